@@ -0,0 +1,305 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/user/git-inquisitor-go/internal/collector"
+	"github.com/user/git-inquisitor-go/internal/timeutil"
+	"github.com/user/git-inquisitor-go/pkg/gitutil"
+)
+
+var (
+	// Used for cache subcommand flags.
+	pruneKeep      int
+	pruneNewerThan string
+
+	cacheCmd = &cobra.Command{
+		Use:   "cache",
+		Short: "Inspects and manages collection caches.",
+		Long: `Lists, prunes and clears the caches written by collect. Caches live below --cache-dir
+(default: $XDG_CACHE_HOME/git-inquisitor), one directory per repository.
+
+Older versions kept caches inside the repository, in .inquisitor/cache. Those are
+included for a repository given as argument, and for every repository that also has
+caches below the cache root.`,
+	}
+
+	cacheListCmd = &cobra.Command{
+		Use:   "list [REPO_PATH|URL]",
+		Short: "Lists cached collections, newest first.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			entries, err := cacheEntries(args)
+			if err != nil {
+				return err
+			}
+			if len(entries) == 0 {
+				fmt.Println("No cached collections found.")
+				return nil
+			}
+			for _, entry := range entries {
+				source := entry.Source
+				if source == "" {
+					source = entry.Repo
+				}
+				fmt.Printf("%s  %s  %10s  %s\n", entry.ModTime.Format("2006-01-02 15:04:05"), entry.Commit[:min(len(entry.Commit), 12)], formatBytes(entry.Size), source)
+			}
+			return nil
+		},
+	}
+
+	cachePruneCmd = &cobra.Command{
+		Use:   "prune [REPO_PATH|URL]",
+		Short: "Removes old cached collections.",
+		Long: `Removes cached collections except the --keep newest per repository and those
+newer than --newer-than (e.g. 30d, 2w, 6mo, or a Go duration such as 30m for
+minutes).`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			var maxAge time.Duration
+			if pruneNewerThan != "" {
+				// Go durations come first, so that 30m keeps meaning minutes for caches.
+				var err error
+				if maxAge, err = time.ParseDuration(pruneNewerThan); err != nil {
+					if maxAge, err = timeutil.ParseDuration(pruneNewerThan); err != nil {
+						return err
+					}
+				}
+			}
+			entries, err := cacheEntries(args)
+			if err != nil {
+				return err
+			}
+			removed, err := collector.PruneCache(entries, pruneKeep, maxAge, time.Now())
+			var freed int64
+			for _, entry := range removed {
+				freed += entry.Size
+			}
+			fmt.Printf("Removed %d of %d cached collections (%s freed).\n", len(removed), len(entries), formatBytes(freed))
+			return err
+		},
+	}
+
+	cacheClearCmd = &cobra.Command{
+		Use:   "clear [REPO_PATH|URL]",
+		Short: "Removes cached collections and mirrors.",
+		Long: `Removes the caches of the given repository, or every cached collection and remote
+mirror when no repository is given, including the caches older versions left inside
+the repositories.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			root := cacheRoot()
+			var dirs []string
+			if len(args) == 1 {
+				repoDir, err := repoCacheDir(args[0])
+				if err != nil {
+					return err
+				}
+				dirs = []string{repoDir}
+			} else {
+				dirs = []string{filepath.Join(root, "repos"), collector.MirrorsDir(root)}
+			}
+			var entries []collector.CacheEntry
+			if len(args) == 0 {
+				var err error
+				if entries, err = collector.ListCache(root, ""); err != nil {
+					return err
+				}
+			}
+			repos, err := legacyCacheRepos(args, entries)
+			if err != nil {
+				return err
+			}
+			for _, repoPath := range repos {
+				if _, err := os.Stat(collector.LegacyCacheDir(repoPath)); err == nil {
+					dirs = append(dirs, collector.LegacyCacheDir(repoPath))
+				}
+			}
+			for _, dir := range dirs {
+				if err := os.RemoveAll(dir); err != nil {
+					return fmt.Errorf("failed to remove %s: %w", dir, err)
+				}
+				fmt.Printf("Removed %s\n", dir)
+			}
+			for _, repoPath := range repos {
+				_ = os.Remove(filepath.Dir(collector.LegacyCacheDir(repoPath))) // .inquisitor, if now empty
+			}
+			return nil
+		},
+	}
+
+	cacheInfoCmd = &cobra.Command{
+		Use:   "info [REPO_PATH|URL]",
+		Short: "Shows where caches are stored and how much space they use.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			root := cacheRoot()
+			fmt.Printf("Cache directory: %s\n", root)
+
+			entries, err := cacheEntries(args)
+			if err != nil {
+				return err
+			}
+			repos := make(map[string]bool)
+			var total int64
+			for _, entry := range entries {
+				repos[entry.Repo] = true
+				total += entry.Size
+			}
+			if len(args) == 1 {
+				repoDir, _ := repoCacheDir(args[0])
+				fmt.Printf("Repository cache: %s\n", repoDir)
+			} else {
+				fmt.Printf("Repositories: %d\n", len(repos))
+				mirrorsSize, err := collector.DirSize(collector.MirrorsDir(root))
+				if err != nil {
+					return fmt.Errorf("failed to measure mirrors directory: %w", err)
+				}
+				fmt.Printf("Mirrors: %s\n", formatBytes(mirrorsSize))
+			}
+			fmt.Printf("Cached collections: %d (%s)\n", len(entries), formatBytes(total))
+			if len(entries) > 0 {
+				fmt.Printf("Newest: %s\n", entries[0].ModTime.Format("2006-01-02 15:04:05"))
+				fmt.Printf("Oldest: %s\n", entries[len(entries)-1].ModTime.Format("2006-01-02 15:04:05"))
+			}
+			return nil
+		},
+	}
+)
+
+// cacheRoot returns the cache root selected by --cache-dir or the default location.
+func cacheRoot() string {
+	if cacheDir != "" {
+		return cacheDir
+	}
+	return collector.DefaultCacheDir()
+}
+
+// repoCacheDir returns the cache directory of a repository path or URL without opening it.
+func repoCacheDir(target string) (string, error) {
+	if gitutil.IsRemoteURL(target) {
+		return collector.RepoCacheDir(cacheRoot(), target), nil
+	}
+	absRepoPath, err := filepath.Abs(target)
+	if err != nil {
+		return "", fmt.Errorf("error getting absolute path for '%s': %w", target, err)
+	}
+	return collector.RepoCacheDir(cacheRoot(), absRepoPath), nil
+}
+
+// cacheEntries lists the cache entries of the repository in args, or of every repository,
+// including those older versions left inside the repositories (see legacyCacheRepos).
+func cacheEntries(args []string) ([]collector.CacheEntry, error) {
+	repoDir := ""
+	if len(args) == 1 {
+		var err error
+		if repoDir, err = repoCacheDir(args[0]); err != nil {
+			return nil, err
+		}
+	}
+	entries, err := collector.ListCache(cacheRoot(), repoDir)
+	if err != nil {
+		return nil, err
+	}
+	repos, err := legacyCacheRepos(args, entries)
+	if err != nil {
+		return nil, err
+	}
+	for _, repoPath := range repos {
+		legacyEntries, err := collector.ListLegacyCache(repoPath)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, legacyEntries...)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].ModTime.After(entries[j].ModTime)
+	})
+	return entries, nil
+}
+
+// legacyCacheRepos returns the local repositories that may still hold caches in
+// collector.LegacyCacheDir: the repository in args, or every local repository with
+// entries below the cache root. Repositories only ever cached in the legacy location
+// are unknown to the cache root and must be named explicitly.
+func legacyCacheRepos(args []string, entries []collector.CacheEntry) ([]string, error) {
+	if len(args) == 1 {
+		if gitutil.IsRemoteURL(args[0]) {
+			return nil, nil
+		}
+		absRepoPath, err := filepath.Abs(args[0])
+		if err != nil {
+			return nil, fmt.Errorf("error getting absolute path for '%s': %w", args[0], err)
+		}
+		return []string{absRepoPath}, nil
+	}
+	var repos []string
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.Source == "" || gitutil.IsRemoteURL(entry.Source) || seen[entry.Source] {
+			continue
+		}
+		seen[entry.Source] = true
+		repos = append(repos, entry.Source)
+	}
+	return repos, nil
+}
+
+// formatBytes renders a byte count with a binary unit suffix.
+func formatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// parseBytes parses a byte count with an optional decimal (KB, MB, GB, TB) or binary
+// (KiB, MiB, GiB, TiB) unit suffix, such as "512MB" or "2GiB".
+func parseBytes(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	units := []struct {
+		suffix string
+		size   float64
+	}{
+		{"KiB", 1 << 10}, {"MiB", 1 << 20}, {"GiB", 1 << 30}, {"TiB", 1 << 40},
+		{"KB", 1e3}, {"MB", 1e6}, {"GB", 1e9}, {"TB", 1e12},
+		{"B", 1},
+	}
+	multiplier := 1.0
+	number := value
+	for _, unit := range units {
+		if strings.HasSuffix(strings.ToUpper(value), strings.ToUpper(unit.suffix)) {
+			multiplier = unit.size
+			number = strings.TrimSpace(value[:len(value)-len(unit.suffix)])
+			break
+		}
+	}
+	count, err := strconv.ParseFloat(number, 64)
+	if err != nil || count <= 0 {
+		return 0, fmt.Errorf("invalid size %q: want a positive number with an optional unit such as MB or GiB", value)
+	}
+	return int64(count * multiplier), nil
+}
+
+func init() {
+	cachePruneCmd.Flags().IntVar(&pruneKeep, "keep", 0, "Number of newest cached collections to keep per repository")
+	cachePruneCmd.Flags().StringVar(&pruneNewerThan, "newer-than", "", "Keep cached collections newer than this age (e.g. 30d, 2w, 6mo; 30m is minutes)")
+
+	cacheCmd.AddCommand(cacheListCmd)
+	cacheCmd.AddCommand(cachePruneCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+	cacheCmd.AddCommand(cacheInfoCmd)
+}
@@ -1,19 +1,85 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"runtime"
+	"runtime/debug"
 	"strings"
+	"syscall"
+	"text/template"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/user/git-inquisitor-go/internal/analysis"
+	"github.com/user/git-inquisitor-go/internal/changelog"
 	"github.com/user/git-inquisitor-go/internal/collector"
+	"github.com/user/git-inquisitor-go/internal/models"
+	"github.com/user/git-inquisitor-go/internal/progress"
 	"github.com/user/git-inquisitor-go/internal/report"
+	"github.com/user/git-inquisitor-go/internal/timeutil"
+	"github.com/user/git-inquisitor-go/internal/workspace"
+	"github.com/user/git-inquisitor-go/pkg/gitutil"
 )
 
 var (
 	// Used for flags.
-	outputFilePath string
+	outputFilePath    string
+	trendSampling     string
+	workspaceGlob     string
+	workspaceManifest string
+	workspaceParallel int
+	cacheDir          string
+	cloneDepth        int
+	singleBranch      bool
+	tempClone         bool
+	clearCache        bool
+	noCache           bool
+	refreshCache      bool
+	quiet             bool
+	verbose           bool
+	logFormat         string
+	strict            bool
+	timeout           time.Duration
+	blameTimeout      time.Duration
+	jobs              int
+	fastStats         bool
+	backend           string
+	maxMemory         string
+	maxMemoryBytes    int64
+	ignoreRevs        []string
+	ignoreRevsFile    string
+	excludeIgnored    bool
+	blameWhitespace   bool
+	blameMoves        bool
+	blameCopies       bool
+	releaseTags       string
+	inactiveAfter     string
+	inactiveAfterDur  time.Duration
+	staleAfter        string
+	staleAfterDur     time.Duration
+	divergedBehind    int
+	messagePolicy     models.MessagePolicy
+	issuePatternSpecs []string
+	issuePatterns     []analysis.IssuePattern
+	changelogFrom     string
+	changelogTo       string
+	changelogGroupBy  string
+	changelogFormat   string
+	changelogTemplate string
+
+	// reporter receives progress and messages, and logger receives collector diagnostics;
+	// both are set up from the logging flags before any command runs.
+	reporter progress.Reporter
+	logger   *slog.Logger
+	// cancelTimeout releases the --timeout context, if any.
+	cancelTimeout context.CancelFunc = func() {}
 
 	rootCmd = &cobra.Command{
 		Use:   "git-inquisitor",
@@ -21,140 +87,567 @@ var (
 		Long: `A tool designed to provide teams with useful information about a 
 git repository and its contributors. It provides history details, 
 file level contribution statistics, and contributor level statistics.`,
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			if timeout > 0 {
+				ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+				cmd.SetContext(ctx)
+				cancelTimeout = cancel
+			}
+			if quiet && verbose {
+				return fmt.Errorf("--quiet and --verbose cannot be combined")
+			}
+			if err := gitutil.ValidateBackend(backend); err != nil {
+				return err
+			}
+			if maxMemory != "" {
+				var err error
+				if maxMemoryBytes, err = parseBytes(maxMemory); err != nil {
+					return fmt.Errorf("invalid --max-memory: %w", err)
+				}
+				// Make the garbage collector work harder rather than exceed the cap.
+				debug.SetMemoryLimit(maxMemoryBytes)
+			}
+			if inactiveAfter != "" {
+				var err error
+				if inactiveAfterDur, err = timeutil.ParseDuration(inactiveAfter); err != nil {
+					return fmt.Errorf("invalid --inactive-after: %w", err)
+				}
+			}
+			if staleAfter != "" {
+				var err error
+				if staleAfterDur, err = timeutil.ParseDuration(staleAfter); err != nil {
+					return fmt.Errorf("invalid --stale-after: %w", err)
+				}
+			}
+			for _, spec := range issuePatternSpecs {
+				pattern, err := analysis.ParseIssuePattern(spec)
+				if err != nil {
+					return fmt.Errorf("invalid --issue-pattern: %w", err)
+				}
+				issuePatterns = append(issuePatterns, pattern)
+			}
+			level := progress.Normal
+			if quiet {
+				level = progress.Quiet
+			} else if verbose {
+				level = progress.Verbose
+			}
+			var err error
+			reporter, err = progress.New(os.Stderr, logFormat, level)
+			if err != nil {
+				return err
+			}
+			if logFormat == progress.FormatJSON {
+				logLevel := slog.LevelInfo
+				if quiet {
+					logLevel = slog.LevelWarn
+				} else if verbose {
+					logLevel = slog.LevelDebug
+				}
+				logger = slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: logLevel}))
+			} else {
+				logger = slog.New(progress.NewHandler(reporter))
+			}
+			return nil
+		},
 	}
 
 	collectCmd = &cobra.Command{
-		Use:   "collect [REPO_PATH]",
+		Use:   "collect [REPO_PATH|URL]",
 		Short: "Collects data from a git repository and caches it.",
-		Long:  `Scans a git repository located at REPO_PATH, collects various metrics and statistics, and caches the results for later reporting.`,
-		Args:  cobra.ExactArgs(1), // Requires exactly one argument: repo-path
-		RunE: func(_ *cobra.Command, args []string) error {
-			repoPath := args[0]
-			absRepoPath, err := filepath.Abs(repoPath)
-			if err != nil {
-				return fmt.Errorf("error getting absolute path for '%s': %w", repoPath, err)
+		Long: `Scans a git repository located at REPO_PATH, collects various metrics and statistics, and caches the results for later reporting.
+A remote URL (including file://) may be given instead of a path; it is cloned into a bare mirror below the cache directory.`,
+		Args: cobra.ExactArgs(1), // Requires exactly one argument: repo-path
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := collector.ValidateTrendSampling(trendSampling); err != nil {
+				return err
 			}
 
-			// Check if repoPath is a directory and looks like a git repo
-			stat, err := os.Stat(absRepoPath)
+			col, err := openCollector(cmd.Context(), args[0])
 			if err != nil {
-				if os.IsNotExist(err) {
-					return fmt.Errorf("repository path '%s' does not exist", absRepoPath)
-				}
-				return fmt.Errorf("error accessing repository path '%s': %w", absRepoPath, err)
-			}
-			if !stat.IsDir() {
-				return fmt.Errorf("repository path '%s' is not a directory", absRepoPath)
-			}
-			// Basic check for .git directory
-			if _, err := os.Stat(filepath.Join(absRepoPath, ".git")); os.IsNotExist(err) {
-				// Could also be a bare repo, where absRepoPath itself is .git, or has HEAD file
-				if _, errHead := os.Stat(filepath.Join(absRepoPath, "HEAD")); os.IsNotExist(errHead) {
-					return fmt.Errorf("'%s' does not appear to be a git repository (missing .git directory or HEAD file)", absRepoPath)
-				}
+				return err
 			}
+			defer col.Close()
 
-			fmt.Printf("Collecting data for repository: %s\n", absRepoPath)
-			col, err := collector.NewGitDataCollector(absRepoPath)
-			if err != nil {
-				return fmt.Errorf("failed to initialize collector for %s: %w", absRepoPath, err)
+			if clearCache {
+				reporter.Infof("Clearing cache...")
+				if err := col.ClearCache(); err != nil {
+					return err
+				}
 			}
 
-			if err := col.Collect(); err != nil {
-				return fmt.Errorf("error during data collection for %s: %w", absRepoPath, err)
+			reporter.Infof("Collecting data for repository: %s", args[0])
+			if err := col.Collect(cmd.Context()); err != nil {
+				return fmt.Errorf("error during data collection for %s: %w", args[0], err)
 			}
-			fmt.Println("Data collection successful.")
-			// Consider option to clear cache
-			// clearCache, _ := cmd.Flags().GetBool("clear-cache")
-			// if clearCache {
-			// 	fmt.Println("Clearing cache...")
-			//  if err := col.ClearCache(); err != nil {
-			//      fmt.Fprintf(os.Stderr, "Error clearing cache: %v\n", err)
-			//  }
-			// }
-			return nil
+			reporter.Infof("Data collection successful.")
+			return checkStrict(&col.Data.Diagnostics)
 		},
 	}
 
 	reportCmd = &cobra.Command{
-		Use:   "report [REPO_PATH] [html|json]",
+		Use:   "report [REPO_PATH|URL] [html|json|markdown]",
 		Short: "Generates a report from collected data.",
-		Long: `Generates a report in the specified format (html or json) using previously 
-collected data for the git repository at REPO_PATH.`,
+		Long: `Generates a report in the specified format (html, json or markdown) using previously 
+collected data for the git repository at REPO_PATH (or remote URL).
+Unless collection flags such as --trend or --fast-stats are given, the cache of the current
+commit is used with whatever options collect wrote it; otherwise it must match them.`,
 		Args: cobra.ExactArgs(2), // Requires repo-path and report-format
-		RunE: func(_ *cobra.Command, args []string) error {
-			repoPath := args[0]
+		RunE: func(cmd *cobra.Command, args []string) error {
+			target := args[0]
 			reportFormat := args[1]
 
-			absRepoPath, err := filepath.Abs(repoPath)
-			if err != nil {
-				return fmt.Errorf("error getting absolute path for '%s': %w", repoPath, err)
-			}
-
 			// Validate report format
-			if reportFormat != "html" && reportFormat != "json" {
-				return fmt.Errorf("invalid report format '%s'. Must be 'html' or 'json'", reportFormat)
+			if err := validateReportFormat(reportFormat); err != nil {
+				return err
+			}
+			if err := collector.ValidateTrendSampling(trendSampling); err != nil {
+				return err
 			}
 
 			// Determine output file path
 			if outputFilePath == "" {
-				outputFilePath = fmt.Sprintf("inquisitor-report.%s", reportFormat)
+				outputFilePath = fmt.Sprintf("inquisitor-report.%s", reportExtension(reportFormat))
 			}
 			absOutputFilePath, err := filepath.Abs(outputFilePath)
 			if err != nil {
 				return fmt.Errorf("invalid output file path '%s': %w", outputFilePath, err)
 			}
 
-			fmt.Printf("Generating %s report for repository: %s\n", reportFormat, absRepoPath)
-			col, err := collector.NewGitDataCollector(absRepoPath)
+			col, err := openCollector(cmd.Context(), target)
 			if err != nil {
-				return fmt.Errorf("failed to initialize collector for %s: %w", absRepoPath, err)
+				return err
 			}
+			defer col.Close()
+			col.Options.ReuseCachedOptions = !collectionFlagsChanged(cmd)
 
+			reporter.Infof("Generating %s report for repository: %s", reportFormat, target)
 			// Load data - Collect() will try cache first, then collect if needed.
 			// This matches Python version's behavior where report implies collection if no cache.
-			if err := col.Collect(); err != nil {
+			if err := col.Collect(cmd.Context()); err != nil {
 				// If collection fails (e.g. repo disappeared after initial collect command), report should fail.
-				return fmt.Errorf("failed to load or collect data for %s: %w", absRepoPath, err)
+				return fmt.Errorf("failed to load or collect data for %s: %w", target, err)
+			}
+			// The report needs the full history, even if it was spilled to disk during collection.
+			if err := col.LoadHistory(); err != nil {
+				return err
 			}
 
-			var adapter report.Adapter
-			if reportFormat == "html" {
-				adapter = &report.HTMLReportAdapter{}
-			} else { // reportFormat == "json"
-				adapter = &report.JSONReportAdapter{}
+			if err := writeReport(&col.Data, reportFormat, absOutputFilePath); err != nil {
+				return err
 			}
+			return checkStrict(&col.Data.Diagnostics)
+		},
+	}
 
-			fmt.Println("Preparing report data...")
-			if err := adapter.PrepareData(&col.Data); err != nil {
-				return fmt.Errorf("failed to prepare %s report data: %w", reportFormat, err)
+	changelogCmd = &cobra.Command{
+		Use:   "changelog [REPO_PATH|URL]",
+		Short: "Renders a changelog between two revisions.",
+		Long: `Renders the changes between --from and --to (default: HEAD) of the git repository at
+REPO_PATH (or remote URL), grouped by Conventional Commit type or by top-level directory, with
+contributor credits and stats. Without --from the changelog starts at the previous release.
+The changelog is written as Markdown or JSON, or rendered with a custom Go template, to
+standard output unless --output-file-path is given. The cached collection of the current
+commit is used if there is one; otherwise only the history is collected, without blame,
+and not cached.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			target := args[0]
+			if err := changelog.ValidateGroupBy(changelogGroupBy); err != nil {
+				return err
+			}
+			if changelogFormat != "markdown" && changelogFormat != "json" {
+				return fmt.Errorf("invalid changelog format '%s'. Must be 'markdown' or 'json'", changelogFormat)
+			}
+			if changelogTemplate != "" && changelogFormat != "markdown" {
+				return fmt.Errorf("--template cannot be combined with --format %s", changelogFormat)
+			}
+			if err := collector.ValidateTrendSampling(trendSampling); err != nil {
+				return err
+			}
+			var tmpl *template.Template
+			if changelogTemplate != "" {
+				text, err := os.ReadFile(changelogTemplate)
+				if err != nil {
+					return fmt.Errorf("failed to read changelog template: %w", err)
+				}
+				if tmpl, err = changelog.ParseTemplate(filepath.Base(changelogTemplate), string(text)); err != nil {
+					return err
+				}
 			}
 
-			fmt.Printf("Writing report to: %s\n", absOutputFilePath)
-			if err := adapter.Write(absOutputFilePath); err != nil {
-				return fmt.Errorf("failed to write %s report to %s: %w", reportFormat, absOutputFilePath, err)
+			col, err := openCollector(cmd.Context(), target)
+			if err != nil {
+				return err
+			}
+			defer col.Close()
+			col.Options.ReuseCachedOptions = !collectionFlagsChanged(cmd)
+			if err := col.CollectHistory(cmd.Context()); err != nil {
+				return fmt.Errorf("failed to load or collect the history of %s: %w", target, err)
+			}
+			if err := col.LoadHistory(); err != nil {
+				return err
 			}
 
-			fmt.Printf("%s report generated successfully: %s\n", strings.ToUpper(reportFormat), absOutputFilePath)
+			opts := changelog.Options{From: changelogFrom, To: changelogTo, GroupBy: changelogGroupBy, IssuePatterns: issuePatterns}
+			if len(opts.IssuePatterns) == 0 {
+				opts.IssuePatterns = analysis.DefaultIssuePatterns(col.Data.Metadata.Repo.URL)
+			}
+			if opts.ToCommit, err = col.ResolveRevision(opts.To); err != nil {
+				return err
+			}
+			if opts.From == "" {
+				opts.From, opts.FromCommit = changelog.PreviousRelease(&col.Data, opts.ToCommit)
+			} else if opts.FromCommit, err = col.ResolveRevision(opts.From); err != nil {
+				return err
+			}
+			result, err := changelog.Build(&col.Data, opts)
+			if err != nil {
+				return err
+			}
+
+			var out bytes.Buffer
+			switch {
+			case tmpl != nil:
+				err = result.Render(&out, tmpl)
+			case changelogFormat == "json":
+				err = result.JSON(&out)
+			default:
+				err = result.Markdown(&out)
+			}
+			if err != nil {
+				return err
+			}
+			if outputFilePath == "" {
+				_, err = os.Stdout.Write(out.Bytes())
+				return err
+			}
+			if err := os.WriteFile(outputFilePath, out.Bytes(), 0600); err != nil {
+				return fmt.Errorf("failed to write changelog to %s: %w", outputFilePath, err)
+			}
+			reporter.Infof("Changelog written to: %s", outputFilePath)
 			return nil
 		},
 	}
+
+	workspaceCmd = &cobra.Command{
+		Use:   "workspace [REPO_PATH...] [html|json|markdown]",
+		Short: "Collects several repositories and generates a combined report.",
+		Long: `Collects every repository given as REPO_PATH arguments, matched by --glob or listed
+in a --manifest file (one path per line), in parallel and reusing each repository's cache.
+Produces a single combined report in the specified format (html, json or markdown) in which
+contributors are unified across repositories and per-repository breakdowns are shown.`,
+		Args: cobra.MinimumNArgs(1), // Requires the report format; repo paths may come from flags
+		RunE: func(cmd *cobra.Command, args []string) error {
+			reportFormat := args[len(args)-1]
+			if err := validateReportFormat(reportFormat); err != nil {
+				return err
+			}
+			if err := collector.ValidateTrendSampling(trendSampling); err != nil {
+				return err
+			}
+
+			repoPaths, err := workspace.ResolveRepos(args[:len(args)-1], workspaceGlob, workspaceManifest)
+			if err != nil {
+				return err
+			}
+			for _, repoPath := range repoPaths {
+				if err := validateRepoPath(repoPath); err != nil {
+					return err
+				}
+			}
+
+			if outputFilePath == "" {
+				outputFilePath = fmt.Sprintf("inquisitor-workspace-report.%s", reportExtension(reportFormat))
+			}
+			absOutputFilePath, err := filepath.Abs(outputFilePath)
+			if err != nil {
+				return fmt.Errorf("invalid output file path '%s': %w", outputFilePath, err)
+			}
+
+			reporter.Infof("Collecting data for %d repositories", len(repoPaths))
+			results := workspace.Collect(cmd.Context(), repoPaths, workspaceParallel, collector.Options{
+				TrendSampling:         trendSampling,
+				CacheDir:              cacheDir,
+				NoCache:               noCache,
+				Refresh:               refreshCache,
+				BlameTimeout:          blameTimeout,
+				Jobs:                  jobs,
+				FastStats:             fastStats,
+				Backend:               backend,
+				MaxMemory:             maxMemoryBytes,
+				IgnoreRevs:            ignoreRevs,
+				SkipUnknownIgnoreRevs: true, // Commits usually belong to a single repository
+				IgnoreRevsFile:        ignoreRevsFile,
+				ExcludeIgnoredRevs:    excludeIgnored,
+				BlameIgnoreWhitespace: blameWhitespace,
+				BlameDetectMoves:      blameMoves,
+				BlameDetectCopies:     blameCopies,
+				ReleaseTags:           releaseTags,
+			}, reporter, logger)
+			for _, result := range results {
+				if result.Err != nil {
+					return result.Err
+				}
+			}
+
+			merged := workspace.Merge(results)
+			if err := writeReport(merged, reportFormat, absOutputFilePath); err != nil {
+				return err
+			}
+			return checkStrict(&merged.Diagnostics)
+		},
+	}
 )
 
+// validateReportFormat checks that reportFormat is html, json or markdown.
+func validateReportFormat(reportFormat string) error {
+	switch reportFormat {
+	case "html", "json", "markdown":
+		return nil
+	}
+	return fmt.Errorf("invalid report format '%s'. Must be 'html', 'json' or 'markdown'", reportFormat)
+}
+
+// reportExtension returns the file extension of reports in reportFormat.
+func reportExtension(reportFormat string) string {
+	if reportFormat == "markdown" {
+		return "md"
+	}
+	return reportFormat
+}
+
+// writeReport renders data in reportFormat (html, json or markdown) to absOutputFilePath.
+func writeReport(data *models.CollectedData, reportFormat, absOutputFilePath string) error {
+	var adapter report.Adapter
+	switch reportFormat {
+	case "html":
+		adapter = &report.HTMLReportAdapter{}
+	case "markdown":
+		adapter = &report.MarkdownReportAdapter{}
+	default: // "json"
+		adapter = &report.JSONReportAdapter{}
+	}
+
+	if inactiveAfter != "" {
+		data.OrphanedKnowledge = analysis.OrphanedKnowledge(data, inactiveAfterDur, inactiveAfter, analysis.ReferenceTime(data))
+	}
+	analysis.FlagBranches(data, staleAfterDur, staleAfter, divergedBehind, time.Now())
+	patterns := issuePatterns
+	if len(patterns) == 0 {
+		patterns = analysis.DefaultIssuePatterns(data.Metadata.Repo.URL)
+	}
+	data.Issues = analysis.Issues(data, patterns)
+	data.CommitMessages = analysis.CommitMessages(data, messagePolicy, patterns)
+
+	reporter.Infof("Preparing report data...")
+	if err := adapter.PrepareData(data); err != nil {
+		return fmt.Errorf("failed to prepare %s report data: %w", reportFormat, err)
+	}
+
+	reporter.Infof("Writing report to: %s", absOutputFilePath)
+	if err := adapter.Write(absOutputFilePath); err != nil {
+		return fmt.Errorf("failed to write %s report to %s: %w", reportFormat, absOutputFilePath, err)
+	}
+
+	reporter.Infof("%s report generated successfully: %s", strings.ToUpper(reportFormat), absOutputFilePath)
+	return nil
+}
+
+// checkStrict fails in --strict mode when any commit or file was skipped during collection.
+func checkStrict(diagnostics *models.Diagnostics) error {
+	failures := diagnostics.Failures()
+	if failures > 0 {
+		reporter.Warnf("%d commits and %d files could not be analyzed", len(diagnostics.SkippedCommits), len(diagnostics.SkippedFiles))
+	}
+	if strict && failures > 0 {
+		return fmt.Errorf("strict mode: %d collection failures", failures)
+	}
+	return nil
+}
+
+// openCollector creates a collector for a local repository path or a remote URL,
+// configured from the cache and timeout flags.
+// Remote repositories are cloned (or refreshed) using the clone flags.
+func openCollector(ctx context.Context, target string) (*collector.GitDataCollector, error) {
+	col, err := newCollector(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	col.Options.CacheDir = cacheDir
+	col.Options.NoCache = noCache
+	col.Options.Refresh = refreshCache
+	col.Options.BlameTimeout = blameTimeout
+	col.Options.Jobs = jobs
+	col.Options.FastStats = fastStats
+	col.Options.Backend = backend
+	col.Options.MaxMemory = maxMemoryBytes
+	col.Options.IgnoreRevs = ignoreRevs
+	col.Options.IgnoreRevsFile = ignoreRevsFile
+	col.Options.ExcludeIgnoredRevs = excludeIgnored
+	col.Options.BlameIgnoreWhitespace = blameWhitespace
+	col.Options.BlameDetectMoves = blameMoves
+	col.Options.BlameDetectCopies = blameCopies
+	col.Options.ReleaseTags = releaseTags
+	col.Options.TrendSampling = trendSampling
+	col.Progress = reporter
+	col.Logger = logger
+	return col, nil
+}
+
+// collectionFlags are the flags selecting collector.Options that change the collected data.
+var collectionFlags = []string{
+	"trend", "fast-stats", "backend", "ignore-rev", "ignore-revs-file",
+	"exclude-ignored-revs", "blame-ignore-whitespace", "blame-detect-moves", "blame-detect-copies", "release-tags",
+}
+
+// collectionFlagsChanged reports whether any of collectionFlags was given to cmd. Commands
+// reading the collected data reuse the options of an existing cache when none was.
+func collectionFlagsChanged(cmd *cobra.Command) bool {
+	for _, name := range collectionFlags {
+		if flag := cmd.Flags().Lookup(name); flag != nil && flag.Changed {
+			return true
+		}
+	}
+	return false
+}
+
+// newCollector creates a collector for a local repository path or a remote URL.
+func newCollector(ctx context.Context, target string) (*collector.GitDataCollector, error) {
+	if gitutil.IsRemoteURL(target) {
+		cloneOpts := gitutil.CloneOptions{Depth: cloneDepth, SingleBranch: singleBranch}
+		col, err := collector.NewRemoteGitDataCollector(ctx, target, cacheDir, cloneOpts, tempClone, reporter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize collector for %s: %w", target, err)
+		}
+		return col, nil
+	}
+
+	absRepoPath, err := filepath.Abs(target)
+	if err != nil {
+		return nil, fmt.Errorf("error getting absolute path for '%s': %w", target, err)
+	}
+	if err := validateRepoPath(absRepoPath); err != nil {
+		return nil, err
+	}
+	col, err := collector.NewGitDataCollector(absRepoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize collector for %s: %w", absRepoPath, err)
+	}
+	return col, nil
+}
+
+// validateRepoPath checks that absRepoPath is a directory that looks like a git repository.
+func validateRepoPath(absRepoPath string) error {
+	// Check if repoPath is a directory and looks like a git repo
+	stat, err := os.Stat(absRepoPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("repository path '%s' does not exist", absRepoPath)
+		}
+		return fmt.Errorf("error accessing repository path '%s': %w", absRepoPath, err)
+	}
+	if !stat.IsDir() {
+		return fmt.Errorf("repository path '%s' is not a directory", absRepoPath)
+	}
+	// Basic check for .git directory
+	if _, err := os.Stat(filepath.Join(absRepoPath, ".git")); os.IsNotExist(err) {
+		// Could also be a bare repo, where absRepoPath itself is .git, or has HEAD file
+		if _, errHead := os.Stat(filepath.Join(absRepoPath, "HEAD")); os.IsNotExist(errHead) {
+			return fmt.Errorf("'%s' does not appear to be a git repository (missing .git directory or HEAD file)", absRepoPath)
+		}
+	}
+	return nil
+}
+
 func init() {
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Only print warnings and errors")
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Print every processed item and debug messages")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", progress.FormatText, "Format of progress and log output: 'text' or 'json'")
+	rootCmd.PersistentFlags().DurationVar(&timeout, "timeout", 0, "Abort if the command takes longer than this (e.g. 10m); 0 disables the limit")
+	rootCmd.PersistentFlags().StringVar(&cacheDir, "cache-dir", "", "Directory for collection caches and remote mirrors (default: $XDG_CACHE_HOME/git-inquisitor)")
+	for _, cmd := range []*cobra.Command{collectCmd, reportCmd, changelogCmd} {
+		cmd.Flags().IntVar(&cloneDepth, "depth", 0, "Limit the history fetched for remote URLs to the given number of commits")
+		cmd.Flags().BoolVar(&singleBranch, "single-branch", false, "Only fetch the default branch of remote URLs")
+		cmd.Flags().BoolVar(&tempClone, "temp-clone", false, "Clone remote URLs into a temporary directory instead of a cached mirror")
+		cmd.Flags().StringVar(&trendSampling, "trend", "", "Sample historical ownership and size: 'tag', 'month' or every N commits")
+	}
+
+	for _, cmd := range []*cobra.Command{collectCmd, reportCmd, workspaceCmd, changelogCmd} {
+		cmd.Flags().BoolVar(&noCache, "no-cache", false, "Neither read nor write the collection cache")
+		cmd.Flags().BoolVar(&refreshCache, "refresh", false, "Ignore any existing cache and re-collect, then update the cache")
+		cmd.Flags().BoolVar(&strict, "strict", false, "Exit non-zero if any commit or file could not be analyzed")
+		maxMemoryUsage := "Soft memory cap such as 2GiB or 512MB; history is spilled to disk to stay below it"
+		if cmd != collectCmd {
+			maxMemoryUsage = "Soft memory cap such as 2GiB or 512MB while collecting; the report itself still loads the whole history"
+		}
+		cmd.Flags().StringVar(&maxMemory, "max-memory", "", maxMemoryUsage)
+		cmd.Flags().StringVar(&backend, "backend", gitutil.BackendGoGit, "How commits are diffed and files blamed: 'go-git' (pure Go) or 'cli' (the git command, faster on large repositories)")
+		cmd.Flags().BoolVar(&fastStats, "fast-stats", false, "Compute commit stats from tree and line-hash diffs instead of full patches (for very long histories)")
+		cmd.Flags().IntVarP(&jobs, "jobs", "j", runtime.NumCPU(), "Number of workers computing commit diffs and blames")
+		cmd.Flags().BoolVar(&blameWhitespace, "blame-ignore-whitespace", false, "Keep lines that were only reindented with their previous author in blame (like git blame -w)")
+		cmd.Flags().BoolVar(&blameMoves, "blame-detect-moves", false, "Keep lines moved within a file with their previous author in blame (like git blame -M)")
+		cmd.Flags().BoolVar(&blameCopies, "blame-detect-copies", false, "Also keep lines moved or copied from other files changed in the same commit with their previous author (like git blame -C)")
+		cmd.Flags().StringVar(&releaseTags, "release-tags", "", "Glob pattern selecting the tags that mark releases, e.g. 'v*' (default: every tag)")
+		cmd.Flags().StringSliceVar(&ignoreRevs, "ignore-rev", nil, "Attribute the lines changed by this commit to their previous author in blame (repeatable)")
+		cmd.Flags().StringVar(&ignoreRevsFile, "ignore-revs-file", "", "Also ignore in blame the commits listed in this file of the analyzed commit, e.g. "+gitutil.DefaultIgnoreRevsFile+" (the blame.ignoreRevsFile setting is always read)")
+		cmd.Flags().BoolVar(&excludeIgnored, "exclude-ignored-revs", false, "Also leave the commits ignored by blame out of the contributors' insertion and deletion totals")
+		cmd.Flags().DurationVar(&blameTimeout, "blame-timeout", 0, "Skip (and record) files whose blame takes longer than this (e.g. 30s); 0 disables the limit")
+	}
+
 	// Add flags to reportCmd
 	reportCmd.Flags().StringVarP(&outputFilePath, "output-file-path", "o", "", "Output file path for the report")
-	// Example for adding a flag to collectCmd if needed later:
-	// collectCmd.Flags().Bool("clear-cache", false, "Clears existing cache before collecting")
+	collectCmd.Flags().BoolVar(&clearCache, "clear-cache", false, "Clears existing cache before collecting")
+
+	workspaceCmd.Flags().StringVarP(&outputFilePath, "output-file-path", "o", "", "Output file path for the combined report")
+	for _, cmd := range []*cobra.Command{reportCmd, workspaceCmd} {
+		cmd.Flags().StringVar(&inactiveAfter, "inactive-after", "6mo", "Treat contributors without commits for this long before the HEAD commit (e.g. 90d, 6mo, 1y) as inactive in the orphaned knowledge analysis; empty disables it")
+		cmd.Flags().StringVar(&staleAfter, "stale-after", "90d", "Flag branches without commits for this long (e.g. 30d, 3mo) as stale; empty disables it")
+		cmd.Flags().IntVar(&divergedBehind, "diverged-behind", 100, "Flag unmerged branches at least this many commits behind the default branch as diverged; 0 disables it")
+		cmd.Flags().IntVar(&messagePolicy.MaxSubjectLength, "max-subject-length", 72, "Report commits whose subject is longer than this many characters; 0 disables the check")
+		cmd.Flags().BoolVar(&messagePolicy.RequireConventional, "require-conventional", false, "Report commits whose subject is not in Conventional Commits form (type(scope): description)")
+		cmd.Flags().StringSliceVar(&messagePolicy.AllowedTypes, "commit-types", analysis.DefaultCommitTypes, "Conventional Commit types allowed by the message policy; empty allows any")
+		cmd.Flags().BoolVar(&messagePolicy.RequireIssueRef, "require-issue-ref", false, "Report commits whose message references no issue (#123 or JIRA-456)")
+		cmd.Flags().BoolVar(&messagePolicy.RequireImperative, "require-imperative", false, "Report commits whose subject does not start with a verb in the imperative mood")
+		cmd.Flags().BoolVar(&messagePolicy.RequireBody, "require-body", false, "Report commits whose message has no body")
+		cmd.Flags().StringArrayVar(&issuePatternSpecs, "issue-pattern", nil, "Issue reference pattern as REGEX or REGEX=URL_TEMPLATE (an http or https URL), with ${0} the reference and ${1}... its groups (repeatable; replaces the default #123 and JIRA-456 patterns)")
+	}
+	changelogCmd.Flags().StringVar(&changelogFrom, "from", "", "Revision the changelog starts after (default: the previous release, or the first commit)")
+	changelogCmd.Flags().StringVar(&changelogTo, "to", "HEAD", "Revision the changelog ends at")
+	changelogCmd.Flags().StringVar(&changelogGroupBy, "group-by", changelog.GroupByType, "Group changes by Conventional Commit 'type' or top-level 'directory'")
+	changelogCmd.Flags().StringVar(&changelogFormat, "format", "markdown", "Output format: 'markdown' or 'json'")
+	changelogCmd.Flags().StringVar(&changelogTemplate, "template", "", "Go text/template file rendering the changelog instead of the default Markdown")
+	changelogCmd.Flags().StringVarP(&outputFilePath, "output-file-path", "o", "", "Output file path for the changelog (default: standard output)")
+	changelogCmd.Flags().StringArrayVar(&issuePatternSpecs, "issue-pattern", nil, "Issue reference pattern as REGEX or REGEX=URL_TEMPLATE (an http or https URL; repeatable; replaces the default #123 and JIRA-456 patterns)")
+
+	workspaceCmd.Flags().StringVar(&workspaceGlob, "glob", "", "Glob pattern matching repository directories")
+	workspaceCmd.Flags().StringVar(&workspaceManifest, "manifest", "", "File listing repository paths, one per line")
+	workspaceCmd.Flags().IntVar(&workspaceParallel, "parallel", runtime.NumCPU(), "Number of repositories collected in parallel")
+	workspaceCmd.Flags().StringVar(&trendSampling, "trend", "", "Sample historical ownership and size: 'tag', 'month' or every N commits")
 
 	// Add subcommands to rootCmd
 	rootCmd.AddCommand(collectCmd)
 	rootCmd.AddCommand(reportCmd)
+	rootCmd.AddCommand(workspaceCmd)
+	rootCmd.AddCommand(changelogCmd)
+	rootCmd.AddCommand(cacheCmd)
 }
 
 func main() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	// Ctrl-C and SIGTERM cancel the context, which stops collection and blame workers.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err := rootCmd.ExecuteContext(ctx)
+	cancelTimeout()
+	stop()
+	if err != nil {
+		switch {
+		case errors.Is(err, context.DeadlineExceeded) && timeout > 0:
+			fmt.Fprintf(os.Stderr, "Error: timed out after %s: %v\n", timeout, err)
+		case errors.Is(err, context.Canceled):
+			fmt.Fprintf(os.Stderr, "Error: interrupted: %v\n", err)
+		default:
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/user/git-inquisitor-go/internal/collector"
+	"github.com/user/git-inquisitor-go/internal/models"
+)
+
+// createTestRepo initializes a git repository with two commits in a temporary directory.
+func createTestRepo(t *testing.T) string {
+	t.Helper()
+	repoPath := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		if out, err := exec.Command("git", append([]string{"-C", repoPath}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	git("init")
+	git("config", "user.name", "Test User")
+	git("config", "user.email", "test@example.com")
+	for i, content := range []string{"1\n", "1\n2\n"} {
+		if err := os.WriteFile(filepath.Join(repoPath, "a.txt"), []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		git("add", "a.txt")
+		git("commit", "-m", []string{"first", "second"}[i])
+	}
+	return repoPath
+}
+
+// runCommand runs the command line args like main does, as a fresh process would: flags
+// set by earlier runs are reset first.
+func runCommand(t *testing.T, args ...string) {
+	t.Helper()
+	trendSampling, outputFilePath, issuePatterns = "", "", nil
+	rootCmd.SetArgs(args)
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("git-inquisitor %v error = %v", args, err)
+	}
+}
+
+func TestReportReusesTrendCollected(t *testing.T) {
+	repoPath := createTestRepo(t)
+	cacheDir := t.TempDir()
+	reportPath := filepath.Join(t.TempDir(), "report.json")
+
+	runCommand(t, "collect", "--quiet", "--cache-dir", cacheDir, "--trend", "1", repoPath)
+	runCommand(t, "report", "--quiet", "--cache-dir", cacheDir, "-o", reportPath, repoPath, "json")
+
+	content, err := os.ReadFile(reportPath)
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	var data models.CollectedData
+	if err := json.Unmarshal(content, &data); err != nil {
+		t.Fatalf("Failed to decode report: %v", err)
+	}
+	if len(data.Trend) != 2 {
+		t.Errorf("Report trend length = %d, want the 2 points collected with --trend 1", len(data.Trend))
+	}
+
+	// The cache must still hold the trend rather than being re-collected without it.
+	runCommand(t, "report", "--quiet", "--cache-dir", cacheDir, "-o", reportPath, repoPath, "json")
+	if content, err = os.ReadFile(reportPath); err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	data = models.CollectedData{}
+	if err := json.Unmarshal(content, &data); err != nil {
+		t.Fatalf("Failed to decode report: %v", err)
+	}
+	if len(data.Trend) != 2 {
+		t.Errorf("Second report trend length = %d, want 2", len(data.Trend))
+	}
+}
+
+func TestCachePruneNewerThanMinutes(t *testing.T) {
+	repoPath := createTestRepo(t)
+	cacheDir := t.TempDir()
+
+	runCommand(t, "collect", "--quiet", "--cache-dir", cacheDir, repoPath)
+	// A bare m suffix is rejected by the analyses, but keeps meaning minutes for caches.
+	runCommand(t, "cache", "prune", "--cache-dir", cacheDir, "--newer-than", "30m")
+
+	entries, err := collector.ListCache(cacheDir, "")
+	if err != nil {
+		t.Fatalf("ListCache() error = %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("ListCache() = %d entries after pruning, want the collection just made kept", len(entries))
+	}
+}
@@ -0,0 +1,31 @@
+package analysis
+
+import (
+	"time"
+
+	"github.com/user/git-inquisitor-go/internal/models"
+)
+
+// FlagBranches flags the branches of data that are candidates for cleanup. A branch is
+// stale when its last commit is more than staleAfter before asOf, and diverged when it
+// is not merged and at least divergedBehind commits behind the default branch. Default
+// branches are never flagged. A zero staleAfter or divergedBehind disables the flag;
+// label describes staleAfter (such as "90d").
+func FlagBranches(data *models.CollectedData, staleAfter time.Duration, label string, divergedBehind int, asOf time.Time) {
+	if data.Branches == nil {
+		return
+	}
+	landscape := data.Branches
+	landscape.StaleAfter = ""
+	if staleAfter > 0 {
+		landscape.StaleAfter = label
+	}
+	landscape.DivergedBehind = divergedBehind
+	landscape.AsOf = asOf
+	cutoff := asOf.Add(-staleAfter)
+	for i := range landscape.Branches {
+		branch := &landscape.Branches[i]
+		branch.Stale = !branch.Default && staleAfter > 0 && branch.LastCommitDate.Before(cutoff)
+		branch.Diverged = !branch.Default && !branch.Merged && divergedBehind > 0 && branch.Behind >= divergedBehind
+	}
+}
@@ -0,0 +1,46 @@
+package analysis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/user/git-inquisitor-go/internal/models"
+)
+
+func TestFlagBranches(t *testing.T) {
+	asOf := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	old := asOf.AddDate(0, -4, 0)
+	data := &models.CollectedData{Branches: &models.BranchLandscape{
+		Default: "main",
+		Branches: []models.Branch{
+			{Name: "main", Default: true, LastCommitDate: old},
+			{Name: "abandoned", LastCommitDate: old, Ahead: 2, Behind: 150},
+			{Name: "merged", LastCommitDate: old, Merged: true, Behind: 150},
+			{Name: "active", LastCommitDate: asOf.AddDate(0, 0, -3), Ahead: 5, Behind: 20},
+		},
+	}}
+
+	FlagBranches(data, 90*24*time.Hour, "90d", 100, asOf)
+	want := map[string][2]bool{
+		"main":      {false, false},
+		"abandoned": {true, true},
+		"merged":    {true, false},
+		"active":    {false, false},
+	}
+	for _, branch := range data.Branches.Branches {
+		if got := [2]bool{branch.Stale, branch.Diverged}; got != want[branch.Name] {
+			t.Errorf("%s stale, diverged = %v, want %v", branch.Name, got, want[branch.Name])
+		}
+	}
+	if data.Branches.StaleAfter != "90d" || data.Branches.DivergedBehind != 100 || !data.Branches.AsOf.Equal(asOf) {
+		t.Errorf("thresholds = %+v", data.Branches)
+	}
+
+	FlagBranches(data, 0, "", 0, asOf)
+	for _, branch := range data.Branches.Branches {
+		if branch.Stale || branch.Diverged {
+			t.Errorf("%s is flagged with the flags disabled", branch.Name)
+		}
+	}
+	FlagBranches(&models.CollectedData{}, 90*24*time.Hour, "90d", 100, asOf) // No branches: nothing to flag
+}
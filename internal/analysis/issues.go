@@ -0,0 +1,181 @@
+package analysis
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/user/git-inquisitor-go/internal/models"
+)
+
+// Patterns of the issue references recognized by default.
+const (
+	GitHubIssuePattern = `\B#(\d+)\b`
+	JiraIssuePattern   = `\b[A-Z][A-Z0-9]+-\d+\b`
+)
+
+// IssuePattern recognizes references to an issue tracker in commit messages. The whole
+// match of Regexp is the reference, such as "#123" or "JIRA-456".
+type IssuePattern struct {
+	Regexp *regexp.Regexp
+	// URLTemplate links a reference, with "${0}" replaced by the reference and "${1}",
+	// "${2}"... by the groups of Regexp, as in regexp.Regexp.Expand. Empty leaves
+	// references unlinked.
+	URLTemplate string
+}
+
+// urlTemplateStart matches the "=" that starts the URL template of an issue pattern spec.
+var urlTemplateStart = regexp.MustCompile(`=https?://`)
+
+// ParseIssuePattern parses "REGEX" or "REGEX=URL_TEMPLATE", such as
+// `\bPROJ-\d+\b=https://jira.example.com/browse/${0}`. The URL template must be an
+// http or https URL; it starts at the last "=" followed by one, so the regular
+// expression may contain "=" too.
+func ParseIssuePattern(spec string) (IssuePattern, error) {
+	expr, urlTemplate := spec, ""
+	if starts := urlTemplateStart.FindAllStringIndex(spec, -1); len(starts) > 0 {
+		start := starts[len(starts)-1][0]
+		expr, urlTemplate = spec[:start], spec[start+1:]
+	}
+	if expr == "" {
+		return IssuePattern{}, fmt.Errorf("issue pattern %q has no regular expression", spec)
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return IssuePattern{}, fmt.Errorf("invalid issue pattern %q: %w", expr, err)
+	}
+	return IssuePattern{Regexp: re, URLTemplate: urlTemplate}, nil
+}
+
+// DefaultIssuePatterns returns the GitHub (#123) and Jira (JIRA-456) patterns. GitHub
+// references link to the issues of repoURL if it is a GitHub repository; Jira keys are
+// not linked, as the tracker's address is unknown.
+func DefaultIssuePatterns(repoURL string) []IssuePattern {
+	github := IssuePattern{Regexp: regexp.MustCompile(GitHubIssuePattern)}
+	if project := githubProject(repoURL); project != "" {
+		github.URLTemplate = "https://github.com/" + project + "/issues/${1}"
+	}
+	return []IssuePattern{github, {Regexp: regexp.MustCompile(JiraIssuePattern)}}
+}
+
+// githubProject returns the "owner/name" of a GitHub remote URL in HTTPS, SSH or scp-like
+// form, or "" for other URLs.
+func githubProject(repoURL string) string {
+	rest := ""
+	for _, prefix := range []string{"https://github.com/", "http://github.com/", "ssh://git@github.com/", "git@github.com:"} {
+		if strings.HasPrefix(repoURL, prefix) {
+			rest = strings.TrimPrefix(repoURL, prefix)
+			break
+		}
+	}
+	parts := strings.Split(strings.TrimSuffix(strings.TrimSuffix(rest, "/"), ".git"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return ""
+	}
+	return parts[0] + "/" + parts[1]
+}
+
+// findIssues returns the references patterns find in message, in order of appearance
+// and without duplicates, and the URL of each linked one (nil if none is linked).
+func findIssues(message string, patterns []IssuePattern) ([]string, map[string]string) {
+	var refs []string
+	var urls map[string]string
+	seen := make(map[string]bool)
+	type found struct {
+		start int
+		ref   string
+	}
+	var matches []found
+	for _, pattern := range patterns {
+		for _, loc := range pattern.Regexp.FindAllStringSubmatchIndex(message, -1) {
+			ref := message[loc[0]:loc[1]]
+			if ref == "" {
+				continue
+			}
+			matches = append(matches, found{start: loc[0], ref: ref})
+			if pattern.URLTemplate != "" && urls[ref] == "" {
+				if urls == nil {
+					urls = make(map[string]string)
+				}
+				urls[ref] = string(pattern.Regexp.ExpandString(nil, pattern.URLTemplate, message, loc))
+			}
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].start < matches[j].start })
+	for _, match := range matches {
+		if !seen[match.ref] {
+			seen[match.ref] = true
+			refs = append(refs, match.ref)
+		}
+	}
+	return refs, urls
+}
+
+// Issues sets the Issues of the history's commits to the references patterns find in
+// their messages and aggregates the commits, churn, files and contributors of each
+// issue, most commits first. Merge commits are tagged but not aggregated, as their
+// changes are those of the merged commits. References of several repositories in a
+// combined workspace report are aggregated by their text.
+func Issues(data *models.CollectedData, patterns []IssuePattern) []models.IssueSummary {
+	summaries := make(map[string]*models.IssueSummary)
+	files := make(map[string]map[string]bool)
+	contributors := make(map[string]map[string]bool)
+	for i := range data.History {
+		item := &data.History[i]
+		refs, urls := findIssues(item.Message, patterns)
+		item.Issues = refs
+		if len(item.Parents) > 1 {
+			continue
+		}
+		for _, ref := range refs {
+			summary := summaries[ref]
+			if summary == nil {
+				summary = &models.IssueSummary{Key: ref}
+				summaries[ref] = summary
+				files[ref] = make(map[string]bool)
+				contributors[ref] = make(map[string]bool)
+			}
+			if summary.URL == "" {
+				summary.URL = urls[ref]
+			}
+			summary.Commits++
+			summary.Insertions += item.Insertions
+			summary.Deletions += item.Deletions
+			if summary.FirstCommitDate.IsZero() || item.Date.Before(summary.FirstCommitDate) {
+				summary.FirstCommitDate = item.Date
+			}
+			if item.Date.After(summary.LastCommitDate) {
+				summary.LastCommitDate = item.Date
+			}
+			for path := range item.FilesChanged {
+				files[ref][path] = true
+			}
+			contributors[ref][ContributorName(item.Contributor)] = true
+		}
+	}
+
+	result := make([]models.IssueSummary, 0, len(summaries))
+	for ref, summary := range summaries {
+		summary.Files = sortedSet(files[ref])
+		summary.Contributors = sortedSet(contributors[ref])
+		result = append(result, *summary)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Commits != result[j].Commits {
+			return result[i].Commits > result[j].Commits
+		}
+		return result[i].Key < result[j].Key
+	})
+	return result
+}
+
+// sortedSet returns the members of set, sorted.
+func sortedSet(set map[string]bool) []string {
+	members := make([]string, 0, len(set))
+	for member := range set {
+		members = append(members, member)
+	}
+	sort.Strings(members)
+	return members
+}
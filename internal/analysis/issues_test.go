@@ -0,0 +1,95 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/user/git-inquisitor-go/internal/models"
+)
+
+func TestParseIssuePattern(t *testing.T) {
+	pattern, err := ParseIssuePattern(`\bOPS-(\d+)\b=https://tracker.example.com/browse/OPS-${1}?view=full`)
+	if err != nil {
+		t.Fatalf("ParseIssuePattern() error = %v", err)
+	}
+	if pattern.URLTemplate != "https://tracker.example.com/browse/OPS-${1}?view=full" {
+		t.Errorf("URLTemplate = %q, want the URL after the last =", pattern.URLTemplate)
+	}
+	// The regular expression may contain "=" itself.
+	pattern, err = ParseIssuePattern(`\bticket=(\d+)\b=https://tracker.example.com/?id=${1}`)
+	if err != nil {
+		t.Fatalf("ParseIssuePattern() error = %v", err)
+	}
+	if pattern.Regexp.String() != `\bticket=(\d+)\b` || pattern.URLTemplate != "https://tracker.example.com/?id=${1}" {
+		t.Errorf("ParseIssuePattern() = %q, %q, want the split before the URL", pattern.Regexp, pattern.URLTemplate)
+	}
+	if pattern, err = ParseIssuePattern(`(?:x)?a=b`); err != nil || pattern.Regexp.String() != `(?:x)?a=b` || pattern.URLTemplate != "" {
+		t.Errorf("ParseIssuePattern() without URL = %v, %v, want the whole spec as regular expression", pattern, err)
+	}
+	for _, spec := range []string{"", "=https://example.com", "OPS-(\\d+"} {
+		if _, err := ParseIssuePattern(spec); err == nil {
+			t.Errorf("ParseIssuePattern(%q) error = nil, want an error", spec)
+		}
+	}
+}
+
+func TestDefaultIssuePatterns(t *testing.T) {
+	for repoURL, want := range map[string]string{
+		"https://github.com/acme/widgets.git": "https://github.com/acme/widgets/issues/${1}",
+		"git@github.com:acme/widgets.git":     "https://github.com/acme/widgets/issues/${1}",
+		"https://gitlab.com/acme/widgets.git": "",
+		"":                                    "",
+	} {
+		if got := DefaultIssuePatterns(repoURL)[0].URLTemplate; got != want {
+			t.Errorf("DefaultIssuePatterns(%q) GitHub URL = %q, want %q", repoURL, got, want)
+		}
+	}
+}
+
+func TestIssues(t *testing.T) {
+	jira, err := ParseIssuePattern(`\bOPS-\d+\b=https://tracker.example.com/browse/${0}`)
+	if err != nil {
+		t.Fatalf("ParseIssuePattern() error = %v", err)
+	}
+	patterns := append(DefaultIssuePatterns("https://github.com/acme/widgets"), jira)
+	jan := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	feb := time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC)
+	data := &models.CollectedData{
+		History: []models.CommitHistoryItem{
+			{Commit: "a", Contributor: "Alice (alice@example.com)", Date: jan, Message: "Fix login (#12)\n\nSee OPS-7.", Insertions: 5, Deletions: 1,
+				FilesChanged: map[string]models.FileCommitStats{"login.go": {}}},
+			{Commit: "b", Contributor: "Bob (bob@example.com)", Date: feb, Message: "OPS-7: Add retries", Insertions: 10, Deletions: 2,
+				FilesChanged: map[string]models.FileCommitStats{"login.go": {}, "retry.go": {}}},
+			{Commit: "c", Contributor: "Alice (alice@example.com)", Date: feb, Message: "Merge pull request #12 from alice/login", Parents: []string{"a", "b"},
+				FilesChanged: map[string]models.FileCommitStats{"login.go": {}}},
+			{Commit: "d", Contributor: "Alice (alice@example.com)", Date: feb, Message: "Tidy up"},
+		},
+	}
+
+	issues := Issues(data, patterns)
+
+	if want := []string{"#12", "OPS-7"}; !reflect.DeepEqual(data.History[0].Issues, want) {
+		t.Errorf("History[0].Issues = %v, want %v", data.History[0].Issues, want)
+	}
+	if want := []string{"#12"}; !reflect.DeepEqual(data.History[2].Issues, want) {
+		t.Errorf("merge commit Issues = %v, want %v", data.History[2].Issues, want)
+	}
+	if data.History[3].Issues != nil {
+		t.Errorf("History[3].Issues = %v, want none", data.History[3].Issues)
+	}
+
+	want := []models.IssueSummary{
+		{
+			Key: "OPS-7", URL: "https://tracker.example.com/browse/OPS-7", Commits: 2, Insertions: 15, Deletions: 3,
+			Files: []string{"login.go", "retry.go"}, Contributors: []string{"Alice", "Bob"}, FirstCommitDate: jan, LastCommitDate: feb,
+		},
+		{
+			Key: "#12", URL: "https://github.com/acme/widgets/issues/12", Commits: 1, Insertions: 5, Deletions: 1,
+			Files: []string{"login.go"}, Contributors: []string{"Alice"}, FirstCommitDate: jan, LastCommitDate: jan,
+		},
+	}
+	if !reflect.DeepEqual(issues, want) {
+		t.Errorf("Issues() = %+v, want %+v", issues, want)
+	}
+}
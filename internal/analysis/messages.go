@@ -0,0 +1,252 @@
+package analysis
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/user/git-inquisitor-go/internal/models"
+)
+
+// DefaultCommitTypes are the Conventional Commit types allowed by default.
+var DefaultCommitTypes = []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "revert"}
+
+var (
+	// conventionalSubject matches "type(scope)!: description".
+	conventionalSubject = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9-]*)(?:\(([^()]+)\))?(!)?: +(\S.*)$`)
+	// breakingFooter matches the footer announcing a breaking change.
+	breakingFooter = regexp.MustCompile(`(?m)^BREAKING[ -]CHANGE: `)
+	// trailer matches git trailers such as "Signed-off-by: Name <email>".
+	trailer = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9-]*: `)
+)
+
+// ParsedMessage is what ParseCommitMessage finds in a commit message.
+type ParsedMessage struct {
+	Subject string
+	// Type, Scope and Breaking come from a Conventional Commits subject; Type is lower case.
+	Conventional bool
+	Type         string
+	Scope        string
+	Breaking     bool
+	// Description is the subject without its Conventional Commits prefix.
+	Description string
+	IssueRefs   []string
+	// IssueURLs links the references of IssueRefs whose pattern has a URL template.
+	IssueURLs map[string]string
+	// HasBody is set when the message has text beyond its subject and trailers.
+	HasBody    bool
+	Imperative bool
+}
+
+// ParseCommitMessage parses message as a Conventional Commit, collects the issue
+// references patterns find in it and judges whether its subject is in the imperative mood.
+func ParseCommitMessage(message string, patterns []IssuePattern) ParsedMessage {
+	lines := strings.Split(strings.ReplaceAll(message, "\r\n", "\n"), "\n")
+	parsed := ParsedMessage{Subject: strings.TrimSpace(lines[0])}
+	parsed.Description = parsed.Subject
+	if match := conventionalSubject.FindStringSubmatch(parsed.Subject); match != nil {
+		parsed.Conventional = true
+		parsed.Type = strings.ToLower(match[1])
+		parsed.Scope = strings.TrimSpace(match[2])
+		parsed.Breaking = match[3] != ""
+		parsed.Description = match[4]
+	}
+	if parsed.Conventional && breakingFooter.MatchString(message) {
+		parsed.Breaking = true
+	}
+	for _, line := range lines[1:] {
+		line = strings.TrimSpace(line)
+		if line != "" && !trailer.MatchString(line) {
+			parsed.HasBody = true
+			break
+		}
+	}
+
+	parsed.IssueRefs, parsed.IssueURLs = findIssues(message, patterns)
+	parsed.Imperative = isImperative(parsed.Description)
+	return parsed
+}
+
+// nonImperativeExceptions are words ending like past tenses, gerunds or third persons
+// that are imperatives nonetheless.
+var nonImperativeExceptions = map[string]bool{
+	"embed": true, "feed": true, "need": true, "seed": true, "shred": true, "speed": true, "proceed": true, "exceed": true,
+	"bring": true, "string": true, "ring": true, "sing": true,
+	"access": true, "address": true, "bypass": true, "compress": true, "discuss": true, "pass": true, "process": true, "express": true,
+	"focus": true, "bias": true, "alias": true, "canvas": true, "status": true,
+}
+
+// isImperative guesses whether description starts with a verb in the imperative mood,
+// such as "Add" or "fix", rather than "Added", "Adding" or "Adds".
+func isImperative(description string) bool {
+	fields := strings.Fields(description)
+	if len(fields) == 0 {
+		return false
+	}
+	word := strings.ToLower(strings.Trim(fields[0], ".,:;!?\"'`"))
+	if word == "" || !isLetters(word) {
+		return false
+	}
+	if nonImperativeExceptions[word] {
+		return true
+	}
+	switch {
+	case strings.HasSuffix(word, "ed"), strings.HasSuffix(word, "ing"):
+		return false
+	case strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") && !strings.HasSuffix(word, "us") && !strings.HasSuffix(word, "is"):
+		return false
+	}
+	return true
+}
+
+// isLetters reports whether word only consists of ASCII letters.
+func isLetters(word string) bool {
+	for _, r := range word {
+		if r < 'a' || r > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckMessage returns the ways parsed breaks policy, if any.
+func CheckMessage(parsed ParsedMessage, policy models.MessagePolicy) []string {
+	var problems []string
+	if parsed.Subject == "" {
+		problems = append(problems, "empty subject")
+	}
+	if length := utf8.RuneCountInString(parsed.Subject); policy.MaxSubjectLength > 0 && length > policy.MaxSubjectLength {
+		problems = append(problems, fmt.Sprintf("subject is %d characters long (max %d)", length, policy.MaxSubjectLength))
+	}
+	if policy.RequireConventional && !parsed.Conventional {
+		problems = append(problems, "not a Conventional Commit")
+	}
+	if parsed.Conventional && len(policy.AllowedTypes) > 0 && !containsFold(policy.AllowedTypes, parsed.Type) {
+		problems = append(problems, fmt.Sprintf("type %q is not allowed", parsed.Type))
+	}
+	if policy.RequireIssueRef && len(parsed.IssueRefs) == 0 {
+		problems = append(problems, "no issue reference")
+	}
+	if policy.RequireImperative && !parsed.Imperative {
+		problems = append(problems, "subject not in the imperative mood")
+	}
+	if policy.RequireBody && !parsed.HasBody {
+		problems = append(problems, "no body")
+	}
+	return problems
+}
+
+// containsFold reports whether values contains value, ignoring case.
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// CommitMessages parses the messages of the history's commits, merges excepted, and
+// counts their properties overall, by contributor (committer name) and by month of the
+// commit date. Issue references are those found by patterns. Commits breaking policy
+// are listed newest first.
+func CommitMessages(data *models.CollectedData, policy models.MessagePolicy, patterns []IssuePattern) *models.CommitMessages {
+	result := &models.CommitMessages{
+		Policy:        policy,
+		ByContributor: make(map[string]models.MessageStats),
+		ByMonth:       make(map[string]models.MessageStats),
+		Violations:    []models.MessageViolation{},
+	}
+	var overallSubjectLength int
+	byContributorLength := make(map[string]int)
+	byMonthLength := make(map[string]int)
+
+	for _, item := range data.History {
+		if len(item.Parents) > 1 {
+			continue
+		}
+		parsed := ParseCommitMessage(item.Message, patterns)
+		problems := CheckMessage(parsed, policy)
+		length := utf8.RuneCountInString(parsed.Subject)
+
+		name := ContributorName(item.Contributor)
+		month := item.Date.Format("2006-01")
+		contributorStats := result.ByContributor[name]
+		monthStats := result.ByMonth[month]
+		for _, stats := range []*models.MessageStats{&result.Overall, &contributorStats, &monthStats} {
+			addMessage(stats, parsed, len(problems) > 0)
+		}
+		result.ByContributor[name] = contributorStats
+		result.ByMonth[month] = monthStats
+		overallSubjectLength += length
+		byContributorLength[name] += length
+		byMonthLength[month] += length
+
+		if len(problems) > 0 {
+			result.Violations = append(result.Violations, models.MessageViolation{
+				Commit:      item.Commit,
+				Contributor: item.Contributor,
+				Date:        item.Date,
+				Subject:     parsed.Subject,
+				Problems:    problems,
+			})
+		}
+	}
+
+	result.Overall.AverageSubjectLength = average(overallSubjectLength, result.Overall.Commits)
+	for name, stats := range result.ByContributor {
+		stats.AverageSubjectLength = average(byContributorLength[name], stats.Commits)
+		result.ByContributor[name] = stats
+	}
+	for month, stats := range result.ByMonth {
+		stats.AverageSubjectLength = average(byMonthLength[month], stats.Commits)
+		result.ByMonth[month] = stats
+	}
+	sort.SliceStable(result.Violations, func(i, j int) bool {
+		return result.Violations[i].Date.After(result.Violations[j].Date)
+	})
+	return result
+}
+
+// addMessage counts parsed in stats.
+func addMessage(stats *models.MessageStats, parsed ParsedMessage, violation bool) {
+	stats.Commits++
+	if parsed.Conventional {
+		stats.Conventional++
+		if stats.Types == nil {
+			stats.Types = make(map[string]int)
+		}
+		stats.Types[parsed.Type]++
+		if parsed.Scope != "" {
+			if stats.Scopes == nil {
+				stats.Scopes = make(map[string]int)
+			}
+			stats.Scopes[parsed.Scope]++
+		}
+	}
+	if parsed.Breaking {
+		stats.Breaking++
+	}
+	if len(parsed.IssueRefs) > 0 {
+		stats.WithIssueRefs++
+	}
+	if parsed.HasBody {
+		stats.WithBody++
+	}
+	if parsed.Imperative {
+		stats.Imperative++
+	}
+	if violation {
+		stats.Violations++
+	}
+}
+
+// average divides total by count, or returns 0 without a count.
+func average(total, count int) float64 {
+	if count == 0 {
+		return 0
+	}
+	return float64(total) / float64(count)
+}
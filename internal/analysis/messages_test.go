@@ -0,0 +1,87 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/user/git-inquisitor-go/internal/models"
+)
+
+func TestParseCommitMessage(t *testing.T) {
+	testCases := []struct {
+		message string
+		want    ParsedMessage
+	}{
+		{
+			message: "feat(api)!: Add pagination to #12 and JIRA-456",
+			want: ParsedMessage{
+				Subject: "feat(api)!: Add pagination to #12 and JIRA-456", Conventional: true, Type: "feat", Scope: "api", Breaking: true,
+				Description: "Add pagination to #12 and JIRA-456", IssueRefs: []string{"#12", "JIRA-456"}, Imperative: true,
+			},
+		},
+		{
+			message: "Fix: handle empty input\n\nThe parser crashed.\n\nBREAKING CHANGE: errors are returned\nRefs: #7",
+			want: ParsedMessage{
+				Subject: "Fix: handle empty input", Conventional: true, Type: "fix", Breaking: true,
+				Description: "handle empty input", IssueRefs: []string{"#7"}, HasBody: true, Imperative: true,
+			},
+		},
+		{
+			message: "Added tests\n\nSigned-off-by: Alice <alice@example.com>\n",
+			want:    ParsedMessage{Subject: "Added tests", Description: "Added tests"},
+		},
+		{
+			message: "Updates the docs for UTF8 and issue#3",
+			want:    ParsedMessage{Subject: "Updates the docs for UTF8 and issue#3", Description: "Updates the docs for UTF8 and issue#3"},
+		},
+		{
+			message: "Process queued jobs",
+			want:    ParsedMessage{Subject: "Process queued jobs", Description: "Process queued jobs", Imperative: true},
+		},
+	}
+	for _, tc := range testCases {
+		if got := ParseCommitMessage(tc.message, DefaultIssuePatterns("")); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("ParseCommitMessage(%q) = %+v, want %+v", tc.message, got, tc.want)
+		}
+	}
+}
+
+func TestCommitMessages(t *testing.T) {
+	jan := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	feb := time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC)
+	data := &models.CollectedData{
+		History: []models.CommitHistoryItem{
+			{Commit: "a", Contributor: "Alice (alice@example.com)", Date: jan, Message: "feat: add login (#1)"},
+			{Commit: "b", Contributor: "Bob (bob@example.com)", Date: jan, Message: "wip: stuff"},
+			{Commit: "c", Contributor: "Alice (alice@example.com)", Date: feb, Message: "Fixed a bug in the session handling code path, again"},
+			{Commit: "d", Contributor: "Alice (alice@example.com)", Date: feb, Message: "Merge branch 'x'", Parents: []string{"b", "c"}},
+		},
+	}
+	policy := models.MessagePolicy{MaxSubjectLength: 50, RequireConventional: true, AllowedTypes: DefaultCommitTypes}
+
+	result := CommitMessages(data, policy, DefaultIssuePatterns(""))
+
+	if result.Overall.Commits != 3 || result.Overall.Conventional != 2 || result.Overall.WithIssueRefs != 1 || result.Overall.Violations != 2 {
+		t.Errorf("Overall = %+v, want 3 commits, 2 conventional, 1 with issue references and 2 violations", result.Overall)
+	}
+	if want := map[string]int{"feat": 1, "wip": 1}; !reflect.DeepEqual(result.Overall.Types, want) {
+		t.Errorf("Overall.Types = %v, want %v", result.Overall.Types, want)
+	}
+	if alice := result.ByContributor["Alice"]; alice.Commits != 2 || alice.Imperative != 1 || alice.AverageSubjectLength != 36 {
+		t.Errorf("ByContributor[Alice] = %+v, want 2 commits, 1 imperative and 36 characters per subject", alice)
+	}
+	if result.ByMonth["2024-01"].Commits != 2 || result.ByMonth["2024-02"].Commits != 1 {
+		t.Errorf("ByMonth = %+v, want 2 commits in January and 1 in February", result.ByMonth)
+	}
+
+	if len(result.Violations) != 2 {
+		t.Fatalf("Violations = %+v, want c and b", result.Violations)
+	}
+	if got := result.Violations[0]; got.Commit != "c" || len(got.Problems) != 2 {
+		t.Errorf("Violations[0] = %+v, want c, too long and not conventional", got)
+	}
+	if want := []string{`type "wip" is not allowed`}; !reflect.DeepEqual(result.Violations[1].Problems, want) {
+		t.Errorf("Violations[1].Problems = %v, want %v", result.Violations[1].Problems, want)
+	}
+}
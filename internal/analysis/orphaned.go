@@ -0,0 +1,122 @@
+// Package analysis derives report-time analyses from collected data.
+package analysis
+
+import (
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/user/git-inquisitor-go/internal/models"
+)
+
+// OrphanedKnowledge measures the surviving lines owned by inactive contributors: those
+// whose last commit, authored or committed, is more than inactiveAfter before asOf.
+// Activity comes from the contributors' last commit dates and from the history; owners
+// with no known activity count as inactive. label describes inactiveAfter (such as "6mo").
+// Directories are the parent directories of the files, "." for the repository root.
+func OrphanedKnowledge(data *models.CollectedData, inactiveAfter time.Duration, label string, asOf time.Time) *models.OrphanedKnowledge {
+	lastActive := lastActivity(data)
+	cutoff := asOf.Add(-inactiveAfter)
+	inactive := func(name string) bool {
+		last, ok := lastActive[name]
+		return !ok || last.Before(cutoff)
+	}
+
+	result := &models.OrphanedKnowledge{
+		InactiveAfter:        label,
+		AsOf:                 asOf,
+		InactiveContributors: []string{},
+		Directories:          make(map[string]models.OrphanedShare),
+		Files:                make(map[string]models.OrphanedShare),
+	}
+	inactiveOwners := make(map[string]bool)
+	for filePath, file := range data.Files {
+		var share models.OrphanedShare
+		for name, lines := range file.LinesByContributor {
+			share.TotalLines += lines
+			if inactive(name) {
+				share.InactiveLines += lines
+				inactiveOwners[name] = true
+			}
+		}
+		if share.TotalLines == 0 {
+			continue
+		}
+		result.Files[filePath] = withPercentage(share)
+
+		dir := path.Dir(filePath)
+		dirShare := result.Directories[dir]
+		dirShare.TotalLines += share.TotalLines
+		dirShare.InactiveLines += share.InactiveLines
+		result.Directories[dir] = dirShare
+
+		result.Repository.TotalLines += share.TotalLines
+		result.Repository.InactiveLines += share.InactiveLines
+	}
+	for dir, share := range result.Directories {
+		result.Directories[dir] = withPercentage(share)
+	}
+	result.Repository = withPercentage(result.Repository)
+
+	for name := range inactiveOwners {
+		result.InactiveContributors = append(result.InactiveContributors, name)
+	}
+	sort.Strings(result.InactiveContributors)
+	return result
+}
+
+// ReferenceTime returns the time data's ages are measured up to: the date of the analyzed
+// HEAD commit, like the line ages computed by blame, so a report reads the same whenever
+// it is generated. Combined data of several repositories has no HEAD commit; the date of
+// its newest commit is used then, or the collection time if there is no history.
+func ReferenceTime(data *models.CollectedData) time.Time {
+	if date := data.Metadata.Repo.Commit.Date; !date.IsZero() {
+		return date
+	}
+	var newest time.Time
+	for _, item := range data.History {
+		if item.Date.After(newest) {
+			newest = item.Date
+		}
+	}
+	if newest.IsZero() {
+		return data.Metadata.Collector.DateCollected
+	}
+	return newest
+}
+
+// lastActivity returns the date of each contributor's most recent commit, by name.
+func lastActivity(data *models.CollectedData) map[string]time.Time {
+	lastActive := make(map[string]time.Time)
+	record := func(name string, when time.Time) {
+		if name != "" && when.After(lastActive[name]) {
+			lastActive[name] = when
+		}
+	}
+	for name, contributor := range data.Contributors {
+		record(name, contributor.LastCommitDate)
+	}
+	for _, item := range data.History {
+		record(ContributorName(item.Contributor), item.Date)
+		record(ContributorName(item.Author), item.Date)
+	}
+	return lastActive
+}
+
+// ContributorName returns the name of a "Name (email)" contributor, trimmed the way the
+// collector trims committer and blame names.
+func ContributorName(contributor string) string {
+	if i := strings.LastIndex(contributor, " ("); i >= 0 {
+		contributor = contributor[:i]
+	}
+	return strings.TrimSpace(strings.Split(contributor, "<")[0])
+}
+
+// withPercentage fills in the percentage of share's lines that are inactive.
+func withPercentage(share models.OrphanedShare) models.OrphanedShare {
+	if share.TotalLines > 0 {
+		share.Percentage = float64(share.InactiveLines) / float64(share.TotalLines) * 100
+	}
+	return share
+}
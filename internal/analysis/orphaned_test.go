@@ -0,0 +1,73 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/user/git-inquisitor-go/internal/models"
+	"github.com/user/git-inquisitor-go/internal/timeutil"
+)
+
+func TestOrphanedKnowledge(t *testing.T) {
+	asOf := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	data := &models.CollectedData{
+		Contributors: map[string]models.Contributor{
+			"Alice": {LastCommitDate: asOf.Add(-10 * timeutil.Day)},
+			"Bob":   {LastCommitDate: asOf.Add(-400 * timeutil.Day)},
+		},
+		History: []models.CommitHistoryItem{
+			// Carol only authors commits that Alice applies.
+			{Contributor: "Alice (alice@example.com)", Author: "Carol (carol@example.com)", Date: asOf.Add(-20 * timeutil.Day)},
+		},
+		Files: map[string]models.FileData{
+			"src/a.go":  {LinesByContributor: map[string]int{"Alice": 1, "Bob": 3}},
+			"src/b.go":  {LinesByContributor: map[string]int{"Carol": 4}},
+			"README.md": {LinesByContributor: map[string]int{"Dave": 2}}, // Never seen committing
+			"empty.txt": {},
+		},
+	}
+
+	result := OrphanedKnowledge(data, 6*timeutil.Month, "6mo", asOf)
+
+	if want := []string{"Bob", "Dave"}; !reflect.DeepEqual(result.InactiveContributors, want) {
+		t.Errorf("InactiveContributors = %v, want %v", result.InactiveContributors, want)
+	}
+	if want := (models.OrphanedShare{TotalLines: 10, InactiveLines: 5, Percentage: 50}); result.Repository != want {
+		t.Errorf("Repository = %+v, want %+v", result.Repository, want)
+	}
+	if want := (models.OrphanedShare{TotalLines: 8, InactiveLines: 3, Percentage: 37.5}); result.Directories["src"] != want {
+		t.Errorf("Directories[src] = %+v, want %+v", result.Directories["src"], want)
+	}
+	if result.Directories["."].Percentage != 100 || result.Files["src/a.go"].Percentage != 75 {
+		t.Errorf("Directories[.] = %+v, Files[src/a.go] = %+v, want 100%% and 75%%", result.Directories["."], result.Files["src/a.go"])
+	}
+	if _, ok := result.Files["empty.txt"]; ok {
+		t.Error("Files contains empty.txt, which has no lines")
+	}
+}
+
+func TestReferenceTime(t *testing.T) {
+	head := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	collected := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	data := &models.CollectedData{
+		Metadata: models.Metadata{
+			Collector: models.CollectorMetadata{DateCollected: collected},
+			Repo:      models.RepoMetadata{Commit: models.CommitDetails{Date: head}},
+		},
+		History: []models.CommitHistoryItem{{Date: head.Add(-time.Hour)}, {Date: head.Add(-48 * time.Hour)}},
+	}
+	if got := ReferenceTime(data); !got.Equal(head) {
+		t.Errorf("ReferenceTime() = %v, want the HEAD commit date %v", got, head)
+	}
+
+	// Combined data has no HEAD commit.
+	data.Metadata.Repo.Commit = models.CommitDetails{}
+	if got := ReferenceTime(data); !got.Equal(head.Add(-time.Hour)) {
+		t.Errorf("ReferenceTime() without HEAD = %v, want the newest commit date %v", got, head.Add(-time.Hour))
+	}
+	data.History = nil
+	if got := ReferenceTime(data); !got.Equal(collected) {
+		t.Errorf("ReferenceTime() without history = %v, want the collection date %v", got, collected)
+	}
+}
@@ -0,0 +1,429 @@
+// Package changelog builds changelogs of the commits between two revisions from a
+// collected history and renders them as Markdown, JSON or with a custom template.
+package changelog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/user/git-inquisitor-go/internal/analysis"
+	"github.com/user/git-inquisitor-go/internal/models"
+)
+
+// Ways of grouping the entries of a changelog.
+const (
+	GroupByType      = "type"
+	GroupByDirectory = "directory"
+)
+
+// otherGroup holds the commits without a Conventional Commit type, or without changed
+// files when grouping by directory.
+const otherGroup = ""
+
+// typeTitles are the section titles of the well-known Conventional Commit types, in
+// the order the sections are listed.
+var typeTitles = []struct{ Type, Title string }{
+	{"feat", "Features"},
+	{"fix", "Bug Fixes"},
+	{"perf", "Performance Improvements"},
+	{"refactor", "Code Refactoring"},
+	{"docs", "Documentation"},
+	{"test", "Tests"},
+	{"build", "Build System"},
+	{"ci", "Continuous Integration"},
+	{"chore", "Chores"},
+	{"style", "Styles"},
+	{"revert", "Reverts"},
+}
+
+// Options selects the commits of a changelog and how they are grouped.
+type Options struct {
+	// From and To name the revisions, as given by the user; To defaults to "HEAD".
+	From, To string
+	// FromCommit and ToCommit are their commit hashes. The changelog holds the commits
+	// reachable from ToCommit but not from FromCommit; an empty FromCommit starts at the
+	// first commit.
+	FromCommit, ToCommit string
+	// GroupBy is GroupByType (the default) or GroupByDirectory.
+	GroupBy string
+	// IssuePatterns recognize the issue references of the commit messages.
+	IssuePatterns []analysis.IssuePattern
+}
+
+// Changelog lists the changes between two revisions.
+type Changelog struct {
+	From       string    `json:"from,omitempty"`
+	To         string    `json:"to"`
+	FromCommit string    `json:"from_commit,omitempty"`
+	ToCommit   string    `json:"to_commit"`
+	Date       time.Time `json:"date"` // Committer date of ToCommit
+	GroupBy    string    `json:"group_by"`
+	Stats      Stats     `json:"stats"`
+	// Breaking repeats the entries announcing breaking changes, newest first.
+	Breaking []Entry `json:"breaking,omitempty"`
+	Groups   []Group `json:"groups"`
+	// Contributors credits the authors of the changes, most commits first.
+	Contributors []Credit `json:"contributors"`
+}
+
+// Stats totals the changes of a changelog; merge commits are not counted.
+type Stats struct {
+	Commits      int `json:"commits"`
+	Contributors int `json:"contributors"`
+	FilesChanged int `json:"files_changed"`
+	Insertions   int `json:"insertions"`
+	Deletions    int `json:"deletions"`
+}
+
+// Group is a section of a changelog: the entries of a commit type or of a top-level
+// directory, newest first.
+type Group struct {
+	Key     string  `json:"key"` // The commit type or directory; empty for other changes
+	Title   string  `json:"title"`
+	Entries []Entry `json:"entries"`
+}
+
+// Entry is a commit listed in a changelog.
+type Entry struct {
+	Commit  string `json:"commit"`
+	Subject string `json:"subject"`
+	// Title is the text listed: the description when grouping by type, as the group
+	// already tells the type, and the subject otherwise.
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Type        string    `json:"type,omitempty"`
+	Scope       string    `json:"scope,omitempty"`
+	Breaking    bool      `json:"breaking,omitempty"`
+	Author      string    `json:"author"`
+	Date        time.Time `json:"date"`
+	Insertions  int       `json:"insertions"`
+	Deletions   int       `json:"deletions"`
+	Files       int       `json:"files"`
+	Issues      []Issue   `json:"issues,omitempty"`
+}
+
+// Issue is an issue reference of an entry.
+type Issue struct {
+	Key string `json:"key"`
+	URL string `json:"url,omitempty"`
+}
+
+// Credit is a contributor's share of a changelog.
+type Credit struct {
+	Name       string `json:"name"`
+	Commits    int    `json:"commits"`
+	Insertions int    `json:"insertions"`
+	Deletions  int    `json:"deletions"`
+}
+
+// ValidateGroupBy checks that groupBy is a known way of grouping entries.
+func ValidateGroupBy(groupBy string) error {
+	switch groupBy {
+	case GroupByType, GroupByDirectory:
+		return nil
+	}
+	return fmt.Errorf("invalid grouping '%s'. Must be '%s' or '%s'", groupBy, GroupByType, GroupByDirectory)
+}
+
+// PreviousRelease returns the name and commit of the latest release of data whose
+// tagged commit is a proper ancestor of toCommit, or empty strings if there is none.
+func PreviousRelease(data *models.CollectedData, toCommit string) (string, string) {
+	ancestors := reachable(historyIndex(data), toCommit, nil)
+	var name, commit string
+	var date time.Time
+	for _, release := range data.Releases {
+		if release.Unreleased || release.Commit == toCommit || !ancestors[release.Commit] {
+			continue
+		}
+		if commit == "" || release.Date.After(date) {
+			name, commit, date = release.Name, release.Commit, release.Date
+		}
+	}
+	return name, commit
+}
+
+// Build lists the non-merge commits of data's history between opts.FromCommit and
+// opts.ToCommit. Both must be in the history.
+func Build(data *models.CollectedData, opts Options) (*Changelog, error) {
+	if opts.GroupBy == "" {
+		opts.GroupBy = GroupByType
+	}
+	if err := ValidateGroupBy(opts.GroupBy); err != nil {
+		return nil, err
+	}
+	if opts.To == "" {
+		opts.To = "HEAD"
+	}
+	index := historyIndex(data)
+	to, ok := index[opts.ToCommit]
+	if !ok {
+		return nil, fmt.Errorf("%s (%s) is not in the collected history", opts.To, opts.ToCommit)
+	}
+	var excluded map[string]bool
+	if opts.FromCommit != "" {
+		if _, ok := index[opts.FromCommit]; !ok {
+			return nil, fmt.Errorf("%s (%s) is not in the collected history", opts.From, opts.FromCommit)
+		}
+		excluded = reachable(index, opts.FromCommit, nil)
+	}
+
+	var items []*models.CommitHistoryItem
+	for sha := range reachable(index, opts.ToCommit, excluded) {
+		if item := index[sha]; len(item.Parents) <= 1 {
+			items = append(items, item)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].Date.Equal(items[j].Date) {
+			return items[i].Date.After(items[j].Date)
+		}
+		return items[i].Commit < items[j].Commit
+	})
+
+	changelog := &Changelog{
+		From:       opts.From,
+		To:         opts.To,
+		FromCommit: opts.FromCommit,
+		ToCommit:   opts.ToCommit,
+		Date:       to.Date,
+		GroupBy:    opts.GroupBy,
+		Breaking:   []Entry{},
+		Groups:     []Group{},
+	}
+	groups := make(map[string]*Group)
+	credits := make(map[string]*Credit)
+	files := make(map[string]bool)
+	for _, item := range items {
+		entry, parsed := newEntry(item, opts)
+		for _, key := range groupKeys(item, parsed, opts.GroupBy) {
+			group := groups[key]
+			if group == nil {
+				group = &Group{Key: key, Title: groupTitle(key, opts.GroupBy)}
+				groups[key] = group
+			}
+			group.Entries = append(group.Entries, entry)
+		}
+		if entry.Breaking {
+			changelog.Breaking = append(changelog.Breaking, entry)
+		}
+
+		credit := credits[entry.Author]
+		if credit == nil {
+			credit = &Credit{Name: entry.Author}
+			credits[entry.Author] = credit
+		}
+		credit.Commits++
+		credit.Insertions += item.Insertions
+		credit.Deletions += item.Deletions
+
+		changelog.Stats.Commits++
+		changelog.Stats.Insertions += item.Insertions
+		changelog.Stats.Deletions += item.Deletions
+		for path := range item.FilesChanged {
+			files[path] = true
+		}
+	}
+	changelog.Stats.FilesChanged = len(files)
+	changelog.Stats.Contributors = len(credits)
+
+	for _, group := range groups {
+		changelog.Groups = append(changelog.Groups, *group)
+	}
+	sortGroups(changelog.Groups, opts.GroupBy)
+	changelog.Contributors = make([]Credit, 0, len(credits))
+	for _, credit := range credits {
+		changelog.Contributors = append(changelog.Contributors, *credit)
+	}
+	sort.Slice(changelog.Contributors, func(i, j int) bool {
+		a, b := changelog.Contributors[i], changelog.Contributors[j]
+		if a.Commits != b.Commits {
+			return a.Commits > b.Commits
+		}
+		return a.Name < b.Name
+	})
+	return changelog, nil
+}
+
+// historyIndex maps the commits of data's history to their items.
+func historyIndex(data *models.CollectedData) map[string]*models.CommitHistoryItem {
+	index := make(map[string]*models.CommitHistoryItem, len(data.History))
+	for i := range data.History {
+		index[data.History[i].Commit] = &data.History[i]
+	}
+	return index
+}
+
+// reachable returns the commits of index reachable from start, start included, without
+// walking into the commits of excluded.
+func reachable(index map[string]*models.CommitHistoryItem, start string, excluded map[string]bool) map[string]bool {
+	seen := make(map[string]bool)
+	stack := []string{start}
+	for len(stack) > 0 {
+		sha := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		item, ok := index[sha]
+		if !ok || seen[sha] || excluded[sha] {
+			continue
+		}
+		seen[sha] = true
+		stack = append(stack, item.Parents...)
+	}
+	return seen
+}
+
+// newEntry describes item for the changelog.
+func newEntry(item *models.CommitHistoryItem, opts Options) (Entry, analysis.ParsedMessage) {
+	parsed := analysis.ParseCommitMessage(item.Message, opts.IssuePatterns)
+	author := item.Author
+	if author == "" {
+		author = item.Contributor
+	}
+	entry := Entry{
+		Commit:      item.Commit,
+		Subject:     parsed.Subject,
+		Title:       parsed.Subject,
+		Description: parsed.Description,
+		Type:        parsed.Type,
+		Scope:       parsed.Scope,
+		Breaking:    parsed.Breaking,
+		Author:      analysis.ContributorName(author),
+		Date:        item.Date,
+		Insertions:  item.Insertions,
+		Deletions:   item.Deletions,
+		Files:       len(item.FilesChanged),
+	}
+	if opts.GroupBy == GroupByType && parsed.Conventional {
+		entry.Title = parsed.Description
+	}
+	for _, ref := range parsed.IssueRefs {
+		entry.Issues = append(entry.Issues, Issue{Key: ref, URL: parsed.IssueURLs[ref]})
+	}
+	return entry, parsed
+}
+
+// groupKeys returns the groups item is listed in: its commit type, or every top-level
+// directory it changes ("." for files at the root).
+func groupKeys(item *models.CommitHistoryItem, parsed analysis.ParsedMessage, groupBy string) []string {
+	if groupBy == GroupByType {
+		if parsed.Conventional {
+			return []string{parsed.Type}
+		}
+		return []string{otherGroup}
+	}
+	dirs := make(map[string]bool)
+	for path := range item.FilesChanged {
+		dir, _, found := strings.Cut(path, "/")
+		if !found {
+			dir = "."
+		}
+		dirs[dir] = true
+	}
+	if len(dirs) == 0 {
+		return []string{otherGroup}
+	}
+	keys := make([]string, 0, len(dirs))
+	for dir := range dirs {
+		keys = append(keys, dir)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// groupTitle returns the section title of the group key.
+func groupTitle(key, groupBy string) string {
+	switch {
+	case key == otherGroup:
+		return "Other Changes"
+	case groupBy == GroupByDirectory && key == ".":
+		return "Root Directory"
+	case groupBy == GroupByDirectory:
+		return key + "/"
+	}
+	for _, known := range typeTitles {
+		if known.Type == key {
+			return known.Title
+		}
+	}
+	return strings.ToUpper(key[:1]) + key[1:]
+}
+
+// sortGroups orders commit types as typeTitles does, followed by the other types by
+// name, and directories by the number of entries. Other changes always come last.
+func sortGroups(groups []Group, groupBy string) {
+	rank := func(key string) int {
+		for i, known := range typeTitles {
+			if known.Type == key {
+				return i
+			}
+		}
+		return len(typeTitles)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		a, b := groups[i], groups[j]
+		if (a.Key == otherGroup) != (b.Key == otherGroup) {
+			return b.Key == otherGroup
+		}
+		if groupBy == GroupByType {
+			if rank(a.Key) != rank(b.Key) {
+				return rank(a.Key) < rank(b.Key)
+			}
+		} else if len(a.Entries) != len(b.Entries) {
+			return len(a.Entries) > len(b.Entries)
+		}
+		return a.Key < b.Key
+	})
+}
+
+// templateFuncs are the functions available to changelog templates.
+var templateFuncs = template.FuncMap{
+	"ShortSha": func(sha string) string {
+		if len(sha) > 8 {
+			return sha[:8]
+		}
+		return sha
+	},
+	"FormatDate": func(t time.Time) string { return t.Format("2006-01-02") },
+	"Join":       strings.Join,
+}
+
+// ParseTemplate parses a changelog template. Templates are text/template templates
+// executed with a *Changelog, and may use the ShortSha, FormatDate and Join functions.
+func ParseTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse changelog template %s: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+// Render executes tmpl with c and writes the result to w.
+func (c *Changelog) Render(w io.Writer, tmpl *template.Template) error {
+	if err := tmpl.Execute(w, c); err != nil {
+		return fmt.Errorf("failed to render changelog: %w", err)
+	}
+	return nil
+}
+
+// Markdown writes c to w as Markdown, using DefaultTemplate.
+func (c *Changelog) Markdown(w io.Writer) error {
+	tmpl, err := ParseTemplate("default", DefaultTemplate)
+	if err != nil {
+		return err
+	}
+	return c.Render(w, tmpl)
+}
+
+// JSON writes c to w as indented JSON.
+func (c *Changelog) JSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(c); err != nil {
+		return fmt.Errorf("failed to encode changelog: %w", err)
+	}
+	return nil
+}
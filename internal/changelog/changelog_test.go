@@ -0,0 +1,174 @@
+package changelog
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/git-inquisitor-go/internal/analysis"
+	"github.com/user/git-inquisitor-go/internal/models"
+)
+
+// testHistory is a linear history a, b (tagged v1.0), c, d with a merge m of a side
+// commit s on top.
+func testHistory() *models.CollectedData {
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 12, 0, 0, 0, time.UTC) }
+	files := func(paths ...string) map[string]models.FileCommitStats {
+		changed := make(map[string]models.FileCommitStats)
+		for _, path := range paths {
+			changed[path] = models.FileCommitStats{Insertions: 1}
+		}
+		return changed
+	}
+	return &models.CollectedData{
+		History: []models.CommitHistoryItem{
+			{Commit: "a", Contributor: "Alice (alice@example.com)", Date: day(1), Message: "Initial commit", Insertions: 10,
+				FilesChanged: files("README.md")},
+			{Commit: "b", Parents: []string{"a"}, Contributor: "Alice (alice@example.com)", Date: day(2), Message: "feat: add login", Insertions: 20,
+				FilesChanged: files("auth/login.go")},
+			{Commit: "c", Parents: []string{"b"}, Contributor: "Bob (bob@example.com)", Date: day(3), Message: "fix(auth): reject empty passwords (#12)",
+				Insertions: 3, Deletions: 1, FilesChanged: files("auth/login.go", "auth/login_test.go")},
+			{Commit: "d", Parents: []string{"c"}, Contributor: "Alice (alice@example.com)", Date: day(4),
+				Message: "feat(api)!: drop v1 endpoints\n\nBREAKING CHANGE: clients must use /v2.", Deletions: 40, FilesChanged: files("api/v1.go", "go.mod")},
+			{Commit: "s", Parents: []string{"c"}, Contributor: "Carol (carol@example.com)", Author: "Dave (dave@example.com)", Date: day(5),
+				Message: "Tidy up docs", Insertions: 2, FilesChanged: files("docs/index.md")},
+			{Commit: "m", Parents: []string{"d", "s"}, Contributor: "Alice (alice@example.com)", Date: day(6), Message: "Merge branch 'docs'"},
+		},
+		Releases: []models.Release{
+			{Name: "v1.0", Commit: "b", Date: day(2)},
+			{Name: "Unreleased", Unreleased: true, Date: day(6)},
+		},
+	}
+}
+
+func TestBuild_ByType(t *testing.T) {
+	data := testHistory()
+	changelog, err := Build(data, Options{
+		From: "v1.0", FromCommit: "b", ToCommit: "m",
+		IssuePatterns: analysis.DefaultIssuePatterns("https://github.com/acme/widgets"),
+	})
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	if changelog.To != "HEAD" || changelog.GroupBy != GroupByType || !changelog.Date.Equal(data.History[5].Date) {
+		t.Errorf("changelog = %s %s %v, want HEAD grouped by type at the date of m", changelog.To, changelog.GroupBy, changelog.Date)
+	}
+	want := Stats{Commits: 3, Contributors: 3, FilesChanged: 5, Insertions: 5, Deletions: 41}
+	if changelog.Stats != want {
+		t.Errorf("Stats = %+v, want %+v", changelog.Stats, want)
+	}
+
+	var titles []string
+	for _, group := range changelog.Groups {
+		titles = append(titles, group.Title)
+	}
+	if got := strings.Join(titles, ", "); got != "Features, Bug Fixes, Other Changes" {
+		t.Errorf("group titles = %s, want Features, Bug Fixes, Other Changes", got)
+	}
+	fix := changelog.Groups[1].Entries[0]
+	if fix.Commit != "c" || fix.Title != "reject empty passwords (#12)" || fix.Scope != "auth" || fix.Author != "Bob" {
+		t.Errorf("fix entry = %+v", fix)
+	}
+	if len(fix.Issues) != 1 || fix.Issues[0].URL != "https://github.com/acme/widgets/issues/12" {
+		t.Errorf("fix issues = %+v, want #12 linked", fix.Issues)
+	}
+	if other := changelog.Groups[2].Entries[0]; other.Author != "Dave" || other.Title != "Tidy up docs" {
+		t.Errorf("other entry = %+v, want Dave's commit credited to its author", other)
+	}
+	if len(changelog.Breaking) != 1 || changelog.Breaking[0].Commit != "d" {
+		t.Errorf("Breaking = %+v, want d", changelog.Breaking)
+	}
+	if changelog.Contributors[0].Name != "Alice" || changelog.Contributors[0].Deletions != 40 {
+		t.Errorf("Contributors = %+v, want Alice first", changelog.Contributors)
+	}
+}
+
+func TestBuild_ByDirectory(t *testing.T) {
+	changelog, err := Build(testHistory(), Options{ToCommit: "d", GroupBy: GroupByDirectory})
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if changelog.Stats.Commits != 4 {
+		t.Errorf("Commits = %d, want every commit up to d", changelog.Stats.Commits)
+	}
+	var keys []string
+	for _, group := range changelog.Groups {
+		keys = append(keys, group.Key)
+	}
+	if got := strings.Join(keys, ","); got != ".,auth,api" {
+		t.Errorf("group keys = %s, want .,auth,api", got)
+	}
+	if entry := changelog.Groups[1].Entries[0]; entry.Title != "fix(auth): reject empty passwords (#12)" {
+		t.Errorf("entry title = %q, want the whole subject", entry.Title)
+	}
+}
+
+func TestBuild_Errors(t *testing.T) {
+	if _, err := Build(testHistory(), Options{ToCommit: "x"}); err == nil {
+		t.Error("Build() with an unknown commit error = nil, want an error")
+	}
+	if _, err := Build(testHistory(), Options{ToCommit: "d", GroupBy: "author"}); err == nil {
+		t.Error("Build() with an unknown grouping error = nil, want an error")
+	}
+}
+
+func TestPreviousRelease(t *testing.T) {
+	if name, commit := PreviousRelease(testHistory(), "m"); name != "v1.0" || commit != "b" {
+		t.Errorf("PreviousRelease(m) = %s %s, want v1.0 b", name, commit)
+	}
+	if name, _ := PreviousRelease(testHistory(), "b"); name != "" {
+		t.Errorf("PreviousRelease(b) = %s, want none", name)
+	}
+}
+
+func TestRender(t *testing.T) {
+	changelog, err := Build(testHistory(), Options{
+		From: "v1.0", FromCommit: "b", ToCommit: "m",
+		IssuePatterns: analysis.DefaultIssuePatterns("https://github.com/acme/widgets"),
+	})
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	var markdown bytes.Buffer
+	if err := changelog.Markdown(&markdown); err != nil {
+		t.Fatalf("Markdown() error = %v", err)
+	}
+	for _, want := range []string{
+		"# v1.0...HEAD (2024-03-06)\n\n3 commits by 3 contributors, 5 files changed, +5 -41\n",
+		"## Breaking Changes\n\n- **api:** drop v1 endpoints (d) by Alice\n",
+		"## Bug Fixes\n\n- **auth:** reject empty passwords (#12) (c, [#12](https://github.com/acme/widgets/issues/12)) by Bob\n",
+		"## Contributors\n\n- Alice: 1 commits, +0 -40\n",
+	} {
+		if !strings.Contains(markdown.String(), want) {
+			t.Errorf("Markdown() = %s\nwant it to contain %q", markdown.String(), want)
+		}
+	}
+
+	var encoded bytes.Buffer
+	if err := changelog.JSON(&encoded); err != nil {
+		t.Fatalf("JSON() error = %v", err)
+	}
+	var decoded Changelog
+	if err := json.Unmarshal(encoded.Bytes(), &decoded); err != nil || decoded.Stats != changelog.Stats {
+		t.Errorf("JSON() round trip = %+v, %v", decoded.Stats, err)
+	}
+
+	tmpl, err := ParseTemplate("custom", `{{ range .Groups }}{{ .Key }}={{ len .Entries }} {{ end }}`)
+	if err != nil {
+		t.Fatalf("ParseTemplate() error = %v", err)
+	}
+	var custom bytes.Buffer
+	if err := changelog.Render(&custom, tmpl); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if custom.String() != "feat=1 fix=1 =1 " {
+		t.Errorf("Render() = %q, want the group sizes", custom.String())
+	}
+	if _, err := ParseTemplate("broken", "{{ .Groups "); err == nil {
+		t.Error("ParseTemplate() of a broken template error = nil, want an error")
+	}
+}
@@ -0,0 +1,30 @@
+package changelog
+
+// DefaultTemplate renders a changelog as Markdown: a heading with the range and date,
+// the totals, the breaking changes, one section per group and the contributor credits.
+const DefaultTemplate = `# {{ if .From }}{{ .From }}...{{ end }}{{ .To }} ({{ FormatDate .Date }})
+
+{{ .Stats.Commits }} commits by {{ .Stats.Contributors }} contributors, {{ .Stats.FilesChanged }} files changed, +{{ .Stats.Insertions }} -{{ .Stats.Deletions }}
+{{- if .Breaking }}
+
+## Breaking Changes
+{{ range .Breaking }}
+- {{ template "entry" . }}
+{{- end }}
+{{- end }}
+{{- range .Groups }}
+
+## {{ .Title }}
+{{ range .Entries }}
+- {{ template "entry" . }}
+{{- end }}
+{{- end }}
+{{- if .Contributors }}
+
+## Contributors
+{{ range .Contributors }}
+- {{ .Name }}: {{ .Commits }} commits, +{{ .Insertions }} -{{ .Deletions }}
+{{- end }}
+{{- end }}
+{{ define "entry" }}{{ if .Scope }}**{{ .Scope }}:** {{ end }}{{ .Title }} ({{ ShortSha .Commit }}
+{{- range .Issues }}, {{ if .URL }}[{{ .Key }}]({{ .URL }}){{ else }}{{ .Key }}{{ end }}{{ end }}) by {{ .Author }}{{ end }}`
@@ -0,0 +1,83 @@
+package collector
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/user/git-inquisitor-go/internal/models"
+)
+
+// recordActivity adds a commit made at when to the activity timeline of the contributor
+// called name: first and last commit dates, weekly and monthly series and active days.
+// Dates are taken in the commit's own time zone, so a day is the committer's day.
+func (gdc *GitDataCollector) recordActivity(name string, contributor *models.Contributor, when time.Time) {
+	if contributor.FirstCommitDate.IsZero() || when.Before(contributor.FirstCommitDate) {
+		contributor.FirstCommitDate = when
+	}
+	if when.After(contributor.LastCommitDate) {
+		contributor.LastCommitDate = when
+	}
+	if contributor.WeeklyCommits == nil {
+		contributor.WeeklyCommits = make(map[string]int)
+		contributor.MonthlyCommits = make(map[string]int)
+	}
+	contributor.WeeklyCommits[isoWeek(when)]++
+	contributor.MonthlyCommits[when.Format("2006-01")]++
+
+	if gdc.activeDays == nil {
+		gdc.activeDays = make(map[string]map[int64]bool)
+	}
+	if gdc.activeDays[name] == nil {
+		gdc.activeDays[name] = make(map[int64]bool)
+	}
+	gdc.activeDays[name][civilDay(when)] = true
+}
+
+// finishActivity sets the active days and longest streak of every contributor from the
+// days recorded by recordActivity, and releases them.
+func (gdc *GitDataCollector) finishActivity() {
+	for name, days := range gdc.activeDays {
+		contributor, ok := gdc.Data.Contributors[name]
+		if !ok {
+			continue
+		}
+		sorted := make([]int64, 0, len(days))
+		for day := range days {
+			sorted = append(sorted, day)
+		}
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		contributor.ActiveDays = len(sorted)
+		contributor.LongestStreak = longestStreak(sorted)
+		gdc.Data.Contributors[name] = contributor
+	}
+	gdc.activeDays = nil
+}
+
+// longestStreak returns the length of the longest run of consecutive days in days, sorted.
+func longestStreak(days []int64) int {
+	longest, current := 0, 0
+	for i, day := range days {
+		if i > 0 && day == days[i-1]+1 {
+			current++
+		} else {
+			current = 1
+		}
+		if current > longest {
+			longest = current
+		}
+	}
+	return longest
+}
+
+// civilDay numbers the calendar day of t, in t's time zone, counting from the Unix epoch.
+func civilDay(t time.Time) int64 {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Unix() / (24 * 60 * 60)
+}
+
+// isoWeek formats the ISO 8601 week of t, such as "2024-W05".
+func isoWeek(t time.Time) string {
+	year, week := t.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
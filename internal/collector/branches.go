@@ -0,0 +1,99 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"github.com/user/git-inquisitor-go/internal/models"
+	"github.com/user/git-inquisitor-go/pkg/gitutil"
+)
+
+// collectBranches lists the repository's local and remote-tracking branches with their
+// last commit and, if there is a default branch, how far each one is ahead and behind
+// it, whether it is merged and which files it changes since the merge base.
+func (gdc *GitDataCollector) collectBranches(ctx context.Context) error {
+	refs, err := gitutil.ListBranches(gdc.repo)
+	if err != nil {
+		return err
+	}
+	landscape := &models.BranchLandscape{
+		Default:  gitutil.DefaultBranch(gdc.repo, refs),
+		Branches: make([]models.Branch, 0, len(refs)),
+	}
+	var base plumbing.Hash
+	tips := make([]plumbing.Hash, len(refs))
+	for i, ref := range refs {
+		if ref.Name == landscape.Default {
+			base = ref.Hash
+		}
+		tips[i] = ref.Hash
+	}
+	var comparisons []gitutil.BranchComparison
+	if landscape.Default != "" {
+		gdc.reporter().StartPhase("Comparing branches", 0)
+		comparisons, err = gitutil.CompareBranches(ctx, gdc.repo, base, tips)
+		gdc.reporter().EndPhase()
+		if err != nil {
+			return fmt.Errorf("failed to compare branches with %s: %w", landscape.Default, err)
+		}
+	}
+
+	for i, ref := range refs {
+		branch := models.Branch{
+			Name:    ref.Name,
+			Remote:  ref.Remote,
+			Default: ref.Name == landscape.Default,
+			Commit:  ref.Hash.String(),
+		}
+		if commit, err := gdc.repo.CommitObject(ref.Hash); err != nil {
+			gdc.warn(fmt.Sprintf("could not read the last commit of branch %s", ref.Name), err)
+		} else {
+			branch.LastCommitDate = commit.Committer.When
+			branch.LastCommitAuthor = fmt.Sprintf("%s (%s)", commit.Author.Name, commit.Author.Email)
+		}
+		if comparisons != nil {
+			comparison := comparisons[i]
+			branch.Ahead, branch.Behind = comparison.Ahead, comparison.Behind
+			branch.Merged = comparison.Ahead == 0
+			if !comparison.MergeBase.IsZero() {
+				branch.MergeBase = comparison.MergeBase.String()
+			}
+			if comparison.Ahead > 0 {
+				if branch.Files, err = gitutil.ChangedFiles(ctx, gdc.repo, comparison.MergeBase, ref.Hash); err != nil {
+					gdc.warn(fmt.Sprintf("could not list the files changed on branch %s", ref.Name), err)
+				}
+			}
+		}
+		landscape.Branches = append(landscape.Branches, branch)
+	}
+	gdc.Data.Branches = landscape
+	return nil
+}
+
+// refreshBranches collects the branches again when they moved since the loaded cache was
+// written, so reports from a cache show the current branches. The cache is not rewritten.
+func (gdc *GitDataCollector) refreshBranches(ctx context.Context) error {
+	if gdc.repo == nil {
+		return nil
+	}
+	refs, err := gitutil.ListBranches(gdc.repo)
+	if err != nil {
+		return err
+	}
+	if recorded := gdc.Data.Branches; recorded != nil && len(recorded.Branches) == len(refs) {
+		unchanged := true
+		for i, ref := range refs {
+			if recorded.Branches[i].Name != ref.Name || recorded.Branches[i].Commit != ref.Hash.String() {
+				unchanged = false
+				break
+			}
+		}
+		if unchanged {
+			return nil
+		}
+	}
+	gdc.reporter().Infof("Branches changed since collection. Comparing them again.")
+	return gdc.collectBranches(ctx)
+}
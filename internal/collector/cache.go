@@ -0,0 +1,246 @@
+package collector
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/user/git-inquisitor-go/internal/store"
+)
+
+// cacheSourceFile records, inside a repository's cache directory, which path or URL it belongs to.
+const cacheSourceFile = "source"
+
+// CacheEntry describes the cached collection of one repository at one HEAD commit.
+type CacheEntry struct {
+	Repo    string    // Name of the repository's cache directory
+	Source  string    // Repository path or URL, if recorded
+	Commit  string    // HEAD SHA the data was collected for
+	Path    string    // Path of the cache store (or legacy cache file)
+	Size    int64     // Size in bytes
+	ModTime time.Time // When the cache was written
+}
+
+// DefaultCacheDir returns the default root directory for caches and mirrors,
+// honouring XDG_CACHE_HOME (or the platform equivalent).
+func DefaultCacheDir() string {
+	userCacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "git-inquisitor")
+	}
+	return filepath.Join(userCacheDir, "git-inquisitor")
+}
+
+// RepoCacheDir returns the directory holding the caches of the repository at source
+// (an absolute path or a URL) below the cache root cacheDir.
+func RepoCacheDir(cacheDir, source string) string {
+	return filepath.Join(cacheDir, "repos", cacheKey(source))
+}
+
+// MirrorsDir returns the directory holding bare mirrors of remote repositories below cacheDir.
+func MirrorsDir(cacheDir string) string {
+	return filepath.Join(cacheDir, "mirrors")
+}
+
+// LegacyCacheDir returns the directory inside the repository at repoPath where caches
+// were kept before they moved below the cache root.
+func LegacyCacheDir(repoPath string) string {
+	return filepath.Join(repoPath, ".inquisitor", "cache")
+}
+
+// cacheKey derives a stable, filesystem safe directory name for a repository path or URL.
+func cacheKey(source string) string {
+	sum := sha256.Sum256([]byte(source))
+	name := strings.TrimSuffix(filepath.Base(strings.TrimRight(source, "/")), ".git")
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+	return name + "-" + hex.EncodeToString(sum[:])[:12]
+}
+
+// ListCache returns the cache entries below cacheDir, newest first.
+// When repoDir is not empty only the entries of that repository cache directory are listed.
+func ListCache(cacheDir, repoDir string) ([]CacheEntry, error) {
+	repoDirs := []string{repoDir}
+	if repoDir == "" {
+		dirEntries, err := os.ReadDir(filepath.Join(cacheDir, "repos"))
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cache directory %s: %w", cacheDir, err)
+		}
+		repoDirs = repoDirs[:0]
+		for _, dirEntry := range dirEntries {
+			if dirEntry.IsDir() {
+				repoDirs = append(repoDirs, filepath.Join(cacheDir, "repos", dirEntry.Name()))
+			}
+		}
+	}
+
+	var entries []CacheEntry
+	for _, dir := range repoDirs {
+		repoEntries, err := listRepoCache(dir)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, repoEntries...)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].ModTime.After(entries[j].ModTime)
+	})
+	return entries, nil
+}
+
+// ListLegacyCache returns the cache entries left in the legacy cache directory of the
+// repository at repoPath, newest first. They are grouped with the repository's entries
+// below the cache root.
+func ListLegacyCache(repoPath string) ([]CacheEntry, error) {
+	entries, err := listRepoCache(LegacyCacheDir(repoPath))
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		entries[i].Repo = cacheKey(repoPath)
+		entries[i].Source = repoPath
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].ModTime.After(entries[j].ModTime)
+	})
+	return entries, nil
+}
+
+// listRepoCache lists the caches of a single repository cache directory.
+func listRepoCache(dir string) ([]CacheEntry, error) {
+	dirEntries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache directory %s: %w", dir, err)
+	}
+
+	source := ""
+	if content, errSource := os.ReadFile(filepath.Join(dir, cacheSourceFile)); errSource == nil { // #nosec G304 -- path inside the cache dir
+		source = strings.TrimSpace(string(content))
+	}
+
+	var entries []CacheEntry
+	for _, dirEntry := range dirEntries {
+		name := dirEntry.Name()
+		path := filepath.Join(dir, name)
+		commit := ""
+		switch {
+		case strings.HasPrefix(name, "."):
+			continue // Store being written or replaced
+		case dirEntry.IsDir() && store.Exists(path):
+			commit = name
+		case !dirEntry.IsDir() && strings.HasSuffix(name, store.LegacySuffix):
+			commit = strings.TrimSuffix(name, store.LegacySuffix)
+		default:
+			continue
+		}
+
+		info, errInfo := dirEntry.Info()
+		if errInfo != nil {
+			continue // Removed concurrently
+		}
+		size := info.Size()
+		if dirEntry.IsDir() {
+			if size, errInfo = DirSize(path); errInfo != nil {
+				continue
+			}
+		}
+		entries = append(entries, CacheEntry{
+			Repo:    filepath.Base(dir),
+			Source:  source,
+			Commit:  commit,
+			Path:    path,
+			Size:    size,
+			ModTime: info.ModTime(),
+		})
+	}
+	return entries, nil
+}
+
+// PruneCache removes cache entries that are neither among the keep newest entries
+// of their repository nor newer than maxAge. A zero keep or maxAge disables that criterion,
+// but at least one of them must be set. It returns the removed entries.
+func PruneCache(entries []CacheEntry, keep int, maxAge time.Duration, now time.Time) ([]CacheEntry, error) {
+	if keep <= 0 && maxAge <= 0 {
+		return nil, fmt.Errorf("prune needs a number of entries to keep or a maximum age")
+	}
+
+	// entries may come in any order; rank them newest first within each repository.
+	sorted := append([]CacheEntry(nil), entries...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ModTime.After(sorted[j].ModTime)
+	})
+
+	seenPerRepo := make(map[string]int)
+	var removed []CacheEntry
+	for _, entry := range sorted {
+		seenPerRepo[entry.Repo]++
+		if keep > 0 && seenPerRepo[entry.Repo] <= keep {
+			continue
+		}
+		if maxAge > 0 && now.Sub(entry.ModTime) < maxAge {
+			continue
+		}
+		if err := os.RemoveAll(entry.Path); err != nil {
+			return removed, fmt.Errorf("failed to remove cache %s: %w", entry.Path, err)
+		}
+		removed = append(removed, entry)
+	}
+	return removed, nil
+}
+
+// DirSize returns the total size of the regular files below dir.
+func DirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(_ string, d os.DirEntry, err error) error {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, errInfo := d.Info()
+			if errInfo == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size, err
+}
+
+// writeFileAtomic writes content to a temporary file next to path and renames it into
+// place, so concurrent readers see either the old or the new content, never a partial file.
+func writeFileAtomic(path string, content []byte) error {
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name()) // No-op once renamed into place
+
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
@@ -0,0 +1,121 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/git-inquisitor-go/internal/models"
+	"github.com/user/git-inquisitor-go/internal/store"
+)
+
+// writeCacheFile creates a fake legacy cache file for commit in repoDir with the given modification time.
+func writeCacheFile(t *testing.T, repoDir, commit string, modTime time.Time) {
+	t.Helper()
+	if err := os.MkdirAll(repoDir, 0755); err != nil {
+		t.Fatalf("Failed to create cache dir: %v", err)
+	}
+	path := filepath.Join(repoDir, commit+store.LegacySuffix)
+	if err := os.WriteFile(path, []byte("data"), 0600); err != nil {
+		t.Fatalf("Failed to write cache file: %v", err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("Failed to set cache file time: %v", err)
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	a := cacheKey("https://example.com/org/repo.git")
+	b := cacheKey("/home/user/src/repo")
+	if !strings.HasPrefix(a, "repo-") || !strings.HasPrefix(b, "repo-") {
+		t.Errorf("cacheKey() = %s, %s, want names prefixed with 'repo-'", a, b)
+	}
+	if a == b {
+		t.Errorf("cacheKey() returned %s for different sources", a)
+	}
+	if cacheKey("/home/user/src/repo") != b {
+		t.Errorf("cacheKey() is not stable")
+	}
+}
+
+func TestListAndPruneCache(t *testing.T) {
+	cacheDir := t.TempDir()
+	now := time.Now()
+	alpha := RepoCacheDir(cacheDir, "/src/alpha")
+	beta := RepoCacheDir(cacheDir, "/src/beta")
+	writeCacheFile(t, alpha, "a1", now.Add(-72*time.Hour))
+	writeCacheFile(t, alpha, "a2", now.Add(-48*time.Hour))
+	writeCacheFile(t, alpha, "a3", now.Add(-1*time.Hour))
+	writeCacheFile(t, beta, "b1", now.Add(-96*time.Hour))
+	// Stores are listed alongside legacy cache files.
+	storeDir := filepath.Join(beta, "b2")
+	if err := store.Write(storeDir, &models.CollectedData{}); err != nil {
+		t.Fatalf("store.Write() error = %v", err)
+	}
+	if err := os.Chtimes(storeDir, now.Add(-120*time.Hour), now.Add(-120*time.Hour)); err != nil {
+		t.Fatalf("Failed to set store time: %v", err)
+	}
+
+	entries, err := ListCache(cacheDir, "")
+	if err != nil {
+		t.Fatalf("ListCache() error = %v", err)
+	}
+	if len(entries) != 5 || entries[0].Commit != "a3" || entries[4].Commit != "b2" {
+		t.Fatalf("ListCache() = %+v, want 5 entries newest first", entries)
+	}
+
+	alphaEntries, err := ListCache(cacheDir, alpha)
+	if err != nil {
+		t.Fatalf("ListCache(repo) error = %v", err)
+	}
+	if len(alphaEntries) != 3 {
+		t.Errorf("ListCache(repo) = %d entries, want 3", len(alphaEntries))
+	}
+
+	if _, err := PruneCache(entries, 0, 0, now); err == nil {
+		t.Error("PruneCache() without criteria expected error, got nil")
+	}
+
+	// Keep the newest entry per repository, plus anything younger than two days.
+	removed, err := PruneCache(entries, 1, 50*time.Hour, now)
+	if err != nil {
+		t.Fatalf("PruneCache() error = %v", err)
+	}
+	if len(removed) != 2 || removed[0].Commit != "a1" || removed[1].Commit != "b2" {
+		t.Errorf("PruneCache() removed %+v, want a1 and b2", removed)
+	}
+
+	remaining, err := ListCache(cacheDir, "")
+	if err != nil {
+		t.Fatalf("ListCache() error = %v", err)
+	}
+	if len(remaining) != 3 {
+		t.Errorf("ListCache() after prune = %d entries, want 3", len(remaining))
+	}
+}
+
+func TestListLegacyCache(t *testing.T) {
+	repoPath := t.TempDir()
+	now := time.Now()
+	writeCacheFile(t, LegacyCacheDir(repoPath), "old1", now.Add(-48*time.Hour))
+	writeCacheFile(t, LegacyCacheDir(repoPath), "old2", now.Add(-1*time.Hour))
+
+	entries, err := ListLegacyCache(repoPath)
+	if err != nil {
+		t.Fatalf("ListLegacyCache() error = %v", err)
+	}
+	if len(entries) != 2 || entries[0].Commit != "old2" || entries[1].Commit != "old1" {
+		t.Fatalf("ListLegacyCache() = %+v, want old2 and old1", entries)
+	}
+	// Legacy entries are grouped with the repository's entries below the cache root.
+	current := RepoCacheDir(t.TempDir(), repoPath)
+	if entries[0].Repo != filepath.Base(current) || entries[0].Source != repoPath {
+		t.Errorf("ListLegacyCache() entry = %+v, want repo %s and source %s", entries[0], filepath.Base(current), repoPath)
+	}
+
+	if entries, err := ListLegacyCache(t.TempDir()); err != nil || len(entries) != 0 {
+		t.Errorf("ListLegacyCache() without legacy caches = %+v, %v, want none", entries, err)
+	}
+}
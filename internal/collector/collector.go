@@ -1,34 +1,180 @@
 package collector
 
 import (
-	"archive/zip"
-	"bytes"
-	"encoding/gob"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/user"
 	"path/filepath"
+	"reflect"
 	"runtime"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/user/git-inquisitor-go/internal/models"
+	"github.com/user/git-inquisitor-go/internal/progress"
+	"github.com/user/git-inquisitor-go/internal/store"
 	"github.com/user/git-inquisitor-go/pkg/gitutil"
-	// TODO: Add a progress bar library if desired, like tqdm in Python.
-	// For now, simple print statements or nothing for progress.
 )
 
-const InquisitorVersion = "0.1.0-go" // Or dynamically set during build
+const InquisitorVersion = "0.6.0-go" // Or dynamically set during build
+
+// ErrStaleCache is returned by LoadCache when the cache was written by another
+// inquisitor version or with different collection options.
+var ErrStaleCache = errors.New("cache is stale")
+
+// Options controls optional parts of the collection process.
+// Fields that change the collected data take part in Hash; the others are tagged `json:"-"`.
+type Options struct {
+	// TrendSampling selects the historical commits sampled for trend collection.
+	// An empty value disables trends. See parseTrendSampling for accepted values.
+	TrendSampling string `json:"trend_sampling,omitempty"`
+	// FastStats computes commit stats with gitutil.GetCommitStatsFast instead of full patches.
+	FastStats bool `json:"fast_stats,omitempty"`
+	// Backend selects how commits are diffed and files blamed: gitutil.BackendGoGit (the
+	// default when empty) or gitutil.BackendCLI.
+	Backend string `json:"backend,omitempty"`
+	// IgnoreRevs are commits (any revision) whose changes blame attributes to the previous
+	// author of each line, in addition to those of the repository's ignore-revs files
+	// (see gitutil.LoadIgnoreRevs).
+	IgnoreRevs []string `json:"ignore_revs,omitempty"`
+	// SkipUnknownIgnoreRevs skips, with a warning, the IgnoreRevs that do not resolve in
+	// the repository instead of failing, for revisions shared by several repositories.
+	SkipUnknownIgnoreRevs bool `json:"-"`
+	// IgnoreRevsFile names an ignore-revs file, such as gitutil.DefaultIgnoreRevsFile,
+	// whose commits blame ignores too. Without it only the file named by the
+	// repository's blame.ignoreRevsFile setting is read.
+	IgnoreRevsFile string `json:"ignore_revs_file,omitempty"`
+	// ExcludeIgnoredRevs also leaves the insertions and deletions of the ignored commits
+	// out of the contributors' totals. The commits themselves are still counted.
+	ExcludeIgnoredRevs bool `json:"exclude_ignored_revs,omitempty"`
+	// BlameIgnoreWhitespace, BlameDetectMoves and BlameDetectCopies attribute lines that
+	// were only reindented, moved within a file or moved or copied from another file to
+	// their previous author, like `git blame -w`, `-M` and `-C`.
+	BlameIgnoreWhitespace bool `json:"blame_ignore_whitespace,omitempty"`
+	BlameDetectMoves      bool `json:"blame_detect_moves,omitempty"`
+	BlameDetectCopies     bool `json:"blame_detect_copies,omitempty"`
+	// ReleaseTags is a glob pattern (see path.Match), such as "v*", selecting the tags
+	// that mark releases. Empty takes every tag.
+	ReleaseTags string `json:"release_tags,omitempty"`
+	// CacheDir is the root directory for collection caches and remote mirrors.
+	// When empty, DefaultCacheDir is used.
+	CacheDir string `json:"-"`
+	// NoCache disables reading and writing the cache.
+	NoCache bool `json:"-"`
+	// Refresh ignores an existing cache but writes the fresh results to it.
+	Refresh bool `json:"-"`
+	// BlameTimeout limits the time spent blaming a single file; files exceeding it are
+	// skipped and recorded in the diagnostics. Zero means no limit.
+	BlameTimeout time.Duration `json:"-"`
+	// Jobs is the number of workers computing commit diffs and blames.
+	// Zero or less uses one worker per CPU.
+	Jobs int `json:"-"`
+	// MaxMemory is a soft cap, in bytes, on the memory used by collection. When set, the
+	// history is spilled to disk once it outgrows its share (see appendHistory).
+	// Zero means no cap.
+	MaxMemory int64 `json:"-"`
+	// ReuseCachedOptions adopts the options stored with an existing cache for the current
+	// HEAD commit instead of requiring them to match, and keeps them for a re-collection.
+	// Commands that only read the collected data set it when no collection flags are given,
+	// so they reuse whatever collect produced (such as a trend) instead of discarding it.
+	ReuseCachedOptions bool `json:"-"`
+}
+
+// blameOptions returns the blame options selected by o, ignoring ignoreRevs.
+func (o Options) blameOptions(ignoreRevs []plumbing.Hash) gitutil.BlameOptions {
+	return gitutil.BlameOptions{
+		IgnoreRevs:       ignoreRevs,
+		IgnoreWhitespace: o.BlameIgnoreWhitespace,
+		DetectMoves:      o.BlameDetectMoves,
+		DetectCopies:     o.BlameDetectCopies,
+	}
+}
+
+// workers returns the size of the worker pools for n items of work.
+func (o Options) workers(n int) int {
+	workers := o.Jobs
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if n < workers {
+		workers = n // Don't start more workers than items
+	}
+	return workers
+}
+
+// Hash returns a short digest of the options that affect the collected data.
+// It is stored in the cache so caches collected with different options are not reused.
+// The default backend hashes the same whether it is selected by name or left empty.
+func (o Options) Hash() string {
+	if o.Backend == gitutil.BackendGoGit {
+		o.Backend = ""
+	}
+	encoded, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// adopt returns o with the options that take part in Hash replaced by those encoded in
+// stored; the fields tagged `json:"-"` are kept.
+func (o Options) adopt(stored json.RawMessage) (Options, error) {
+	value := reflect.ValueOf(&o).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		if value.Type().Field(i).Tag.Get("json") != "-" {
+			value.Field(i).SetZero()
+		}
+	}
+	if err := json.Unmarshal(stored, &o); err != nil {
+		return o, fmt.Errorf("failed to decode cached collection options: %w", err)
+	}
+	return o, nil
+}
 
 // GitDataCollector handles the collection and processing of Git repository data.
 type GitDataCollector struct {
 	RepoPath string
-	repo     *git.Repository
-	head     *object.Commit
-	Data     models.CollectedData
+	Options  Options
+	// Progress receives progress and messages; when nil, plain log lines go to stdout.
+	Progress progress.Reporter
+	// Logger receives diagnostics such as skipped files; when nil, they go to Progress.
+	Logger *slog.Logger
+	repo   *git.Repository
+	head   *object.Commit
+	Data   models.CollectedData
+	// source identifies the repository for cache naming: the URL for remote
+	// repositories, RepoPath otherwise.
+	source string
+	// tempDir is a temporary clone removed by Close, if any.
+	tempDir string
+	// backend diffs and blames; see gitBackend.
+	backend gitutil.Backend
+	// ignoredRevs are the commits ignored by blame, resolved with the backend;
+	// unresolvedRevs are the entries of the ignore-revs files that did not resolve.
+	ignoredRevs    map[string]bool
+	unresolvedRevs []string
+	// activeDays collects the days each contributor committed on, see recordActivity.
+	activeDays map[string]map[int64]bool
+	// releases, releaseOf and unreleased accumulate the release summaries, see resolveReleases.
+	releases   []*releaseSummary
+	releaseOf  map[plumbing.Hash]int
+	unreleased *releaseSummary
+	// historySpool holds the history once it has been spilled to disk; historyBytes
+	// estimates the size of the in-memory history until then.
+	historySpool *store.HistorySpool
+	historyBytes int64
 }
 
 // NewGitDataCollector creates and initializes a new GitDataCollector.
@@ -48,147 +194,386 @@ func NewGitDataCollector(repoPath string) (*GitDataCollector, error) {
 		return nil, err
 	}
 
+	return newGitDataCollector(absRepoPath, repo, head), nil
+}
+
+// newGitDataCollector builds a collector for an already opened repository.
+func newGitDataCollector(repoPath string, repo *git.Repository, head *object.Commit) *GitDataCollector {
 	return &GitDataCollector{
-		RepoPath: absRepoPath,
+		RepoPath: repoPath,
 		repo:     repo,
 		head:     head,
+		source:   repoPath,
 		Data: models.CollectedData{
 			Contributors: make(map[string]models.Contributor),
 			Files:        make(map[string]models.FileData),
 			History:      []models.CommitHistoryItem{},
 		},
-	}, nil
+	}
+}
+
+// gitBackend returns the backend selected by Options.Backend, creating it on first use.
+func (gdc *GitDataCollector) gitBackend() (gitutil.Backend, error) {
+	if gdc.backend == nil {
+		extra := gdc.Options.IgnoreRevs
+		var unknown []string
+		if gdc.Options.SkipUnknownIgnoreRevs {
+			extra = nil
+			for _, rev := range gdc.Options.IgnoreRevs {
+				if _, err := gdc.repo.ResolveRevision(plumbing.Revision(rev)); err != nil {
+					unknown = append(unknown, fmt.Sprintf("%s: %v", rev, err))
+					continue
+				}
+				extra = append(extra, rev)
+			}
+		}
+		ignoreRevs, unresolved, err := gitutil.LoadIgnoreRevs(gdc.repo, gdc.head, gdc.Options.IgnoreRevsFile, extra)
+		if err != nil {
+			return nil, err
+		}
+		gdc.unresolvedRevs = append(unresolved, unknown...)
+		backend, err := gitutil.NewBackend(gdc.Options.Backend, gdc.repo, gdc.RepoPath, gdc.head.Hash, gitutil.BackendOptions{
+			FastStats: gdc.Options.FastStats,
+			Blame:     gdc.Options.blameOptions(ignoreRevs),
+		})
+		if err != nil {
+			return nil, err
+		}
+		gdc.backend = backend
+		gdc.ignoredRevs = make(map[string]bool, len(ignoreRevs))
+		for _, hash := range ignoreRevs {
+			gdc.ignoredRevs[hash.String()] = true
+		}
+	}
+	return gdc.backend, nil
 }
 
-// cachePath returns the path to the cache file for the current HEAD commit.
+// ignoredRevList returns the commits ignored by blame, sorted.
+func (gdc *GitDataCollector) ignoredRevList() []string {
+	if len(gdc.ignoredRevs) == 0 {
+		return nil
+	}
+	revs := make([]string, 0, len(gdc.ignoredRevs))
+	for hash := range gdc.ignoredRevs {
+		revs = append(revs, hash)
+	}
+	sort.Strings(revs)
+	return revs
+}
+
+// reporter returns the progress reporter, installing the default one if none is set.
+func (gdc *GitDataCollector) reporter() progress.Reporter {
+	if gdc.Progress == nil {
+		gdc.Progress = progress.NewLog(os.Stdout, progress.Normal)
+	}
+	return gdc.Progress
+}
+
+// cacheDirectory returns the directory holding this repository's cache files.
+func (gdc *GitDataCollector) cacheDirectory() string {
+	cacheDir := gdc.Options.CacheDir
+	if cacheDir == "" {
+		cacheDir = DefaultCacheDir()
+	}
+	return RepoCacheDir(cacheDir, gdc.cacheSource())
+}
+
+// cacheSource returns the path or URL identifying the repository in the cache.
+func (gdc *GitDataCollector) cacheSource() string {
+	if gdc.source == "" {
+		return gdc.RepoPath
+	}
+	return gdc.source
+}
+
+// cachePath returns the path to the cache store for the current HEAD commit.
 func (gdc *GitDataCollector) cachePath() string {
-	// Ensure .inquisitor/cache directory exists in the repo path, not current working dir
-	cacheDir := filepath.Join(gdc.RepoPath, ".inquisitor", "cache")
-	return filepath.Join(cacheDir, gdc.head.Hash.String()+".zip.gob")
+	return filepath.Join(gdc.cacheDirectory(), gdc.head.Hash.String())
+}
+
+// legacyCachePaths returns the paths a gob-in-zip cache for the current HEAD commit had:
+// next to the store, or inside the repository (see LegacyCacheDir) before caches moved
+// below the cache root.
+func (gdc *GitDataCollector) legacyCachePaths() []string {
+	return []string{
+		gdc.cachePath() + store.LegacySuffix,
+		filepath.Join(LegacyCacheDir(gdc.RepoPath), gdc.head.Hash.String()+store.LegacySuffix),
+	}
 }
 
-// CacheExists checks if a cache file exists for the current HEAD commit.
+// legacyCachePath returns the first of legacyCachePaths that exists, or "".
+func (gdc *GitDataCollector) legacyCachePath() string {
+	for _, path := range gdc.legacyCachePaths() {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// CacheExists checks if a cache exists for the current HEAD commit, in either format.
 func (gdc *GitDataCollector) CacheExists() bool {
-	_, err := os.Stat(gdc.cachePath())
-	return !os.IsNotExist(err)
+	return store.Exists(gdc.cachePath()) || gdc.legacyCachePath() != ""
 }
 
-// SaveCache saves the collected data to a gob-encoded, zip-compressed file.
+// SaveCache saves the collected data to the cache store for the current HEAD commit.
 func (gdc *GitDataCollector) SaveCache() error {
-	cacheFile := gdc.cachePath()
-	if err := os.MkdirAll(filepath.Dir(cacheFile), 0755); err != nil {
-		return fmt.Errorf("failed to create cache directory %s: %w", filepath.Dir(cacheFile), err)
+	cacheDir := gdc.cachePath()
+	if err := os.MkdirAll(filepath.Dir(cacheDir), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory %s: %w", filepath.Dir(cacheDir), err)
 	}
-
-	var buf bytes.Buffer
-	gobEncoder := gob.NewEncoder(&buf)
-	if err := gobEncoder.Encode(gdc.Data); err != nil {
-		return fmt.Errorf("failed to gob-encode data: %w", err)
+	// Record which repository the cache directory belongs to, for `cache list`.
+	sourceFile := filepath.Join(filepath.Dir(cacheDir), cacheSourceFile)
+	if err := writeFileAtomic(sourceFile, []byte(gdc.cacheSource()+"\n")); err != nil {
+		return fmt.Errorf("failed to write cache source file %s: %w", sourceFile, err)
 	}
 
-	zipFile, err := os.Create(cacheFile)
+	var err error
+	if gdc.historySpool != nil {
+		err = store.WriteWithHistory(cacheDir, &gdc.Data, gdc.historySpool)
+	} else {
+		err = store.Write(cacheDir, &gdc.Data)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to create zip cache file %s: %w", cacheFile, err)
+		return err
 	}
-	defer zipFile.Close()
+	gdc.reporter().Infof("Data cached successfully to %s", cacheDir)
+	return nil
+}
 
-	zipWriter := zip.NewWriter(zipFile)
-	dataWriter, err := zipWriter.Create("data.gob")
-	if err != nil {
-		return fmt.Errorf("failed to create data.gob entry in zip: %w", err)
+// LoadCache loads collected data from the cache store, migrating a legacy gob-in-zip
+// cache file to the store first if that is all there is.
+// It returns an error wrapping ErrStaleCache when the cache was collected by a different
+// InquisitorVersion or with different options; the loaded data must not be used then.
+func (gdc *GitDataCollector) LoadCache() error {
+	cacheDir := gdc.cachePath()
+	var data *models.CollectedData
+	var err error
+	if !store.Exists(cacheDir) {
+		if legacyPath := gdc.legacyCachePath(); legacyPath != "" {
+			gdc.reporter().Infof("Migrating legacy cache %s", legacyPath)
+			data, err = store.MigrateLegacy(legacyPath, cacheDir, upgradeLegacy)
+		}
+	}
+	if data == nil && err == nil {
+		data, err = store.Read(cacheDir)
 	}
-	_, err = dataWriter.Write(buf.Bytes())
 	if err != nil {
-		return fmt.Errorf("failed to write gob data to zip entry: %w", err)
+		return fmt.Errorf("failed to load cache %s: %w", cacheDir, err)
 	}
+	gdc.Data = *data
 
-	if err := zipWriter.Close(); err != nil {
-		return fmt.Errorf("failed to close zip writer: %w", err)
+	collectorMetadata := gdc.Data.Metadata.Collector
+	if gdc.Options.ReuseCachedOptions && len(collectorMetadata.Options) > 0 {
+		if gdc.Options, err = gdc.Options.adopt(collectorMetadata.Options); err != nil {
+			return err
+		}
+	}
+	if collectorMetadata.InquisitorVersion != InquisitorVersion {
+		return fmt.Errorf("%w: collected by version %q, expected %q", ErrStaleCache, collectorMetadata.InquisitorVersion, InquisitorVersion)
+	}
+	if optionsHash := gdc.Options.Hash(); collectorMetadata.OptionsHash != optionsHash {
+		return fmt.Errorf("%w: collected with options %q, expected %q", ErrStaleCache, collectorMetadata.OptionsHash, optionsHash)
 	}
-	fmt.Printf("Data cached successfully to %s\n", cacheFile)
+	if changed, err := gdc.tagsChanged(); err != nil {
+		return fmt.Errorf("failed to compare tags with the cache: %w", err)
+	} else if changed {
+		return fmt.Errorf("%w: tags changed since collection", ErrStaleCache)
+	}
+	gdc.reporter().Infof("Data loaded successfully from %s", cacheDir)
 	return nil
 }
 
-// LoadCache loads collected data from a gob-encoded, zip-compressed file.
-func (gdc *GitDataCollector) LoadCache() error {
-	cacheFile := gdc.cachePath()
-	zipReader, err := zip.OpenReader(cacheFile)
+// Collect gathers all data from the git repository.
+// It checks for a cache first, and if not found, collects and then saves to cache.
+// Collection holds a lock file next to the cache, so concurrent invocations for the
+// same HEAD commit wait for each other and share the cached result instead of racing.
+// When ctx is cancelled, collection stops as soon as possible and ctx's error is
+// returned; nothing is cached then.
+func (gdc *GitDataCollector) Collect(ctx context.Context) error {
+	switch {
+	case gdc.Options.NoCache:
+		gdc.reporter().Infof("Cache disabled.")
+		return gdc.collect(ctx)
+	case gdc.Options.Refresh:
+		gdc.reporter().Infof("Refresh requested. Ignoring any existing cache.")
+	case gdc.loadValidCache():
+		return gdc.refreshBranches(ctx)
+	}
+
+	if err := os.MkdirAll(gdc.cacheDirectory(), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory %s: %w", gdc.cacheDirectory(), err)
+	}
+	waited := false
+	lock, err := acquireCacheLock(ctx, gdc.cachePath()+".lock", gdc.logger(), func() {
+		waited = true
+		gdc.reporter().Infof("Another collection of this commit is in progress. Waiting for it to finish...")
+	})
 	if err != nil {
-		return fmt.Errorf("failed to open zip cache file %s: %w", cacheFile, err)
+		return err
 	}
-	defer zipReader.Close()
+	defer lock.release()
 
-	if len(zipReader.File) == 0 || zipReader.File[0].Name != "data.gob" {
-		return fmt.Errorf("invalid cache file format: data.gob not found")
+	// Whoever held the lock has most likely cached the result we need.
+	if waited && gdc.loadValidCache() {
+		return gdc.refreshBranches(ctx)
 	}
 
-	dataFile, err := zipReader.File[0].Open()
-	if err != nil {
-		return fmt.Errorf("failed to open data.gob from zip: %w", err)
+	if err := gdc.collect(ctx); err != nil {
+		return err
 	}
-	defer dataFile.Close()
-
-	gobDecoder := gob.NewDecoder(dataFile)
-	if err := gobDecoder.Decode(&gdc.Data); err != nil {
-		return fmt.Errorf("failed to gob-decode data: %w", err)
+	if err := gdc.SaveCache(); err != nil {
+		return fmt.Errorf("failed to save data to cache: %w", err)
 	}
-	fmt.Printf("Data loaded successfully from %s\n", cacheFile)
-	return nil
+	return lock.release()
 }
 
-// Collect gathers all data from the git repository.
-// It checks for a cache first, and if not found, collects and then saves to cache.
-func (gdc *GitDataCollector) Collect() error {
-	if gdc.CacheExists() {
-		fmt.Println("Cache found. Loading data from cache.")
-		if err := gdc.LoadCache(); err == nil {
-			// Verify essential fields from loaded cache to ensure it's not corrupted/empty.
-			if gdc.Data.Metadata.Repo.Commit.SHA == "" || gdc.Data.Metadata.Collector.DateCollected.IsZero() {
-				fmt.Println("Cache seems incomplete or corrupted. Re-collecting.")
-			} else {
-				return nil // Successfully loaded from cache
-			}
-		} else {
-			fmt.Printf("Failed to load cache: %v. Re-collecting.\n", err)
-		}
+// upgradeLegacy fills in the options hash of data migrated from a legacy cache written
+// before the collection options existed, which was collected with what are now the
+// default options. The version is kept, so the data is still only used by the version
+// that collected it.
+func upgradeLegacy(data *models.CollectedData) {
+	if metadata := &data.Metadata.Collector; metadata.OptionsHash == "" {
+		metadata.OptionsHash = Options{}.Hash()
 	}
+}
 
-	fmt.Println("No valid cache found or cache load failed. Collecting data from repository...")
-	if err := gdc.collectMetadata(); err != nil {
-		return fmt.Errorf("failed to collect metadata: %w", err)
+// loadValidCache loads the cache for the current HEAD commit and reports whether
+// it exists, matches the current version and options, and looks complete.
+func (gdc *GitDataCollector) loadValidCache() bool {
+	if !gdc.CacheExists() {
+		return false
 	}
+	gdc.reporter().Infof("Cache found. Loading data from cache.")
+	if err := gdc.LoadCache(); err != nil {
+		gdc.reporter().Infof("Failed to load cache: %v. Re-collecting.", err)
+		return false
+	}
+	// Verify essential fields from loaded cache to ensure it's not corrupted/empty.
+	if gdc.Data.Metadata.Repo.Commit.SHA == "" || gdc.Data.Metadata.Collector.DateCollected.IsZero() {
+		gdc.reporter().Infof("Cache seems incomplete or corrupted. Re-collecting.")
+		return false
+	}
+	return true
+}
 
-	// Print progress (simple version)
-	fmt.Println("Processing commits...")
-	commits, err := gitutil.IterateCommits(gdc.repo, gdc.head)
+// collect gathers all data from the repository into gdc.Data without touching the cache.
+func (gdc *GitDataCollector) collect(ctx context.Context) error {
+	reporter := gdc.reporter()
+	reporter.Infof("No valid cache found or cache load failed. Collecting data from repository...")
+	commits, err := gdc.collectHistory(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to iterate commits: %w", err)
+		return err
 	}
 
-	for _, commit := range commits {
-		if err := gdc.collectCommitData(commit); err != nil {
-			// Log error but continue processing other commits
-			fmt.Printf("Warning: failed to process commit %s: %v\n", commit.Hash.String(), err)
-		}
+	if err := gdc.collectBranches(ctx); err != nil {
+		return fmt.Errorf("failed to collect branches: %w", err)
 	}
 
-	fmt.Println("Processing file blames...")
-	if err := gdc.collectBlameDataByFile(); err != nil {
+	if err := gdc.collectBlameDataByFile(ctx); err != nil {
 		return fmt.Errorf("failed to collect blame data: %w", err)
 	}
 
-	fmt.Println("Aggregating contributor line counts...")
+	reporter.Debugf("Aggregating contributor line counts...")
 	gdc.collectActiveLineCountByContributor()
 
-	fmt.Println("Data collection complete.")
-	if err := gdc.SaveCache(); err != nil {
-		return fmt.Errorf("failed to save data to cache: %w", err)
+	if gdc.Options.TrendSampling != "" {
+		if err := gdc.collectTrend(ctx, commits); err != nil {
+			return fmt.Errorf("failed to collect trend data: %w", err)
+		}
 	}
 
+	reporter.Infof("Data collection complete.")
 	return nil
 }
 
-func (gdc *GitDataCollector) collectMetadata() error {
+// CollectHistory gathers the metadata, history and releases of the repository, for
+// commands that need no more, such as the changelog. A valid cache is loaded if there
+// is one (see Collect); otherwise only those parts are collected, skipping blame, branches
+// and trends, and the result is not cached since it is incomplete.
+func (gdc *GitDataCollector) CollectHistory(ctx context.Context) error {
+	if !gdc.Options.NoCache && !gdc.Options.Refresh && gdc.loadValidCache() {
+		return nil
+	}
+	gdc.reporter().Infof("Collecting the history from the repository, without blame. It is not cached.")
+	if _, err := gdc.collectHistory(ctx); err != nil {
+		return err
+	}
+	gdc.reporter().Infof("History collection complete.")
+	return nil
+}
+
+// collectHistory replaces gdc.Data with the metadata, history, contributor activity and
+// releases of the repository, the parts of the collection that need no blame, and returns
+// the commits of the history.
+func (gdc *GitDataCollector) collectHistory(ctx context.Context) ([]gitutil.CommitRef, error) {
+	reporter := gdc.reporter()
+	gdc.resetData()
+	backend, err := gdc.gitBackend()
+	if err != nil {
+		return nil, err
+	}
+	reporter.Debugf("Using the %s backend", backend.Name())
+	for _, rev := range gdc.unresolvedRevs {
+		gdc.warn("ignored revision not found, blame does not skip it", errors.New(rev))
+	}
+	if err := gdc.collectMetadata(ctx); err != nil {
+		return nil, fmt.Errorf("failed to collect metadata: %w", err)
+	}
+
+	reporter.StartPhase("Listing commits", 0)
+	commits, err := gitutil.ListCommits(ctx, gdc.repo, gdc.head)
+	reporter.EndPhase()
+	if err != nil {
+		return nil, fmt.Errorf("failed to iterate commits: %w", err)
+	}
+
+	if err := gdc.resolveReleases(ctx, commits); err != nil {
+		return nil, err
+	}
+
+	reporter.StartPhase("Processing commits", len(commits))
+	var historyErr error
+	gdc.commitStats(ctx, commits, func(result commitStatsResult) {
+		hash := commits[result.Index].Hash.String()
+		reporter.Increment(hash)
+		if ctx.Err() != nil || historyErr != nil {
+			return // Cancelled: the remaining results are not failures of their own
+		}
+		if result.Err != nil {
+			// Record the error but continue processing other commits
+			gdc.skipCommit(hash, phaseCommits, fmt.Errorf("failed to get stats for commit %s: %w", hash, result.Err))
+			return
+		}
+		historyErr = gdc.collectCommitData(result.Commit, result)
+	})
+	reporter.EndPhase()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if historyErr != nil {
+		return nil, historyErr
+	}
+	gdc.finishActivity()
+	gdc.finishReleases()
+	return commits, nil
+}
+
+// resetData discards anything left over from a partially loaded cache or an earlier collection.
+func (gdc *GitDataCollector) resetData() {
+	if err := gdc.removeHistorySpool(); err != nil {
+		gdc.warn("could not remove spilled history", err)
+	}
+	gdc.Data = models.CollectedData{
+		Contributors: make(map[string]models.Contributor),
+		Files:        make(map[string]models.FileData),
+		History:      []models.CommitHistoryItem{},
+	}
+	gdc.activeDays = nil
+	gdc.releases, gdc.releaseOf, gdc.unreleased = nil, nil, nil
+}
+
+func (gdc *GitDataCollector) collectMetadata(ctx context.Context) error {
 	currentUser, err := user.Current()
 	userName := "unknown"
 	if err == nil {
@@ -196,17 +581,30 @@ func (gdc *GitDataCollector) collectMetadata() error {
 	}
 
 	hostname, _ := os.Hostname()
-	gitVersion, _ := gitutil.GetGitVersion() // Pure Go, so not system git version
+	options, err := json.Marshal(gdc.Options)
+	if err != nil {
+		return fmt.Errorf("failed to encode collection options: %w", err)
+	}
+	gitVersion, err := gdc.backend.Version(ctx)
+	if err != nil {
+		gdc.warn("could not get git version", err)
+		gitVersion = "unknown"
+	}
+
+	tags, err := gdc.tagRefs()
+	if err != nil {
+		gdc.warn("could not list tags", err)
+	}
 
 	remoteURL, err := gitutil.GetRepoRemoteURL(gdc.repo)
 	if err != nil {
-		fmt.Printf("Warning: could not get remote URL: %v\n", err)
+		gdc.warn("could not get remote URL", err)
 		remoteURL = "unknown"
 	}
 
 	branchName, err := gitutil.GetRepoBranch(gdc.repo, gdc.head)
 	if err != nil {
-		fmt.Printf("Warning: could not get branch name: %v\n", err)
+		gdc.warn("could not get branch name", err)
 		// Use HEAD SHA if branch detection failed
 		branchName = gdc.head.Hash.String() + " (error determining branch)"
 	}
@@ -214,23 +612,109 @@ func (gdc *GitDataCollector) collectMetadata() error {
 	gdc.Data.Metadata = models.Metadata{
 		Collector: models.CollectorMetadata{
 			InquisitorVersion: InquisitorVersion,
+			OptionsHash:       gdc.Options.Hash(),
+			Options:           options,
 			DateCollected:     time.Now().UTC(),
 			User:              userName,
 			Hostname:          hostname,
 			Platform:          fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
 			GoVersion:         runtime.Version(),
 			GitVersion:        gitVersion,
+			IgnoredRevs:       gdc.ignoredRevList(),
+			BlameFlags:        gdc.Options.blameOptions(nil).Flags(),
 		},
 		Repo: models.RepoMetadata{
 			URL:    remoteURL,
 			Branch: branchName,
 			Commit: gitutil.GetCommitDetails(gdc.head),
+			Tags:   tags,
 		},
 	}
 	return nil
 }
 
-func (gdc *GitDataCollector) collectCommitData(commit *object.Commit) error {
+// commitWindowPerWorker bounds, per worker, how many commits may be read but not yet
+// handled, so a slow commit holds back a bounded number of results.
+const commitWindowPerWorker = 16
+
+// commitStatsResult is the diff summary of a single commit, computed in a worker.
+type commitStatsResult struct {
+	Index        int            // Position of the commit in the commits passed to commitStats
+	Commit       *object.Commit // The commit, read from the repository by the worker
+	Insertions   int
+	Deletions    int
+	FilesChanged map[string]models.FileCommitStats
+	Err          error
+}
+
+// commitStats reads commits and diffs them against their parents on a pool of workers.
+// handle is called sequentially, from the calling goroutine, once per commit and in the
+// order of commits, so the collected history does not depend on scheduling. Commits are
+// read as they are dispatched, so only a window of them is in memory at a time.
+// Once ctx is cancelled the remaining commits are not diffed; their results carry ctx's error.
+func (gdc *GitDataCollector) commitStats(ctx context.Context, commits []gitutil.CommitRef, handle func(commitStatsResult)) {
+	numCommits := len(commits)
+	if numCommits == 0 {
+		return
+	}
+	numWorkers := gdc.Options.workers(numCommits)
+
+	window := make(chan struct{}, numWorkers*commitWindowPerWorker)
+	jobs := make(chan int, numWorkers)
+	results := make(chan commitStatsResult, cap(window))
+
+	var wg sync.WaitGroup
+	for w := 0; w < numWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				result := commitStatsResult{Index: i}
+				if result.Err = ctx.Err(); result.Err == nil {
+					result.Commit, result.Err = gdc.repo.CommitObject(commits[i].Hash)
+				}
+				if result.Err == nil {
+					result.Insertions, result.Deletions, result.FilesChanged, result.Err = gdc.backend.CommitStats(ctx, result.Commit)
+				}
+				results <- result
+			}
+		}()
+	}
+
+	// Commits are dispatched in order, so the next one to handle is always in flight.
+	go func() {
+		for i := range commits {
+			window <- struct{}{}
+			jobs <- i
+		}
+		close(jobs)
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Results arrive in completion order; hold early ones back until their predecessors are handled.
+	pending := make(map[int]commitStatsResult)
+	next := 0
+	for result := range results {
+		pending[result.Index] = result
+		for {
+			ready, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			handle(ready)
+			<-window
+			next++
+		}
+	}
+}
+
+// collectCommitData adds a commit and its diff summary to the contributor stats and the history.
+func (gdc *GitDataCollector) collectCommitData(commit *object.Commit, stats commitStatsResult) error {
 	// 1. Collect data for contributor stats
 	committerName := strings.TrimSpace(strings.Split(commit.Committer.Name, "<")[0])
 	committerEmail := commit.Committer.Email
@@ -258,14 +742,14 @@ func (gdc *GitDataCollector) collectCommitData(commit *object.Commit) error {
 	}
 
 	contribData.CommitCount++
+	gdc.recordActivity(committerName, &contribData, commit.Committer.When)
+	contribData.CommitTimes.Add(commit.Author.When)
+	gdc.Data.CommitTimes.Add(commit.Author.When)
 
-	// Get stats for this commit
-	insertions, deletions, filesChangedMap, err := gitutil.GetCommitStats(commit)
-	if err != nil {
-		return fmt.Errorf("failed to get stats for commit %s: %w", commit.Hash.String(), err)
+	if !gdc.Options.ExcludeIgnoredRevs || !gdc.ignoredRevs[commit.Hash.String()] {
+		contribData.Insertions += stats.Insertions
+		contribData.Deletions += stats.Deletions
 	}
-	contribData.Insertions += insertions
-	contribData.Deletions += deletions
 	gdc.Data.Contributors[committerName] = contribData // Put the modified copy back
 
 	// 2. Collect data for history log
@@ -282,60 +766,86 @@ func (gdc *GitDataCollector) collectCommitData(commit *object.Commit) error {
 		Parents:      parentSHAs,
 		Tree:         commit.TreeHash.String(),
 		Contributor:  fmt.Sprintf("%s (%s)", commit.Committer.Name, commit.Committer.Email),
+		Author:       fmt.Sprintf("%s (%s)", commit.Author.Name, commit.Author.Email),
 		Date:         commit.Committer.When,
 		Message:      commit.Message, // Full message for history
-		Insertions:   insertions,
-		Deletions:    deletions,
-		FilesChanged: filesChangedMap,
+		Insertions:   stats.Insertions,
+		Deletions:    stats.Deletions,
+		FilesChanged: stats.FilesChanged,
+		Release:      gdc.recordRelease(commit, committerName, stats),
 	}
-	gdc.Data.History = append(gdc.Data.History, historyItem)
-	return nil
+	return gdc.appendHistory(historyItem)
 }
 
-func (gdc *GitDataCollector) collectBlameDataByFile() error {
+func (gdc *GitDataCollector) collectBlameDataByFile(ctx context.Context) error {
 	// Get list of files at HEAD
-	filePaths, err := gitutil.GetFilePaths(gdc.repo, gdc.head)
+	filePaths, err := gdc.backend.FilePaths(ctx, gdc.head)
 	if err != nil {
 		return fmt.Errorf("failed to list files at HEAD: %w", err)
 	}
 
+	reporter := gdc.reporter()
+	reporter.StartPhase("Processing file blames", len(filePaths))
+	defer reporter.EndPhase()
+	gdc.blameFiles(ctx, gdc.head, filePaths, func(result blameResult) {
+		reporter.Increment(result.Path)
+		if ctx.Err() != nil {
+			return // Cancelled: the remaining results are not failures of their own
+		}
+		if result.Err != nil {
+			gdc.skipFile(result.Path, phaseBlame, result.Err)
+			return
+		}
+		if result.Stats != nil && result.Stats.TotalLines > 0 {
+			gdc.Data.Files[result.Path] = models.FileData{
+				DateIntroduced:        result.Stats.DateIntroduced,
+				OriginalAuthor:        result.Stats.OriginalAuthor,
+				TotalCommits:          result.Stats.TotalCommits,
+				TotalLines:            result.Stats.TotalLines,
+				TopContributor:        result.Stats.TopContributor,
+				LinesByContributor:    result.Stats.LinesByContributor,
+				LineAges:              result.Stats.LineAges,
+				LineAgesByContributor: result.Stats.LineAgesByContributor,
+			}
+		}
+	})
+	return ctx.Err()
+}
+
+// blameResult is the outcome of blaming a single file in a worker.
+type blameResult struct {
+	Path  string
+	Stats *models.FileBlameStats
+	Err   error
+}
+
+// blameFiles blames filePaths at commit on a pool of workers.
+// handle is called sequentially, from the calling goroutine, once per file.
+// Once ctx is cancelled the remaining files are not blamed; their results carry ctx's error.
+func (gdc *GitDataCollector) blameFiles(ctx context.Context, commit *object.Commit, filePaths []string, handle func(blameResult)) {
 	numFiles := len(filePaths)
 	if numFiles == 0 {
-		return nil
+		return
 	}
 
 	// Worker pool setup
-	numWorkers := runtime.NumCPU()
-	if numFiles < numWorkers {
-		numWorkers = numFiles // Don't start more workers than files
-	}
+	numWorkers := gdc.Options.workers(numFiles)
 
 	jobs := make(chan string, numFiles)
-	results := make(chan struct {
-		Path  string
-		Stats *models.FileBlameStats
-		Err   error
-	}, numFiles)
+	results := make(chan blameResult, numFiles)
 
 	var wg sync.WaitGroup // Use sync.WaitGroup
 
 	// Start workers
 	for w := 0; w < numWorkers; w++ {
 		wg.Add(1)
-		go func(_ int) {
+		go func() {
 			defer wg.Done()
-			// fmt.Printf("Worker %d started\n", workerID)
 			for filePath := range jobs {
-				// fmt.Printf("Worker %d processing %s\n", workerID, filePath)
-				blameStats, errBlame := gitutil.GetBlameForFile(gdc.repo, gdc.head, filePath)
-				results <- struct {
-					Path  string
-					Stats *models.FileBlameStats
-					Err   error
-				}{Path: filePath, Stats: blameStats, Err: errBlame}
+				blameStats, errBlame := gdc.blameFile(ctx, commit, filePath)
+				results <- blameResult{Path: filePath, Stats: blameStats, Err: errBlame}
 			}
-			// fmt.Printf("Worker %d finished\n", workerID)
-		}(w)
+		}()
 	}
 
 	// Distribute jobs
@@ -344,41 +854,37 @@ func (gdc *GitDataCollector) collectBlameDataByFile() error {
 	}
 	close(jobs) // Signal workers that no more jobs will be sent
 
-	// Collect results
-	// It's important to wait for all workers to finish *before* closing the results channel.
-	// The easiest way to manage this is to know how many results to expect.
-
-	fmt.Println("Waiting for file blame processing to complete...")
-
 	// Wait for all workers to complete in a separate goroutine
 	// so that we don't block collecting results if a worker goroutine panics.
 	go func() {
 		wg.Wait()
 		close(results) // Now it's safe to close results channel
-		// fmt.Println("All workers done, results channel closed.")
 	}()
 
-	processedCount := 0
 	for result := range results {
-		processedCount++
-		fmt.Printf("Processed file %d/%d: %s\n", processedCount, numFiles, result.Path)
-		if result.Err != nil {
-			fmt.Printf("Warning: could not get blame for file %s: %v\n", result.Path, result.Err)
-			continue
-		}
-		if result.Stats != nil && result.Stats.TotalLines > 0 {
-			gdc.Data.Files[result.Path] = models.FileData{
-				DateIntroduced:     result.Stats.DateIntroduced,
-				OriginalAuthor:     result.Stats.OriginalAuthor,
-				TotalCommits:       result.Stats.TotalCommits,
-				TotalLines:         result.Stats.TotalLines,
-				TopContributor:     result.Stats.TopContributor,
-				LinesByContributor: result.Stats.LinesByContributor,
-			}
-		}
+		handle(result)
 	}
-	// fmt.Println("Finished collecting all blame results.")
-	return nil
+}
+
+// blameFile blames a single file with the backend, enforcing Options.BlameTimeout.
+// A blame exceeding the timeout is abandoned (see gitutil.GetBlameForFile) or killed
+// (with the CLI backend) and reported as an error of its own, distinct from the cancellation of ctx.
+func (gdc *GitDataCollector) blameFile(ctx context.Context, commit *object.Commit, filePath string) (*models.FileBlameStats, error) {
+	backend, err := gdc.gitBackend()
+	if err != nil {
+		return nil, err
+	}
+	timeout := gdc.Options.BlameTimeout
+	if timeout <= 0 {
+		return backend.Blame(ctx, commit, filePath)
+	}
+	fileCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	stats, err := backend.Blame(fileCtx, commit, filePath)
+	if err != nil && ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+		return nil, fmt.Errorf("blame timed out after %s", timeout)
+	}
+	return stats, err
 }
 
 func (gdc *GitDataCollector) collectActiveLineCountByContributor() {
@@ -393,25 +899,43 @@ func (gdc *GitDataCollector) collectActiveLineCountByContributor() {
 		// However, here we just calculate activeLines and then update the map entry.
 		contributorData := gdc.Data.Contributors[contributorName]
 		activeLines := 0
+		var lineAges models.AgeHistogram
 		for _, fileData := range gdc.Data.Files { // gdc.Data.Files is fully populated by now
 			if lines, ok := fileData.LinesByContributor[contributorName]; ok {
 				activeLines += lines
 			}
+			lineAges.Merge(fileData.LineAgesByContributor[contributorName])
 		}
 		contributorData.ActiveLines = activeLines
+		contributorData.LineAges = lineAges
 		gdc.Data.Contributors[contributorName] = contributorData // Update the map with new ActiveLines
 	}
 }
 
-// ClearCache removes the cache file for the current HEAD commit.
+// ClearCache removes the cache for the current HEAD commit, in either format.
 func (gdc *GitDataCollector) ClearCache() error {
-	cacheFile := gdc.cachePath()
-	err := os.Remove(cacheFile)
-	if err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("failed to remove cache file %s: %w", cacheFile, err)
+	cacheDir := gdc.cachePath()
+	existed := gdc.CacheExists()
+	if err := os.RemoveAll(cacheDir); err != nil {
+		return fmt.Errorf("failed to remove cache %s: %w", cacheDir, err)
+	}
+	for _, legacyPath := range gdc.legacyCachePaths() {
+		if err := os.Remove(legacyPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove cache file %s: %w", legacyPath, err)
+		}
 	}
-	if err == nil {
-		fmt.Printf("Cache file %s removed successfully.\n", cacheFile)
+	if existed {
+		gdc.reporter().Infof("Cache %s removed successfully.", cacheDir)
 	}
 	return nil
 }
+
+// ResolveRevision resolves rev, such as a tag, a branch or an abbreviated hash, to the
+// hash of a commit of the repository.
+func (gdc *GitDataCollector) ResolveRevision(rev string) (string, error) {
+	hash, err := gdc.repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve revision %q: %w", rev, err)
+	}
+	return hash.String(), nil
+}
@@ -1,12 +1,25 @@
 package collector
 
 import (
+	"archive/zip"
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"os"
+	"os/exec"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/user/git-inquisitor-go/internal/models"
+	"github.com/user/git-inquisitor-go/internal/progress"
+	"github.com/user/git-inquisitor-go/internal/store"
+	"github.com/user/git-inquisitor-go/pkg/gitutil"
 	// Need a way to mock git repo for collector or use a real one
 	// For caching, we can test without a full repo, just need a collector instance
 	// with a dummy repo path and head commit hash for cache file naming.
@@ -34,11 +47,12 @@ func newTestGitDataCollector(t *testing.T, repoPathBase string, headHash string)
 	}
 
 	gdc := &GitDataCollector{
-		RepoPath: tmpRepoPath, // This is where .inquisitor/cache will be created
+		RepoPath: tmpRepoPath,
+		Options:  Options{CacheDir: filepath.Join(tmpRepoPath, "cache")},
 		head:     dummyHeadCommit,
 		Data: models.CollectedData{
 			Metadata: models.Metadata{
-				Collector: models.CollectorMetadata{InquisitorVersion: "test-v0.1"},
+				Collector: models.CollectorMetadata{InquisitorVersion: InquisitorVersion, OptionsHash: Options{}.Hash()},
 				Repo:      models.RepoMetadata{Commit: models.CommitDetails{SHA: headHash}},
 			},
 			Contributors: make(map[string]models.Contributor),
@@ -59,7 +73,8 @@ func TestCacheOperations(t *testing.T) {
 	defer cleanup()
 
 	// Populate some dummy data
-	gdc.Data.Metadata.Collector.DateCollected = time.Now().Truncate(time.Second) // Truncate for comparison
+	// Times are UTC and truncated: the JSON cache keeps offsets, not *time.Location values.
+	gdc.Data.Metadata.Collector.DateCollected = time.Now().UTC().Truncate(time.Second)
 	gdc.Data.Contributors["testuser"] = models.Contributor{
 		Identities:  []string{"test@example.com"},
 		CommitCount: 10,
@@ -70,7 +85,7 @@ func TestCacheOperations(t *testing.T) {
 	gdc.Data.History = append(gdc.Data.History, models.CommitHistoryItem{
 		Commit:      "commit1",
 		Contributor: "testuser (test@example.com)",
-		Date:        time.Now().Add(-1 * time.Hour).Truncate(time.Second),
+		Date:        time.Now().UTC().Add(-1 * time.Hour).Truncate(time.Second),
 		Message:     "Test commit",
 	})
 
@@ -142,6 +157,323 @@ func TestCacheOperations(t *testing.T) {
 	}
 }
 
+func TestLoadCache_Stale(t *testing.T) {
+	gdc, cleanup := newTestGitDataCollector(t, "staletest", "abcdef1234567890abcdef1234567890abcdef12")
+	defer cleanup()
+	gdc.Data.Metadata.Collector.DateCollected = time.Now()
+	if err := gdc.SaveCache(); err != nil {
+		t.Fatalf("SaveCache() error = %v", err)
+	}
+
+	gdc.Options.TrendSampling = TrendByMonth
+	if err := gdc.LoadCache(); !errors.Is(err, ErrStaleCache) {
+		t.Errorf("LoadCache() with different options error = %v, want ErrStaleCache", err)
+	}
+
+	gdc.Options = Options{CacheDir: gdc.Options.CacheDir, Refresh: true}
+	if err := gdc.LoadCache(); err != nil {
+		t.Errorf("LoadCache() with options not affecting the data error = %v, want nil", err)
+	}
+
+	gdc.Data.Metadata.Collector.InquisitorVersion = "0.0.1-old"
+	if err := gdc.SaveCache(); err != nil {
+		t.Fatalf("SaveCache() error = %v", err)
+	}
+	if err := gdc.LoadCache(); !errors.Is(err, ErrStaleCache) {
+		t.Errorf("LoadCache() of an older version error = %v, want ErrStaleCache", err)
+	}
+}
+
+func TestCollect_NoCache(t *testing.T) {
+	repoPath := createTestRepo(t)
+	commitFile(t, repoPath, "a.txt", "1\n", "first")
+
+	gdc, err := NewGitDataCollector(repoPath)
+	if err != nil {
+		t.Fatalf("NewGitDataCollector() error = %v", err)
+	}
+	gdc.Options = Options{CacheDir: t.TempDir(), NoCache: true}
+	if err := gdc.Collect(context.Background()); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if gdc.CacheExists() {
+		t.Error("CacheExists() = true after Collect() with NoCache")
+	}
+	if gdc.Data.Metadata.Collector.OptionsHash != gdc.Options.Hash() {
+		t.Errorf("OptionsHash = %q, want %q", gdc.Data.Metadata.Collector.OptionsHash, gdc.Options.Hash())
+	}
+}
+
+func TestCollect_JobsDeterministicHistory(t *testing.T) {
+	repoPath := createTestRepo(t)
+	for i := 0; i < 12; i++ {
+		commitFile(t, repoPath, fmt.Sprintf("f%d.txt", i%3), strings.Repeat("line\n", i+1), fmt.Sprintf("commit %d", i))
+	}
+
+	collectWith := func(jobs int) models.CollectedData {
+		gdc, err := NewGitDataCollector(repoPath)
+		if err != nil {
+			t.Fatalf("NewGitDataCollector() error = %v", err)
+		}
+		gdc.Options = Options{NoCache: true, Jobs: jobs}
+		gdc.Progress = progress.NewLog(io.Discard, progress.Quiet)
+		if err := gdc.Collect(context.Background()); err != nil {
+			t.Fatalf("Collect() error = %v", err)
+		}
+		return gdc.Data
+	}
+
+	serial, parallel := collectWith(1), collectWith(4)
+	if len(parallel.History) != 12 {
+		t.Fatalf("History length = %d, want 12", len(parallel.History))
+	}
+	if !reflect.DeepEqual(serial.History, parallel.History) {
+		t.Error("History differs between 1 and 4 jobs")
+	}
+	if !reflect.DeepEqual(serial.Contributors, parallel.Contributors) {
+		t.Errorf("Contributors = %+v with 4 jobs, want %+v", parallel.Contributors, serial.Contributors)
+	}
+}
+
+func TestCollect_CLIBackend(t *testing.T) {
+	repoPath := createTestRepo(t)
+	commitFile(t, repoPath, "a.txt", "1\n2\n", "first")
+	commitFile(t, repoPath, "a.txt", "1\n2\n3\n", "second")
+
+	gdc, err := NewGitDataCollector(repoPath)
+	if err != nil {
+		t.Fatalf("NewGitDataCollector() error = %v", err)
+	}
+	gdc.Options = Options{NoCache: true, Backend: gitutil.BackendCLI}
+	gdc.Progress = progress.NewLog(io.Discard, progress.Quiet)
+	if err := gdc.Collect(context.Background()); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if version := gdc.Data.Metadata.Collector.GitVersion; !strings.HasPrefix(version, "git version") {
+		t.Errorf("GitVersion = %q, want the system git version", version)
+	}
+	if len(gdc.Data.History) != 2 || gdc.Data.History[1].Insertions != 1 {
+		t.Errorf("History = %+v, want 2 commits with 1 insertion in the second", gdc.Data.History)
+	}
+	if gdc.Data.Files["a.txt"].TotalLines != 3 {
+		t.Errorf("a.txt total lines = %d, want 3", gdc.Data.Files["a.txt"].TotalLines)
+	}
+	if ages := gdc.Data.Files["a.txt"].LineAges; ages.Under30Days != 3 || ages.Total() != 3 {
+		t.Errorf("a.txt line ages = %+v, want 3 lines under 30 days", ages)
+	}
+	for name, contributor := range gdc.Data.Contributors {
+		if contributor.LineAges.Total() != contributor.ActiveLines {
+			t.Errorf("Contributor %s line ages = %+v, want %d lines", name, contributor.LineAges, contributor.ActiveLines)
+		}
+	}
+}
+
+func TestCollect_ExcludeIgnoredRevs(t *testing.T) {
+	repoPath := createTestRepo(t)
+	commitFile(t, repoPath, "a.txt", "a\nb\n", "first")
+	commitFile(t, repoPath, "a.txt", "A\nB\n", "reformat")
+	reformat := strings.TrimSpace(runGit(t, repoPath, "rev-parse", "HEAD"))
+	commitFile(t, repoPath, "a.txt", "A\nB\nc\n", "third")
+
+	gdc, err := NewGitDataCollector(repoPath)
+	if err != nil {
+		t.Fatalf("NewGitDataCollector() error = %v", err)
+	}
+	// An entry of the ignore file that does not resolve is skipped with a warning.
+	ignoreFile := filepath.Join(t.TempDir(), "ignore-revs")
+	if err := os.WriteFile(ignoreFile, []byte(reformat+"\n0000000000000000000000000000000000000001\n"), 0600); err != nil {
+		t.Fatalf("Failed to write ignore file: %v", err)
+	}
+	gdc.Options = Options{NoCache: true, IgnoreRevs: []string{"HEAD~1"}, IgnoreRevsFile: ignoreFile, ExcludeIgnoredRevs: true, BlameIgnoreWhitespace: true}
+	gdc.Progress = progress.NewLog(io.Discard, progress.Quiet)
+	if err := gdc.Collect(context.Background()); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if revs := gdc.Data.Metadata.Collector.IgnoredRevs; len(revs) != 1 || revs[0] != reformat {
+		t.Errorf("IgnoredRevs = %v, want [%s]", revs, reformat)
+	}
+	if warnings := gdc.Data.Diagnostics.Warnings; len(warnings) == 0 || !strings.Contains(warnings[0], "0000000000000000000000000000000000000001") {
+		t.Errorf("Warnings = %v, want the unresolved ignored revision", warnings)
+	}
+	if flags := gdc.Data.Metadata.Collector.BlameFlags; len(flags) != 1 || flags[0] != "-w" {
+		t.Errorf("BlameFlags = %v, want [-w]", flags)
+	}
+	for name, contributor := range gdc.Data.Contributors {
+		if contributor.CommitCount != 3 || contributor.Insertions != 3 || contributor.Deletions != 0 {
+			t.Errorf("Contributor %s = %+v, want 3 commits, 3 insertions and 0 deletions", name, contributor)
+		}
+	}
+	if len(gdc.Data.History) != 3 || gdc.Data.History[1].Insertions != 2 {
+		t.Errorf("History = %+v, want the ignored commit's own stats kept", gdc.Data.History)
+	}
+}
+
+func TestCollect_SpillsHistory(t *testing.T) {
+	repoPath := createTestRepo(t)
+	for i := 0; i < 5; i++ {
+		commitFile(t, repoPath, "a.txt", strings.Repeat("line\n", i+1), fmt.Sprintf("commit %d", i))
+	}
+
+	inMemory, err := NewGitDataCollector(repoPath)
+	if err != nil {
+		t.Fatalf("NewGitDataCollector() error = %v", err)
+	}
+	inMemory.Options = Options{NoCache: true}
+	inMemory.Progress = progress.NewLog(io.Discard, progress.Quiet)
+	if err := inMemory.Collect(context.Background()); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	gdc, err := NewGitDataCollector(repoPath)
+	if err != nil {
+		t.Fatalf("NewGitDataCollector() error = %v", err)
+	}
+	defer gdc.Close()
+	gdc.Options = Options{CacheDir: t.TempDir(), MaxMemory: 1} // Spill from the first commit on
+	gdc.Progress = progress.NewLog(io.Discard, progress.Quiet)
+	if err := gdc.Collect(context.Background()); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if !gdc.HistorySpilled() || len(gdc.Data.History) != 0 {
+		t.Fatalf("HistorySpilled() = %v with %d items in memory, want the history on disk", gdc.HistorySpilled(), len(gdc.Data.History))
+	}
+
+	// The cache holds the full history.
+	cached, _ := NewGitDataCollector(repoPath)
+	cached.Options = gdc.Options
+	if err := cached.LoadCache(); err != nil {
+		t.Fatalf("LoadCache() error = %v", err)
+	}
+	// Histories are compared as JSON, since dates come back from disk with a fixed zone.
+	sameHistory := func(a, b []models.CommitHistoryItem) bool {
+		encodedA, _ := json.Marshal(a)
+		encodedB, _ := json.Marshal(b)
+		return string(encodedA) == string(encodedB)
+	}
+	if !sameHistory(cached.Data.History, inMemory.Data.History) {
+		t.Errorf("Cached history differs from the in-memory collection: %d vs %d items", len(cached.Data.History), len(inMemory.Data.History))
+	}
+
+	if err := gdc.LoadHistory(); err != nil {
+		t.Fatalf("LoadHistory() error = %v", err)
+	}
+	if gdc.HistorySpilled() || !sameHistory(gdc.Data.History, inMemory.Data.History) {
+		t.Errorf("LoadHistory() history differs from the in-memory collection: %d vs %d items", len(gdc.Data.History), len(inMemory.Data.History))
+	}
+	entries, _ := os.ReadDir(gdc.cacheDirectory())
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".history-") {
+			t.Errorf("Spool file %s left behind after LoadHistory()", entry.Name())
+		}
+	}
+}
+
+func TestCollect_SpillsHistoryWithoutCache(t *testing.T) {
+	repoPath := createTestRepo(t)
+	commitFile(t, repoPath, "a.txt", "line\n", "first")
+	commitFile(t, repoPath, "a.txt", "line\nline\n", "second")
+	tempDir := t.TempDir()
+	t.Setenv("TMPDIR", tempDir)
+
+	gdc, err := NewGitDataCollector(repoPath)
+	if err != nil {
+		t.Fatalf("NewGitDataCollector() error = %v", err)
+	}
+	defer gdc.Close()
+	cacheDir := filepath.Join(t.TempDir(), "cache")
+	gdc.Options = Options{CacheDir: cacheDir, NoCache: true, MaxMemory: 1}
+	gdc.Progress = progress.NewLog(io.Discard, progress.Quiet)
+	if err := gdc.Collect(context.Background()); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if !gdc.HistorySpilled() {
+		t.Fatalf("HistorySpilled() = false, want the history on disk")
+	}
+	if _, err := os.Stat(cacheDir); !os.IsNotExist(err) {
+		t.Errorf("Cache directory %s created with NoCache", cacheDir)
+	}
+	if entries, _ := os.ReadDir(tempDir); len(entries) != 1 {
+		t.Errorf("Temporary directory has %d entries, want the spool file", len(entries))
+	}
+	if err := gdc.LoadHistory(); err != nil {
+		t.Fatalf("LoadHistory() error = %v", err)
+	}
+	if len(gdc.Data.History) != 2 {
+		t.Errorf("History length = %d, want 2", len(gdc.Data.History))
+	}
+}
+
+func TestCollect_Cancelled(t *testing.T) {
+	repoPath := createTestRepo(t)
+	commitFile(t, repoPath, "a.txt", "1\n", "first")
+
+	gdc, err := NewGitDataCollector(repoPath)
+	if err != nil {
+		t.Fatalf("NewGitDataCollector() error = %v", err)
+	}
+	gdc.Options.CacheDir = t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := gdc.Collect(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Collect() error = %v, want context.Canceled", err)
+	}
+	if gdc.CacheExists() {
+		t.Error("CacheExists() = true after a cancelled Collect()")
+	}
+	if _, err := os.Stat(gdc.cachePath() + ".lock"); !os.IsNotExist(err) {
+		t.Errorf("Lock file left behind after a cancelled Collect(): %v", err)
+	}
+}
+
+func TestBlameFile_Timeout(t *testing.T) {
+	repoPath := createTestRepo(t)
+	commitFile(t, repoPath, "a.txt", "1\n2\n", "first")
+
+	gdc, err := NewGitDataCollector(repoPath)
+	if err != nil {
+		t.Fatalf("NewGitDataCollector() error = %v", err)
+	}
+	gdc.Options.BlameTimeout = time.Nanosecond
+	_, err = gdc.blameFile(context.Background(), gdc.head, "a.txt")
+	if err == nil || !strings.Contains(err.Error(), "blame timed out after 1ns") {
+		t.Errorf("blameFile() error = %v, want a blame timeout", err)
+	}
+}
+
+func TestDiagnostics_Recorded(t *testing.T) {
+	gdc, cleanup := newTestGitDataCollector(t, "diagtest", "abcdef1234567890abcdef1234567890abcdef12")
+	defer cleanup()
+	var out strings.Builder
+	gdc.Progress = progress.NewLog(&out, progress.Normal)
+
+	gdc.skipCommit("c0ffee", phaseCommits, errors.New("object not found"))
+	gdc.skipFile("big.bin", phaseBlame, errors.New("timeout"))
+	gdc.warn("could not get remote URL", errors.New("remote not found"))
+
+	diagnostics := gdc.Data.Diagnostics
+	if diagnostics.Failures() != 2 || len(diagnostics.Warnings) != 1 {
+		t.Errorf("Diagnostics = %+v, want 2 failures and 1 warning", diagnostics)
+	}
+	if file := diagnostics.SkippedFiles[0]; file.Item != "big.bin" || file.Phase != phaseBlame || file.Reason != "timeout" {
+		t.Errorf("Skipped file = %+v", file)
+	}
+	if !strings.Contains(out.String(), "Warning: skipped file file=big.bin phase=blame error=timeout") {
+		t.Errorf("Log output = %q, want a structured warning for big.bin", out.String())
+	}
+
+	// Diagnostics survive the cache.
+	gdc.Data.Metadata.Collector.DateCollected = time.Now()
+	if err := gdc.SaveCache(); err != nil {
+		t.Fatalf("SaveCache() error = %v", err)
+	}
+	if err := gdc.LoadCache(); err != nil {
+		t.Fatalf("LoadCache() error = %v", err)
+	}
+	if gdc.Data.Diagnostics.Failures() != 2 {
+		t.Errorf("Loaded diagnostics = %+v, want 2 failures", gdc.Data.Diagnostics)
+	}
+}
+
 func TestCollect_MetadataPopulation(t *testing.T) {
 	// This test would ideally use a mocked gitutil or a very minimal real git repo.
 	// For now, let's assume NewGitDataCollector can be created (which needs a valid repo path).
@@ -158,7 +490,7 @@ func TestCollect_MetadataPopulation(t *testing.T) {
 	// Create a dummy .git folder to satisfy NewGitDataCollector's repo opening
 	// This won't be a fully functional git repo for go-git, but enough to get past PlainOpen.
 	// For actual git operations like GetHeadCommit, it would fail.
-	// This highlights the need for better mocking or test repo setup for collector.Collect().
+	// This highlights the need for better mocking or test repo setup for collector.Collect(context.Background()).
 	// For now, we can't easily test the full Collect() method without that.
 	// We can test parts like collectMetadata if we can construct GitDataCollector appropriately.
 
@@ -170,3 +502,493 @@ func TestCollect_MetadataPopulation(t *testing.T) {
 	// The cache test above covers the file I/O part of caching.
 	t.Skip("Skipping Collect_MetadataPopulation test due to git repo setup complexity for unit tests. Focus on cache tests.")
 }
+
+// createTestRepo creates a temporary git repository with a configured test identity.
+func createTestRepo(t *testing.T) string {
+	t.Helper()
+	repoPath := t.TempDir()
+	runGit(t, repoPath, "init")
+	runGit(t, repoPath, "config", "user.name", "Test User")
+	runGit(t, repoPath, "config", "user.email", "test@example.com")
+	return repoPath
+}
+
+// runGit runs a git command inside repoPath and fails the test on error.
+func runGit(t *testing.T, repoPath string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", repoPath}, args...)...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, out)
+	}
+	return string(out)
+}
+
+// commitFile writes content to name and commits it with the given message.
+func commitFile(t *testing.T, repoPath, name, content, message string) {
+	t.Helper()
+	fullPath := filepath.Join(repoPath, name)
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		t.Fatalf("Failed to create directory for %s: %v", name, err)
+	}
+	if err := os.WriteFile(fullPath, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write file %s: %v", name, err)
+	}
+	runGit(t, repoPath, "add", name)
+	runGit(t, repoPath, "commit", "-m", message)
+}
+
+// commitFileAt is commitFile with the author and committer dates set to date (RFC 3339).
+func commitFileAt(t *testing.T, repoPath, name, content, date string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(repoPath, name), []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write file %s: %v", name, err)
+	}
+	runGit(t, repoPath, "add", name)
+	cmd := exec.Command("git", "-C", repoPath, "commit", "-m", "change "+date)
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git commit failed: %v\n%s", err, out)
+	}
+}
+
+func TestCollect_ActivityTimeline(t *testing.T) {
+	repoPath := createTestRepo(t)
+	// Three days in a row around a month end (late evening in UTC-5), then a gap.
+	for i, date := range []string{"2024-01-30T10:00:00-05:00", "2024-01-31T23:30:00-05:00", "2024-01-31T09:00:00-05:00", "2024-02-01T08:00:00-05:00", "2024-02-20T12:00:00-05:00"} {
+		commitFileAt(t, repoPath, "a.txt", strings.Repeat("x\n", i+1), date)
+	}
+
+	gdc, err := NewGitDataCollector(repoPath)
+	if err != nil {
+		t.Fatalf("NewGitDataCollector() error = %v", err)
+	}
+	gdc.Options = Options{NoCache: true}
+	gdc.Progress = progress.NewLog(io.Discard, progress.Quiet)
+	if err := gdc.Collect(context.Background()); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	contributor := gdc.Data.Contributors["Test User"]
+	if contributor.ActiveDays != 4 || contributor.LongestStreak != 3 {
+		t.Errorf("ActiveDays = %d, LongestStreak = %d, want 4 and 3", contributor.ActiveDays, contributor.LongestStreak)
+	}
+	if got := contributor.FirstCommitDate.Format(time.RFC3339); got != "2024-01-30T10:00:00-05:00" {
+		t.Errorf("FirstCommitDate = %s, want 2024-01-30T10:00:00-05:00", got)
+	}
+	if got := contributor.LastCommitDate.Format(time.RFC3339); got != "2024-02-20T12:00:00-05:00" {
+		t.Errorf("LastCommitDate = %s, want 2024-02-20T12:00:00-05:00", got)
+	}
+	if want := map[string]int{"2024-01": 3, "2024-02": 2}; !reflect.DeepEqual(contributor.MonthlyCommits, want) {
+		t.Errorf("MonthlyCommits = %v, want %v", contributor.MonthlyCommits, want)
+	}
+	if want := map[string]int{"2024-W05": 4, "2024-W08": 1}; !reflect.DeepEqual(contributor.WeeklyCommits, want) {
+		t.Errorf("WeeklyCommits = %v, want %v", contributor.WeeklyCommits, want)
+	}
+
+	// The late evening commit falls on Wednesday at 23:00 locally, Thursday at 4:00 in UTC.
+	times := contributor.CommitTimes
+	if times.Local.At(time.Wednesday, 23) != 1 || times.UTC.At(time.Thursday, 4) != 1 || times.UTC.At(time.Wednesday, 23) != 0 {
+		t.Errorf("CommitTimes = %+v, want the 23:30 commit on Wednesday locally and Thursday in UTC", times)
+	}
+	if want := map[string]int{"-05:00": 5}; !reflect.DeepEqual(times.TimeZones, want) {
+		t.Errorf("TimeZones = %v, want %v", times.TimeZones, want)
+	}
+	if total := gdc.Data.CommitTimes.UTC.Total(); total != 5 {
+		t.Errorf("repository CommitTimes count %d commits, want 5", total)
+	}
+}
+
+func TestParseTrendSampling(t *testing.T) {
+	testCases := []struct {
+		value     string
+		wantMode  string
+		wantEvery int
+		wantErr   bool
+	}{
+		{"tag", TrendByTag, 0, false},
+		{"month", TrendByMonth, 0, false},
+		{"5", "commits", 5, false},
+		{"0", "", 0, true},
+		{"weekly", "", 0, true},
+	}
+	for _, tc := range testCases {
+		mode, every, err := parseTrendSampling(tc.value)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("parseTrendSampling(%q) error = %v, wantErr %v", tc.value, err, tc.wantErr)
+			continue
+		}
+		if mode != tc.wantMode || every != tc.wantEvery {
+			t.Errorf("parseTrendSampling(%q) = (%q, %d), want (%q, %d)", tc.value, mode, every, tc.wantMode, tc.wantEvery)
+		}
+	}
+}
+
+func TestCollect_Trend(t *testing.T) {
+	repoPath := createTestRepo(t)
+	commitFile(t, repoPath, "a.txt", "1\n", "first")
+	runGit(t, repoPath, "tag", "v1")
+	commitFile(t, repoPath, "a.txt", "1\n2\n", "second")
+	commitFile(t, repoPath, "b.txt", "1\n2\n3\n", "third")
+	runGit(t, repoPath, "tag", "-a", "v2", "-m", "release 2")
+	runGit(t, repoPath, "tag", "v2.0") // A commit with several tags is labelled by the first name
+
+	gdc, err := NewGitDataCollector(repoPath)
+	if err != nil {
+		t.Fatalf("NewGitDataCollector() error = %v", err)
+	}
+	gdc.Options.TrendSampling = TrendByTag
+	gdc.Options.CacheDir = t.TempDir()
+	if err := gdc.Collect(context.Background()); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	if len(gdc.Data.Trend) != 2 {
+		t.Fatalf("Trend length = %d, want 2: %+v", len(gdc.Data.Trend), gdc.Data.Trend)
+	}
+	first, last := gdc.Data.Trend[0], gdc.Data.Trend[1]
+	if first.Label != "v1" || first.TotalLines != 1 {
+		t.Errorf("First trend point = %+v, want label v1 with 1 line", first)
+	}
+	if last.Label != "v2" || last.TotalLines != 5 {
+		t.Errorf("Last trend point = %+v, want label v2 with 5 lines", last)
+	}
+	if last.LinesByContributor["Test User"] != 5 {
+		t.Errorf("Last trend point lines by Test User = %d, want 5", last.LinesByContributor["Test User"])
+	}
+}
+
+func TestCollect_ReuseCachedOptions(t *testing.T) {
+	repoPath := createTestRepo(t)
+	commitFile(t, repoPath, "a.txt", "1\n", "first")
+	commitFile(t, repoPath, "a.txt", "1\n2\n", "second")
+	cacheDir := t.TempDir()
+
+	collected, err := NewGitDataCollector(repoPath)
+	if err != nil {
+		t.Fatalf("NewGitDataCollector() error = %v", err)
+	}
+	collected.Options = Options{CacheDir: cacheDir, TrendSampling: "1", FastStats: true}
+	if err := collected.Collect(context.Background()); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	gdc, err := NewGitDataCollector(repoPath)
+	if err != nil {
+		t.Fatalf("NewGitDataCollector() error = %v", err)
+	}
+	gdc.Options = Options{CacheDir: cacheDir, Jobs: 3, ReuseCachedOptions: true}
+	if !gdc.loadValidCache() {
+		t.Fatalf("loadValidCache() = false, want the cache collected with other options reused")
+	}
+	if len(gdc.Data.Trend) != 2 {
+		t.Errorf("Trend length = %d, want 2", len(gdc.Data.Trend))
+	}
+	want := Options{CacheDir: cacheDir, Jobs: 3, ReuseCachedOptions: true, TrendSampling: "1", FastStats: true}
+	if !reflect.DeepEqual(gdc.Options, want) {
+		t.Errorf("Options = %+v, want %+v", gdc.Options, want)
+	}
+
+	// Without ReuseCachedOptions the options must match.
+	gdc.Options = Options{CacheDir: cacheDir}
+	if gdc.loadValidCache() {
+		t.Errorf("loadValidCache() = true for a cache collected with other options")
+	}
+}
+
+func TestLoadCache_MigratesBaselineCache(t *testing.T) {
+	repoPath := createTestRepo(t)
+	commitFile(t, repoPath, "a.txt", "1\n", "first")
+	head := strings.TrimSpace(runGit(t, repoPath, "rev-parse", "HEAD"))
+
+	// A cache as the first versions wrote it: inside the repository, without options hash.
+	legacy := models.CollectedData{
+		Metadata: models.Metadata{
+			Collector: models.CollectorMetadata{InquisitorVersion: "0.1.0-go", DateCollected: time.Now().UTC()},
+			Repo:      models.RepoMetadata{Commit: models.CommitDetails{SHA: head}},
+		},
+		Files: map[string]models.FileData{"legacy.txt": {TotalLines: 7}},
+	}
+	legacyPath := filepath.Join(LegacyCacheDir(repoPath), head+store.LegacySuffix)
+	if err := os.MkdirAll(filepath.Dir(legacyPath), 0755); err != nil {
+		t.Fatalf("Failed to create legacy cache dir: %v", err)
+	}
+	file, err := os.Create(legacyPath)
+	if err != nil {
+		t.Fatalf("Failed to create legacy cache: %v", err)
+	}
+	zipWriter := zip.NewWriter(file)
+	entry, err := zipWriter.Create("data.gob")
+	if err != nil {
+		t.Fatalf("Failed to create zip entry: %v", err)
+	}
+	if err := gob.NewEncoder(entry).Encode(legacy); err != nil {
+		t.Fatalf("Failed to encode legacy data: %v", err)
+	}
+	if err := zipWriter.Close(); err != nil {
+		t.Fatalf("Failed to close zip: %v", err)
+	}
+	file.Close()
+
+	gdc, err := NewGitDataCollector(repoPath)
+	if err != nil {
+		t.Fatalf("NewGitDataCollector() error = %v", err)
+	}
+	gdc.Options = Options{CacheDir: t.TempDir(), Backend: gitutil.BackendGoGit} // As the CLI sets it
+	// The migrated data is loaded but, collected by another version, not used.
+	if err := gdc.LoadCache(); !errors.Is(err, ErrStaleCache) || !strings.Contains(err.Error(), "0.1.0-go") {
+		t.Errorf("LoadCache() error = %v, want ErrStaleCache for version 0.1.0-go", err)
+	}
+	if gdc.Data.Files["legacy.txt"].TotalLines != 7 {
+		t.Errorf("Files = %+v, want the legacy data", gdc.Data.Files)
+	}
+	if hash := gdc.Data.Metadata.Collector.OptionsHash; hash != gdc.Options.Hash() {
+		t.Errorf("Migrated OptionsHash = %q, want the default options' %q", hash, gdc.Options.Hash())
+	}
+	if !store.Exists(gdc.cachePath()) {
+		t.Errorf("No store at %s after migration", gdc.cachePath())
+	}
+	if _, err := os.Stat(legacyPath); !os.IsNotExist(err) {
+		t.Errorf("Legacy cache %s still exists after migration", legacyPath)
+	}
+	if gdc.loadValidCache() {
+		t.Errorf("loadValidCache() = true for a migrated cache of another version")
+	}
+}
+
+func TestOptionsHash_DefaultBackend(t *testing.T) {
+	if (Options{}).Hash() != (Options{Backend: gitutil.BackendGoGit}).Hash() {
+		t.Errorf("Hash() differs between the empty and the named default backend")
+	}
+	if (Options{}).Hash() == (Options{Backend: gitutil.BackendCLI}).Hash() {
+		t.Errorf("Hash() is the same for the go-git and cli backends")
+	}
+}
+
+func TestCollectHistory(t *testing.T) {
+	repoPath := createTestRepo(t)
+	commitFile(t, repoPath, "a.txt", "1\n", "first")
+	runGit(t, repoPath, "tag", "v1")
+	commitFile(t, repoPath, "a.txt", "1\n2\n", "second")
+	cacheDir := t.TempDir()
+
+	gdc, err := NewGitDataCollector(repoPath)
+	if err != nil {
+		t.Fatalf("NewGitDataCollector() error = %v", err)
+	}
+	gdc.Options.CacheDir = cacheDir
+	if err := gdc.CollectHistory(context.Background()); err != nil {
+		t.Fatalf("CollectHistory() error = %v", err)
+	}
+	if len(gdc.Data.History) != 2 || len(gdc.Data.Releases) == 0 || gdc.Data.Metadata.Repo.Commit.SHA == "" {
+		t.Errorf("CollectHistory() collected %d commits, %d releases and commit %q, want the history, releases and metadata",
+			len(gdc.Data.History), len(gdc.Data.Releases), gdc.Data.Metadata.Repo.Commit.SHA)
+	}
+	if gdc.Data.Files["a.txt"].TotalLines != 0 {
+		t.Errorf("CollectHistory() blamed a.txt, want no blame")
+	}
+	if gdc.CacheExists() {
+		t.Errorf("CollectHistory() cached an incomplete collection")
+	}
+
+	// A full collection is cached and then used as is.
+	if err := gdc.Collect(context.Background()); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	cached, err := NewGitDataCollector(repoPath)
+	if err != nil {
+		t.Fatalf("NewGitDataCollector() error = %v", err)
+	}
+	cached.Options.CacheDir = cacheDir
+	if err := cached.CollectHistory(context.Background()); err != nil {
+		t.Fatalf("CollectHistory() error = %v", err)
+	}
+	if cached.Data.Files["a.txt"].TotalLines != 2 {
+		t.Errorf("CollectHistory() with a cache: a.txt total lines = %d, want 2 from the cache", cached.Data.Files["a.txt"].TotalLines)
+	}
+}
+
+func TestNewRemoteGitDataCollector_FileURL(t *testing.T) {
+	repoPath := createTestRepo(t)
+	commitFile(t, repoPath, "a.txt", "1\n2\n", "first")
+	commitFile(t, repoPath, "a.txt", "1\n2\n3\n", "second")
+	cacheDir := t.TempDir()
+	url := "file://" + repoPath
+
+	gdc, err := NewRemoteGitDataCollector(context.Background(), url, cacheDir, gitutil.CloneOptions{Depth: 1, SingleBranch: true}, false, nil)
+	if err != nil {
+		t.Fatalf("NewRemoteGitDataCollector() error = %v", err)
+	}
+	defer gdc.Close()
+	if err := gdc.Collect(context.Background()); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	if gdc.Data.Metadata.Repo.URL != url {
+		t.Errorf("Repo URL = %s, want %s", gdc.Data.Metadata.Repo.URL, url)
+	}
+	if len(gdc.Data.History) != 1 {
+		t.Errorf("History length = %d, want 1 for a depth 1 clone", len(gdc.Data.History))
+	}
+	if gdc.Data.Files["a.txt"].TotalLines != 3 {
+		t.Errorf("a.txt total lines = %d, want 3", gdc.Data.Files["a.txt"].TotalLines)
+	}
+	// Neither the cache nor the mirror may be written into the source repository.
+	if _, err := os.Stat(filepath.Join(repoPath, ".inquisitor")); !os.IsNotExist(err) {
+		t.Errorf("Cache was written into the source repository")
+	}
+	if !strings.HasPrefix(gdc.cachePath(), cacheDir) {
+		t.Errorf("cachePath() = %s, want it below %s", gdc.cachePath(), cacheDir)
+	}
+	if !gdc.CacheExists() {
+		t.Errorf("CacheExists() = false after Collect()")
+	}
+}
+
+func TestNewRemoteGitDataCollector_ConcurrentMirror(t *testing.T) {
+	repoPath := createTestRepo(t)
+	commitFile(t, repoPath, "a.txt", "1\n", "first")
+	cacheDir := t.TempDir()
+	url := "file://" + repoPath
+
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			gdc, err := NewRemoteGitDataCollector(context.Background(), url, cacheDir, gitutil.CloneOptions{}, false, nil)
+			if err == nil {
+				err = gdc.Close()
+			}
+			errs <- err
+		}()
+	}
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != nil {
+			t.Errorf("NewRemoteGitDataCollector() error = %v", err)
+		}
+	}
+}
+
+func TestNewRemoteGitDataCollector_TempClone(t *testing.T) {
+	repoPath := createTestRepo(t)
+	commitFile(t, repoPath, "a.txt", "1\n", "first")
+
+	gdc, err := NewRemoteGitDataCollector(context.Background(), "file://"+repoPath, t.TempDir(), gitutil.CloneOptions{}, true, nil)
+	if err != nil {
+		t.Fatalf("NewRemoteGitDataCollector() error = %v", err)
+	}
+	clonePath := gdc.RepoPath
+	if _, err := os.Stat(clonePath); err != nil {
+		t.Fatalf("Temporary clone %s missing: %v", clonePath, err)
+	}
+	if err := gdc.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if _, err := os.Stat(clonePath); !os.IsNotExist(err) {
+		t.Errorf("Temporary clone %s still exists after Close()", clonePath)
+	}
+}
+
+func TestCollect_Releases(t *testing.T) {
+	repoPath := createTestRepo(t)
+	commitFileAt(t, repoPath, "a.txt", "1\n", "2024-01-01T10:00:00Z")
+	commitFileAt(t, repoPath, "b.txt", "1\n2\n", "2024-01-02T10:00:00Z")
+	runGit(t, repoPath, "tag", "-a", "v1.0", "-m", "release 1.0")
+	runGit(t, repoPath, "tag", "v1.0.0")
+	commitFileAt(t, repoPath, "a.txt", "1\n2\n", "2024-01-05T10:00:00Z")
+	runGit(t, repoPath, "tag", "nightly")
+	commitFileAt(t, repoPath, "a.txt", "1\n2\n3\n", "2024-01-12T10:00:00Z")
+	runGit(t, repoPath, "tag", "v1.1")
+	commitFileAt(t, repoPath, "c.txt", "1\n", "2024-01-20T10:00:00Z")
+
+	gdc, err := NewGitDataCollector(repoPath)
+	if err != nil {
+		t.Fatalf("NewGitDataCollector() error = %v", err)
+	}
+	gdc.Options = Options{CacheDir: t.TempDir(), ReleaseTags: "v*"}
+	gdc.Progress = progress.NewLog(io.Discard, progress.Quiet)
+	if err := gdc.Collect(context.Background()); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	var releases []string
+	for _, item := range gdc.Data.History {
+		releases = append(releases, item.Release)
+	}
+	if want := []string{"v1.0", "v1.0", "v1.1", "v1.1", ""}; !reflect.DeepEqual(releases, want) {
+		t.Errorf("History releases = %v, want %v", releases, want)
+	}
+
+	if len(gdc.Data.Releases) != 3 {
+		t.Fatalf("Releases = %+v, want v1.0, v1.1 and the unreleased commits", gdc.Data.Releases)
+	}
+	first, second, unreleased := gdc.Data.Releases[0], gdc.Data.Releases[1], gdc.Data.Releases[2]
+	if first.Name != "v1.0" || !reflect.DeepEqual(first.Aliases, []string{"v1.0.0"}) || first.Commits != 2 || first.FilesTouched != 2 ||
+		first.Insertions != 3 || first.Contributors != 1 || first.DaysSincePrevious != 0 {
+		t.Errorf("Releases[0] = %+v, want v1.0 (alias v1.0.0) with 2 commits, 2 files and 3 insertions", first)
+	}
+	if second.Name != "v1.1" || second.Commits != 2 || second.FilesTouched != 1 || second.DaysSincePrevious != 10 {
+		t.Errorf("Releases[1] = %+v, want v1.1 with 2 commits to 1 file, 10 days after v1.0", second)
+	}
+	if !unreleased.Unreleased || unreleased.Commits != 1 || unreleased.DaysSincePrevious != 8 {
+		t.Errorf("Releases[2] = %+v, want 1 unreleased commit, 8 days after v1.1", unreleased)
+	}
+
+	// A new tag makes the cached release attribution stale.
+	runGit(t, repoPath, "tag", "v1.2")
+	if err := gdc.Collect(context.Background()); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if last := gdc.Data.Releases[len(gdc.Data.Releases)-1]; last.Name != "v1.2" || last.Unreleased {
+		t.Errorf("Releases after tagging v1.2 end with %+v, want v1.2", last)
+	}
+}
+
+func TestCollect_Branches(t *testing.T) {
+	repoPath := createTestRepo(t)
+	runGit(t, repoPath, "checkout", "-b", "main")
+	commitFileAt(t, repoPath, "a.txt", "1\n", "2024-01-01T10:00:00Z")
+	runGit(t, repoPath, "branch", "done")
+	runGit(t, repoPath, "checkout", "-b", "feature")
+	commitFileAt(t, repoPath, "feature.txt", "1\n", "2024-01-02T10:00:00Z")
+	commitFileAt(t, repoPath, "a.txt", "1\nf\n", "2024-01-03T10:00:00Z")
+	runGit(t, repoPath, "checkout", "main")
+	commitFileAt(t, repoPath, "b.txt", "1\n", "2024-01-04T10:00:00Z")
+
+	gdc, err := NewGitDataCollector(repoPath)
+	if err != nil {
+		t.Fatalf("NewGitDataCollector() error = %v", err)
+	}
+	gdc.Options = Options{CacheDir: t.TempDir()}
+	gdc.Progress = progress.NewLog(io.Discard, progress.Quiet)
+	if err := gdc.Collect(context.Background()); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	landscape := gdc.Data.Branches
+	if landscape == nil || landscape.Default != "main" || len(landscape.Branches) != 3 {
+		t.Fatalf("Branches = %+v, want done, feature and main compared with main", landscape)
+	}
+	done, feature, main := landscape.Branches[0], landscape.Branches[1], landscape.Branches[2]
+	if done.Name != "done" || !done.Merged || done.Ahead != 0 || done.Behind != 1 || len(done.Files) != 0 {
+		t.Errorf("done = %+v, want merged, 1 commit behind", done)
+	}
+	if feature.Name != "feature" || feature.Merged || feature.Ahead != 2 || feature.Behind != 1 ||
+		!reflect.DeepEqual(feature.Files, []string{"a.txt", "feature.txt"}) || feature.MergeBase != done.Commit {
+		t.Errorf("feature = %+v, want 2 commits ahead and 1 behind, changing a.txt and feature.txt", feature)
+	}
+	if !feature.LastCommitDate.Equal(time.Date(2024, 1, 3, 10, 0, 0, 0, time.UTC)) || feature.LastCommitAuthor != "Test User (test@example.com)" {
+		t.Errorf("feature last commit = %v by %s", feature.LastCommitDate, feature.LastCommitAuthor)
+	}
+	if !main.Default || main.Ahead != 0 || main.Behind != 0 {
+		t.Errorf("main = %+v, want the default branch", main)
+	}
+
+	// Moving branches updates a cached collection without collecting again.
+	runGit(t, repoPath, "branch", "-D", "done")
+	if err := gdc.Collect(context.Background()); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if len(gdc.Data.Branches.Branches) != 2 {
+		t.Errorf("Branches after deleting done = %+v, want feature and main", gdc.Data.Branches.Branches)
+	}
+}
@@ -0,0 +1,49 @@
+package collector
+
+import (
+	"log/slog"
+
+	"github.com/user/git-inquisitor-go/internal/models"
+	"github.com/user/git-inquisitor-go/internal/progress"
+)
+
+// Collection phases recorded in diagnostics.
+const (
+	phaseCommits = "commits"
+	phaseBlame   = "blame"
+	phaseTrend   = "trend"
+)
+
+// logger returns the diagnostics logger, defaulting to one that writes through the progress reporter.
+func (gdc *GitDataCollector) logger() *slog.Logger {
+	if gdc.Logger == nil {
+		gdc.Logger = slog.New(progress.NewHandler(gdc.reporter()))
+	}
+	return gdc.Logger
+}
+
+// skipCommit logs and records a commit left out of the collected data.
+func (gdc *GitDataCollector) skipCommit(sha, phase string, err error) {
+	gdc.logger().Warn("skipped commit", "commit", sha, "phase", phase, "error", err)
+	gdc.Data.Diagnostics.SkippedCommits = append(gdc.Data.Diagnostics.SkippedCommits, models.SkippedItem{
+		Item:   sha,
+		Phase:  phase,
+		Reason: err.Error(),
+	})
+}
+
+// skipFile logs and records a file left out of the collected data.
+func (gdc *GitDataCollector) skipFile(path, phase string, err error) {
+	gdc.logger().Warn("skipped file", "file", path, "phase", phase, "error", err)
+	gdc.Data.Diagnostics.SkippedFiles = append(gdc.Data.Diagnostics.SkippedFiles, models.SkippedItem{
+		Item:   path,
+		Phase:  phase,
+		Reason: err.Error(),
+	})
+}
+
+// warn logs and records a problem that did not cause data to be skipped.
+func (gdc *GitDataCollector) warn(message string, err error) {
+	gdc.logger().Warn(message, "error", err)
+	gdc.Data.Diagnostics.Warnings = append(gdc.Data.Diagnostics.Warnings, message+": "+err.Error())
+}
@@ -0,0 +1,122 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// lockRefreshInterval is how often a held lock file's modification time is renewed.
+	lockRefreshInterval = 10 * time.Second
+	// lockStaleAfter is how long a lock file may go without renewal before it is
+	// considered abandoned (for example by a crashed process) and taken over.
+	lockStaleAfter = 1 * time.Minute
+	// lockPollInterval is how often a waiting process checks whether the lock was released.
+	lockPollInterval = 500 * time.Millisecond
+)
+
+// cacheLock is an exclusive lock file guarding the collection of one cache entry.
+// It is kept alive by a background goroutine touching the file until release.
+type cacheLock struct {
+	path     string
+	done     chan struct{}
+	released sync.Once
+}
+
+// acquireCacheLock creates the lock file at path, waiting while another process holds it.
+// onWait is called once if the lock is busy, before waiting starts. Waiting ends with
+// ctx's error when ctx is cancelled.
+func acquireCacheLock(ctx context.Context, path string, logger *slog.Logger, onWait func()) (*cacheLock, error) {
+	waited := false
+	for {
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600) // #nosec G304 -- path inside the cache dir
+		if err == nil {
+			hostname, _ := os.Hostname()
+			_, _ = fmt.Fprintf(file, "pid %d on %s since %s\n", os.Getpid(), hostname, time.Now().UTC().Format(time.RFC3339))
+			if errClose := file.Close(); errClose != nil {
+				_ = os.Remove(path)
+				return nil, fmt.Errorf("failed to write lock file %s: %w", path, errClose)
+			}
+			lock := &cacheLock{path: path, done: make(chan struct{})}
+			go lock.keepAlive()
+			return lock, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to create lock file %s: %w", path, err)
+		}
+
+		if info, errStat := os.Stat(path); errStat == nil && time.Since(info.ModTime()) > lockStaleAfter {
+			if err := takeOverStaleLock(path, logger); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if !waited && onWait != nil {
+			onWait()
+		}
+		waited = true
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
+
+// takeOverStaleLock removes the lock file at path if it is still stale. Another process
+// may take the lock over at the same time, so rather than being removed in place, the
+// file is first renamed to a name of its own: only one process can move a given file, and
+// the one that did checks that what it moved is still the stale lock, not a lock just
+// created in its place. A fresh lock moved by mistake is linked back unless path was
+// taken again meanwhile. The caller then competes for path with O_EXCL as usual.
+func takeOverStaleLock(path string, logger *slog.Logger) error {
+	moved := fmt.Sprintf("%s.stale-%d-%d", path, os.Getpid(), time.Now().UnixNano())
+	if err := os.Rename(path, moved); err != nil {
+		if os.IsNotExist(err) {
+			return nil // Taken over or released by another process
+		}
+		return fmt.Errorf("failed to move stale lock file %s: %w", path, err)
+	}
+	if info, err := os.Stat(moved); err == nil && time.Since(info.ModTime()) <= lockStaleAfter {
+		_ = os.Link(moved, path) // Fails without harm if path was created again meanwhile
+	} else {
+		logger.Warn("removing stale lock file", "path", path)
+	}
+	if err := os.Remove(moved); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove stale lock file %s: %w", moved, err)
+	}
+	return nil
+}
+
+// keepAlive renews the lock file's modification time so waiting processes don't consider it stale.
+func (l *cacheLock) keepAlive() {
+	ticker := time.NewTicker(lockRefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.done:
+			return
+		case <-ticker.C:
+			now := time.Now()
+			_ = os.Chtimes(l.path, now, now)
+		}
+	}
+}
+
+// release stops renewing and removes the lock file. Calls after the first are no-ops.
+func (l *cacheLock) release() error {
+	var err error
+	l.released.Do(func() {
+		close(l.done)
+		if errRemove := os.Remove(l.path); errRemove != nil && !os.IsNotExist(errRemove) {
+			err = fmt.Errorf("failed to remove lock file %s: %w", l.path, errRemove)
+		}
+	})
+	return err
+}
@@ -0,0 +1,133 @@
+package collector
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testLogger returns a logger that discards everything.
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestAcquireCacheLock_WaitsForRelease(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "commit.lock")
+	first, err := acquireCacheLock(context.Background(), path, testLogger(), nil)
+	if err != nil {
+		t.Fatalf("acquireCacheLock() error = %v", err)
+	}
+
+	acquired := make(chan *cacheLock)
+	waiting := make(chan struct{})
+	go func() {
+		second, errSecond := acquireCacheLock(context.Background(), path, testLogger(), func() { close(waiting) })
+		if errSecond != nil {
+			t.Errorf("second acquireCacheLock() error = %v", errSecond)
+		}
+		acquired <- second
+	}()
+
+	<-waiting
+	select {
+	case <-acquired:
+		t.Fatal("second acquireCacheLock() returned while the lock was held")
+	case <-time.After(2 * lockPollInterval):
+	}
+
+	if err := first.release(); err != nil {
+		t.Fatalf("release() error = %v", err)
+	}
+	if err := first.release(); err != nil {
+		t.Errorf("second release() error = %v, want nil", err)
+	}
+	second := <-acquired
+	if second == nil {
+		t.Fatal("second acquireCacheLock() returned no lock")
+	}
+	if err := second.release(); err != nil {
+		t.Fatalf("release() error = %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Lock file still exists after release")
+	}
+}
+
+func TestAcquireCacheLock_TakesOverStaleLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "commit.lock")
+	if err := os.WriteFile(path, []byte("pid 1\n"), 0600); err != nil {
+		t.Fatalf("Failed to write lock file: %v", err)
+	}
+	old := time.Now().Add(-2 * lockStaleAfter)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatalf("Failed to age lock file: %v", err)
+	}
+
+	lock, err := acquireCacheLock(context.Background(), path, testLogger(), func() { t.Error("acquireCacheLock() waited for a stale lock") })
+	if err != nil {
+		t.Fatalf("acquireCacheLock() error = %v", err)
+	}
+	_ = lock.release()
+}
+
+func TestTakeOverStaleLock_KeepsFreshLock(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "commit.lock")
+	if err := os.WriteFile(path, []byte("pid 1\n"), 0600); err != nil {
+		t.Fatalf("Failed to write lock file: %v", err)
+	}
+
+	// As when another process took the stale lock over between the check and the takeover.
+	if err := takeOverStaleLock(path, testLogger()); err != nil {
+		t.Fatalf("takeOverStaleLock() error = %v", err)
+	}
+	if content, err := os.ReadFile(path); err != nil || string(content) != "pid 1\n" {
+		t.Errorf("Lock file = %q, %v after takeOverStaleLock(), want the fresh lock kept", content, err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("Lock directory has %d entries, want only the lock file", len(entries))
+	}
+}
+
+func TestCollect_Concurrent(t *testing.T) {
+	repoPath := createTestRepo(t)
+	commitFile(t, repoPath, "a.txt", "1\n2\n", "first")
+	cacheDir := t.TempDir()
+
+	var wg sync.WaitGroup
+	errs := make([]error, 3)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			gdc, err := NewGitDataCollector(repoPath)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			gdc.Options.CacheDir = cacheDir
+			if errs[i] = gdc.Collect(context.Background()); errs[i] == nil && gdc.Data.Files["a.txt"].TotalLines != 2 {
+				t.Errorf("Collector %d: a.txt total lines = %d, want 2", i, gdc.Data.Files["a.txt"].TotalLines)
+			}
+		}(i)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("Collector %d: Collect() error = %v", i, err)
+		}
+	}
+
+	entries, err := ListCache(cacheDir, "")
+	if err != nil {
+		t.Fatalf("ListCache() error = %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("ListCache() = %+v, want a single cache entry", entries)
+	}
+}
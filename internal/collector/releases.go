@@ -0,0 +1,167 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/user/git-inquisitor-go/internal/models"
+	"github.com/user/git-inquisitor-go/pkg/gitutil"
+)
+
+// unreleasedName names the summary of the commits that are in no release yet.
+const unreleasedName = "Unreleased"
+
+// releaseSummary accumulates the summary of a release while the history is processed.
+type releaseSummary struct {
+	release      models.Release
+	contributors map[string]bool
+	files        map[string]bool
+}
+
+func newReleaseSummary(release models.Release) *releaseSummary {
+	return &releaseSummary{release: release, contributors: make(map[string]bool), files: make(map[string]bool)}
+}
+
+// tagRefs lists the repository's tags that point at commits, sorted by name.
+func (gdc *GitDataCollector) tagRefs() ([]models.TagRef, error) {
+	tagged, err := gitutil.GetTagCommits(gdc.repo)
+	if err != nil {
+		return nil, err
+	}
+	var refs []models.TagRef
+	for hash, names := range tagged {
+		for _, name := range names {
+			refs = append(refs, models.TagRef{Name: name, Commit: hash.String()})
+		}
+	}
+	sort.Slice(refs, func(i, j int) bool { return refs[i].Name < refs[j].Name })
+	return refs, nil
+}
+
+// tagsChanged reports whether the repository's tags differ from those recorded in the
+// collected metadata, which makes the release attribution stale. Without an open
+// repository there is nothing to compare with.
+func (gdc *GitDataCollector) tagsChanged() (bool, error) {
+	if gdc.repo == nil {
+		return false, nil
+	}
+	refs, err := gdc.tagRefs()
+	if err != nil {
+		return false, err
+	}
+	recorded := gdc.Data.Metadata.Repo.Tags
+	if len(refs) != len(recorded) {
+		return true, nil
+	}
+	for i := range refs {
+		if refs[i] != recorded[i] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// resolveReleases takes the releases from the tags, recorded in the metadata, that
+// match Options.ReleaseTags and point at commits of the analyzed history, oldest first,
+// and attributes every commit to the first release containing it (see
+// gitutil.FirstReleases). Tags pointing at the same commit make one release.
+func (gdc *GitDataCollector) resolveReleases(ctx context.Context, commits []gitutil.CommitRef) error {
+	gdc.releases, gdc.releaseOf, gdc.unreleased = nil, nil, nil
+	tagsByCommit := make(map[plumbing.Hash][]string)
+	for _, ref := range gdc.Data.Metadata.Repo.Tags {
+		if gdc.Options.ReleaseTags != "" {
+			matched, err := path.Match(gdc.Options.ReleaseTags, ref.Name)
+			if err != nil {
+				return fmt.Errorf("invalid release tag pattern %q: %w", gdc.Options.ReleaseTags, err)
+			}
+			if !matched {
+				continue
+			}
+		}
+		hash := plumbing.NewHash(ref.Commit)
+		tagsByCommit[hash] = append(tagsByCommit[hash], ref.Name)
+	}
+	if len(tagsByCommit) == 0 {
+		return nil
+	}
+
+	var tagged []plumbing.Hash
+	for _, ref := range commits { // Oldest first
+		names, ok := tagsByCommit[ref.Hash]
+		if !ok {
+			continue
+		}
+		gdc.releases = append(gdc.releases, newReleaseSummary(models.Release{
+			Name:    names[0],
+			Aliases: names[1:],
+			Commit:  ref.Hash.String(),
+			Date:    ref.When,
+		}))
+		tagged = append(tagged, ref.Hash)
+	}
+	if len(tagged) == 0 {
+		return nil
+	}
+	releaseOf, err := gitutil.FirstReleases(ctx, gdc.repo, tagged)
+	if err != nil {
+		return fmt.Errorf("failed to attribute commits to releases: %w", err)
+	}
+	gdc.releaseOf = releaseOf
+	return nil
+}
+
+// recordRelease adds a commit, committed by contributor, to the summary of the first
+// release containing it and returns the release's name, or "" if it is unreleased or
+// the repository has no releases.
+func (gdc *GitDataCollector) recordRelease(commit *object.Commit, contributor string, stats commitStatsResult) string {
+	if len(gdc.releases) == 0 {
+		return ""
+	}
+	name := ""
+	var summary *releaseSummary
+	if index, ok := gdc.releaseOf[commit.Hash]; ok {
+		summary = gdc.releases[index]
+		name = summary.release.Name
+	} else {
+		if gdc.unreleased == nil {
+			gdc.unreleased = newReleaseSummary(models.Release{Name: unreleasedName, Unreleased: true})
+		}
+		summary = gdc.unreleased
+		if commit.Committer.When.After(summary.release.Date) {
+			summary.release.Date = commit.Committer.When
+		}
+	}
+	summary.release.Commits++
+	summary.release.Insertions += stats.Insertions
+	summary.release.Deletions += stats.Deletions
+	summary.contributors[contributor] = true
+	for filePath := range stats.FilesChanged {
+		summary.files[filePath] = true
+	}
+	return name
+}
+
+// finishReleases sets the release summaries of the collected data, followed by that of
+// the unreleased commits, and releases what recordRelease accumulated.
+func (gdc *GitDataCollector) finishReleases() {
+	summaries := gdc.releases
+	if gdc.unreleased != nil {
+		summaries = append(summaries, gdc.unreleased)
+	}
+	gdc.Data.Releases = nil
+	for i, summary := range summaries {
+		release := summary.release
+		release.Contributors = len(summary.contributors)
+		release.FilesTouched = len(summary.files)
+		if i > 0 {
+			release.DaysSincePrevious = release.Date.Sub(summaries[i-1].release.Date).Hours() / 24
+		}
+		gdc.Data.Releases = append(gdc.Data.Releases, release)
+	}
+	gdc.releases, gdc.releaseOf, gdc.unreleased = nil, nil, nil
+}
@@ -0,0 +1,93 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/user/git-inquisitor-go/internal/progress"
+	"github.com/user/git-inquisitor-go/pkg/gitutil"
+)
+
+// NewRemoteGitDataCollector clones the repository at url (any URL supported by go-git,
+// including file://) and returns a collector for the clone.
+// The clone is a bare mirror kept below cacheDir and refreshed on later runs; when
+// temporary is set it is cloned into a temporary directory that Close removes instead.
+// Collection caches are always stored below cacheDir, keyed by the URL.
+// reporter becomes the collector's Progress; it may be nil. Cancelling ctx aborts the clone.
+func NewRemoteGitDataCollector(ctx context.Context, url, cacheDir string, cloneOpts gitutil.CloneOptions, temporary bool, reporter progress.Reporter) (*GitDataCollector, error) {
+	if reporter == nil {
+		reporter = progress.NewLog(os.Stdout, progress.Normal)
+	}
+	if cacheDir == "" {
+		cacheDir = DefaultCacheDir()
+	}
+
+	var mirrorDir, tempDir string
+	if temporary {
+		dir, err := os.MkdirTemp("", "git-inquisitor-clone-")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create temporary clone directory: %w", err)
+		}
+		tempDir = dir
+		mirrorDir = filepath.Join(dir, "repo.git")
+	} else {
+		mirrorDir = filepath.Join(MirrorsDir(cacheDir), cacheKey(url)+".git")
+		if err := os.MkdirAll(filepath.Dir(mirrorDir), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create mirror directory %s: %w", filepath.Dir(mirrorDir), err)
+		}
+	}
+
+	if !temporary {
+		// Serialize clones and updates of the shared mirror with other processes.
+		lock, err := acquireCacheLock(ctx, mirrorDir+".lock", slog.New(progress.NewHandler(reporter)), func() {
+			reporter.Infof("Another process is fetching %s. Waiting for it to finish...", url)
+		})
+		if err != nil {
+			return nil, err
+		}
+		defer lock.release()
+	}
+	reporter.StartPhase(fmt.Sprintf("Fetching %s into %s", url, mirrorDir), 0)
+	repo, err := gitutil.MirrorRepository(ctx, url, mirrorDir, cloneOpts)
+	reporter.EndPhase()
+	if err != nil {
+		if tempDir != "" {
+			_ = os.RemoveAll(tempDir)
+		}
+		return nil, err
+	}
+
+	head, err := gitutil.GetHeadCommit(repo)
+	if err != nil {
+		if tempDir != "" {
+			_ = os.RemoveAll(tempDir)
+		}
+		return nil, err
+	}
+
+	gdc := newGitDataCollector(mirrorDir, repo, head)
+	gdc.source = url
+	gdc.tempDir = tempDir
+	gdc.Options.CacheDir = cacheDir
+	gdc.Progress = reporter
+	return gdc, nil
+}
+
+// Close releases resources held by the collector, removing any temporary clone
+// and spilled history.
+func (gdc *GitDataCollector) Close() error {
+	if err := gdc.removeHistorySpool(); err != nil {
+		return err
+	}
+	if gdc.tempDir == "" {
+		return nil
+	}
+	if err := os.RemoveAll(gdc.tempDir); err != nil {
+		return fmt.Errorf("failed to remove temporary clone %s: %w", gdc.tempDir, err)
+	}
+	gdc.tempDir = ""
+	return nil
+}
@@ -0,0 +1,109 @@
+package collector
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/user/git-inquisitor-go/internal/models"
+	"github.com/user/git-inquisitor-go/internal/store"
+)
+
+// historyMemoryShare is the part of Options.MaxMemory the in-memory history may take
+// (one in historyMemoryShare) before it is spilled to disk; the rest is left to
+// diffing, blame and the file and contributor maps.
+const historyMemoryShare = 4
+
+// Rough per-item overheads used by historyItemSize: struct, slice and map headers and timestamps.
+const (
+	historyItemOverhead = 256
+	fileChangeOverhead  = 80
+)
+
+// appendHistory adds item to the history. Once the estimated size of the history
+// exceeds its share of Options.MaxMemory, the history is moved to a spool file and
+// further items are appended there.
+func (gdc *GitDataCollector) appendHistory(item models.CommitHistoryItem) error {
+	if gdc.historySpool != nil {
+		return gdc.historySpool.Append(item)
+	}
+	gdc.Data.History = append(gdc.Data.History, item)
+	gdc.historyBytes += historyItemSize(item)
+	if gdc.Options.MaxMemory <= 0 || gdc.historyBytes <= gdc.Options.MaxMemory/historyMemoryShare {
+		return nil
+	}
+	return gdc.spillHistory()
+}
+
+// spillHistory moves the history collected so far into a new spool file next to the caches,
+// rather than in the temporary directory, which may itself be held in memory. With
+// Options.NoCache nothing may be written to the cache directory, so the temporary
+// directory is used after all.
+func (gdc *GitDataCollector) spillHistory() error {
+	dir := os.TempDir()
+	if !gdc.Options.NoCache {
+		dir = gdc.cacheDirectory()
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create cache directory %s: %w", dir, err)
+		}
+	}
+	spool, err := store.NewHistorySpool(dir)
+	if err != nil {
+		return err
+	}
+	for _, item := range gdc.Data.History {
+		if err := spool.Append(item); err != nil {
+			spool.Remove()
+			return err
+		}
+	}
+	gdc.reporter().Infof("History of %d commits exceeds its memory budget; spilling it to disk", len(gdc.Data.History))
+	gdc.historySpool = spool
+	gdc.Data.History = nil
+	return nil
+}
+
+// HistorySpilled reports whether the last Collect moved the history to disk to stay
+// within Options.MaxMemory, leaving Data.History empty. LoadHistory reads it back.
+func (gdc *GitDataCollector) HistorySpilled() bool {
+	return gdc.historySpool != nil
+}
+
+// LoadHistory reads a spilled history back into Data.History, for reports that need
+// all of it, and removes the spool file. It does nothing if the history was not spilled.
+func (gdc *GitDataCollector) LoadHistory() error {
+	if gdc.historySpool == nil {
+		return nil
+	}
+	history := make([]models.CommitHistoryItem, 0, gdc.historySpool.Records())
+	if err := gdc.historySpool.Each(func(item models.CommitHistoryItem) error {
+		history = append(history, item)
+		return nil
+	}); err != nil {
+		return err
+	}
+	gdc.Data.History = history
+	return gdc.removeHistorySpool()
+}
+
+// removeHistorySpool deletes the spool file of a spilled history, if any.
+func (gdc *GitDataCollector) removeHistorySpool() error {
+	if gdc.historySpool == nil {
+		return nil
+	}
+	err := gdc.historySpool.Remove()
+	gdc.historySpool = nil
+	gdc.historyBytes = 0
+	return err
+}
+
+// historyItemSize estimates the memory taken by a history item.
+func historyItemSize(item models.CommitHistoryItem) int64 {
+	size := historyItemOverhead + len(item.Commit) + len(item.Tree) + len(item.Contributor) + len(item.Message)
+	for _, parent := range item.Parents {
+		size += len(parent) + 16
+	}
+	for path := range item.FilesChanged {
+		size += len(path) + fileChangeOverhead
+	}
+	return int64(size)
+}
@@ -0,0 +1,154 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/user/git-inquisitor-go/internal/models"
+	"github.com/user/git-inquisitor-go/pkg/gitutil"
+)
+
+// Trend sampling modes accepted by Options.TrendSampling in addition to a commit interval.
+const (
+	TrendByTag   = "tag"
+	TrendByMonth = "month"
+)
+
+// trendSample is a historical commit selected for trend collection.
+type trendSample struct {
+	Label  string
+	Commit *object.Commit
+}
+
+// parseTrendSampling validates a trend sampling value.
+// It returns the sampling mode and, for interval sampling, the number of commits between samples.
+// Accepted values are "tag", "month" or a positive integer N (every Nth commit).
+func parseTrendSampling(value string) (mode string, every int, err error) {
+	switch value {
+	case TrendByTag, TrendByMonth:
+		return value, 0, nil
+	}
+	every, err = strconv.Atoi(value)
+	if err != nil || every <= 0 {
+		return "", 0, fmt.Errorf("invalid trend sampling %q: must be %q, %q or a positive number of commits", value, TrendByTag, TrendByMonth)
+	}
+	return "commits", every, nil
+}
+
+// ValidateTrendSampling reports whether value is an accepted trend sampling value.
+func ValidateTrendSampling(value string) error {
+	if value == "" {
+		return nil
+	}
+	_, _, err := parseTrendSampling(value)
+	return err
+}
+
+// selectTrendSamples picks the sample points from commits (ordered oldest to newest).
+// HEAD is always included as the final point so the trend ends at the current snapshot.
+func (gdc *GitDataCollector) selectTrendSamples(commits []gitutil.CommitRef) ([]trendSample, error) {
+	mode, every, err := parseTrendSampling(gdc.Options.TrendSampling)
+	if err != nil {
+		return nil, err
+	}
+
+	var selected []trendSample // Commit is resolved below, for the selected commits only
+	var hashes []plumbing.Hash
+	switch mode {
+	case TrendByTag:
+		tagged, errTags := gitutil.GetTagCommits(gdc.repo)
+		if errTags != nil {
+			return nil, errTags
+		}
+		for _, commit := range commits {
+			if names, ok := tagged[commit.Hash]; ok {
+				sort.Strings(names) // Label commits with several tags consistently
+				selected = append(selected, trendSample{Label: names[0]})
+				hashes = append(hashes, commit.Hash)
+			}
+		}
+	case TrendByMonth:
+		// Keep the last commit of each calendar month.
+		for i, commit := range commits {
+			month := commit.When.Format("2006-01")
+			isLast := i == len(commits)-1 || commits[i+1].When.Format("2006-01") != month
+			if isLast {
+				selected = append(selected, trendSample{Label: month})
+				hashes = append(hashes, commit.Hash)
+			}
+		}
+	default:
+		for i := every - 1; i < len(commits); i += every {
+			selected = append(selected, trendSample{Label: commits[i].Hash.String()[:8]})
+			hashes = append(hashes, commits[i].Hash)
+		}
+	}
+
+	samples := selected[:0]
+	for i, sample := range selected {
+		commit, errCommit := gdc.repo.CommitObject(hashes[i])
+		if errCommit != nil {
+			return nil, fmt.Errorf("failed to read trend commit %s: %w", hashes[i], errCommit)
+		}
+		sample.Commit = commit
+		samples = append(samples, sample)
+	}
+
+	if len(samples) == 0 || samples[len(samples)-1].Commit.Hash != gdc.head.Hash {
+		samples = append(samples, trendSample{Label: "HEAD", Commit: gdc.head})
+	}
+	return samples, nil
+}
+
+// collectTrend computes blame-based ownership and total line counts at each sampled commit.
+func (gdc *GitDataCollector) collectTrend(ctx context.Context, commits []gitutil.CommitRef) error {
+	samples, err := gdc.selectTrendSamples(commits)
+	if err != nil {
+		return err
+	}
+
+	reporter := gdc.reporter()
+	reporter.StartPhase("Processing ownership trend", len(samples))
+	defer reporter.EndPhase()
+
+	gdc.Data.Trend = make([]models.TrendPoint, 0, len(samples))
+	for _, sample := range samples {
+		filePaths, errPaths := gdc.backend.FilePaths(ctx, sample.Commit)
+		if errPaths != nil {
+			return fmt.Errorf("failed to list files at %s: %w", sample.Commit.Hash, errPaths)
+		}
+
+		point := models.TrendPoint{
+			Label:              sample.Label,
+			Commit:             sample.Commit.Hash.String(),
+			Date:               sample.Commit.Committer.When,
+			LinesByContributor: make(map[string]int),
+		}
+		gdc.blameFiles(ctx, sample.Commit, filePaths, func(result blameResult) {
+			if ctx.Err() != nil {
+				return
+			}
+			if result.Err != nil {
+				gdc.skipFile(result.Path, phaseTrend+" "+sample.Label, result.Err)
+				return
+			}
+			if result.Stats == nil {
+				return
+			}
+			point.TotalLines += result.Stats.TotalLines
+			for contributor, lines := range result.Stats.LinesByContributor {
+				point.LinesByContributor[contributor] += lines
+			}
+		})
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		gdc.Data.Trend = append(gdc.Data.Trend, point)
+		reporter.Increment(sample.Label)
+	}
+	return nil
+}
@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // CollectedData is the main structure holding all analyzed repository data.
 type CollectedData struct {
@@ -8,6 +11,24 @@ type CollectedData struct {
 	Contributors map[string]Contributor `json:"contributors"`
 	Files        map[string]FileData    `json:"files"`
 	History      []CommitHistoryItem    `json:"history"`
+	Trend        []TrendPoint           `json:"trend,omitempty"` // Only populated when trend sampling is enabled
+	// Releases summarizes the commits first shipped in each release, oldest first; see
+	// collector.Options.ReleaseTags.
+	Releases []Release `json:"releases,omitempty"`
+	// Branches compares the local and remote-tracking branches with the default branch.
+	Branches *BranchLandscape `json:"branches,omitempty"`
+	// Repositories holds per-repository breakdowns; only populated for combined workspace reports.
+	Repositories []RepositorySummary `json:"repositories,omitempty"`
+	// Diagnostics records what could not be collected, so reports can say what is missing.
+	Diagnostics Diagnostics `json:"diagnostics"`
+	// CommitTimes spreads all commits over the hours of the week.
+	CommitTimes CommitTimes `json:"commit_times"`
+	// OrphanedKnowledge is computed when a report is generated, see analysis.OrphanedKnowledge.
+	OrphanedKnowledge *OrphanedKnowledge `json:"orphaned_knowledge,omitempty"`
+	// CommitMessages is computed when a report is generated, see analysis.CommitMessages.
+	CommitMessages *CommitMessages `json:"commit_messages,omitempty"`
+	// Issues aggregates the commits referencing each issue; computed when a report is generated.
+	Issues []IssueSummary `json:"issues,omitempty"`
 }
 
 // Metadata holds information about the collection process and the repository.
@@ -19,12 +40,18 @@ type Metadata struct {
 // CollectorMetadata contains details about the execution environment.
 type CollectorMetadata struct {
 	InquisitorVersion string    `json:"inquisitor_version"`
+	OptionsHash       string    `json:"options_hash,omitempty"` // Digest of the collection options, see collector.Options.Hash
+	// Options are the collection options that took part in OptionsHash, JSON-encoded, so
+	// commands reading the cache can reuse them; see collector.Options.ReuseCachedOptions.
+	Options json.RawMessage `json:"options,omitempty"`
 	DateCollected     time.Time `json:"date_collected"`
 	User              string    `json:"user"`
 	Hostname          string    `json:"hostname"`
 	Platform          string    `json:"platform"`
 	GoVersion         string    `json:"go_version"` // Changed from python_version
 	GitVersion        string    `json:"git_version"`
+	IgnoredRevs       []string  `json:"ignored_revs,omitempty"` // Commits ignored by blame, see collector.Options.IgnoreRevs
+	BlameFlags        []string  `json:"blame_flags,omitempty"`  // git blame flags equivalent to the blame options, such as "-w"
 }
 
 // RepoMetadata contains details about the analyzed repository.
@@ -32,6 +59,14 @@ type RepoMetadata struct {
 	URL    string        `json:"url"`
 	Branch string        `json:"branch"`
 	Commit CommitDetails `json:"commit"`
+	// Tags lists the repository's tags that point at commits, sorted by name.
+	Tags []TagRef `json:"tags,omitempty"`
+}
+
+// TagRef is a tag and the commit it points at, annotated tags peeled.
+type TagRef struct {
+	Name   string `json:"name"`
+	Commit string `json:"commit"`
 }
 
 // CommitDetails holds information about a specific commit, typically HEAD.
@@ -50,6 +85,23 @@ type Contributor struct {
 	Insertions  int      `json:"insertions"`
 	Deletions   int      `json:"deletions"`
 	ActiveLines int      `json:"active_lines"`
+	// LineAges is the age distribution of the contributor's active lines.
+	LineAges AgeHistogram `json:"line_ages"`
+	// FirstCommitDate and LastCommitDate are the dates of the contributor's first and most recent commits.
+	FirstCommitDate time.Time `json:"first_commit_date"`
+	LastCommitDate  time.Time `json:"last_commit_date"`
+	// ActiveDays is the number of days with at least one commit, and LongestStreak the
+	// most such days in a row. In combined workspace reports they are approximated by
+	// the sum and the maximum over the repositories.
+	ActiveDays    int `json:"active_days"`
+	LongestStreak int `json:"longest_streak"`
+	// WeeklyCommits and MonthlyCommits count commits by ISO week ("2006-W01") and month ("2006-01").
+	WeeklyCommits  map[string]int `json:"weekly_commits,omitempty"`
+	MonthlyCommits map[string]int `json:"monthly_commits,omitempty"`
+	// CommitTimes spreads the contributor's commits over the hours of the week.
+	CommitTimes CommitTimes `json:"commit_times"`
+	// Repositories maps repository name to commit count; only populated for combined workspace reports.
+	Repositories map[string]int `json:"repositories,omitempty"`
 }
 
 // FileData stores statistics for a single file in the repository.
@@ -60,6 +112,9 @@ type FileData struct {
 	TotalLines         int            `json:"total_lines"`
 	TopContributor     string         `json:"top_contributor"` // Format: "Name (X.XX%)"
 	LinesByContributor map[string]int `json:"lines_by_contributor"`
+	// LineAges is the age distribution of the file's lines, see FileBlameStats.LineAges.
+	LineAges              AgeHistogram            `json:"line_ages"`
+	LineAgesByContributor map[string]AgeHistogram `json:"line_ages_by_contributor,omitempty"`
 }
 
 // FileBlameStats stores blame information for a file.
@@ -70,6 +125,133 @@ type FileBlameStats struct {
 	TotalLines         int            `json:"total_lines"`
 	TopContributor     string         `json:"top_contributor"`
 	LinesByContributor map[string]int `json:"lines_by_contributor"`
+	// LineAges is the age distribution of the file's lines, relative to the blamed commit.
+	LineAges AgeHistogram `json:"line_ages"`
+	// LineAgesByContributor breaks LineAges down by contributor.
+	LineAgesByContributor map[string]AgeHistogram `json:"line_ages_by_contributor"`
+}
+
+// LineAgeBuckets labels the buckets of an AgeHistogram, youngest first.
+var LineAgeBuckets = []string{"< 30 days", "< 90 days", "< 1 year", "< 2 years", "Older"}
+
+// AgeHistogram counts lines by their age: the time between the commit that last changed
+// them and the analyzed commit.
+type AgeHistogram struct {
+	Under30Days int `json:"under_30_days"`
+	Under90Days int `json:"under_90_days"`
+	Under1Year  int `json:"under_1_year"`
+	Under2Years int `json:"under_2_years"`
+	Older       int `json:"older"`
+}
+
+// Add counts lines of the given age. Negative ages (from clock skew) count as young.
+func (h *AgeHistogram) Add(age time.Duration, lines int) {
+	const day = 24 * time.Hour
+	switch {
+	case age < 30*day:
+		h.Under30Days += lines
+	case age < 90*day:
+		h.Under90Days += lines
+	case age < 365*day:
+		h.Under1Year += lines
+	case age < 2*365*day:
+		h.Under2Years += lines
+	default:
+		h.Older += lines
+	}
+}
+
+// Merge adds the counts of other to h.
+func (h *AgeHistogram) Merge(other AgeHistogram) {
+	h.Under30Days += other.Under30Days
+	h.Under90Days += other.Under90Days
+	h.Under1Year += other.Under1Year
+	h.Under2Years += other.Under2Years
+	h.Older += other.Older
+}
+
+// Counts returns the counts in the order of LineAgeBuckets.
+func (h AgeHistogram) Counts() []int {
+	return []int{h.Under30Days, h.Under90Days, h.Under1Year, h.Under2Years, h.Older}
+}
+
+// Total returns the number of lines counted.
+func (h AgeHistogram) Total() int {
+	return h.Under30Days + h.Under90Days + h.Under1Year + h.Under2Years + h.Older
+}
+
+// CommitTimes records when commits were authored.
+type CommitTimes struct {
+	// Local counts commits by the hour of the week in the author's own time zone,
+	// UTC by the hour of the week in UTC.
+	Local HourOfWeek `json:"local"`
+	UTC   HourOfWeek `json:"utc"`
+	// TimeZones counts commits by the UTC offset of their author date, such as "+02:00".
+	TimeZones map[string]int `json:"time_zones,omitempty"`
+}
+
+// Add counts a commit authored at when.
+func (c *CommitTimes) Add(when time.Time) {
+	c.Local.Add(when)
+	c.UTC.Add(when.UTC())
+	if c.TimeZones == nil {
+		c.TimeZones = make(map[string]int)
+	}
+	c.TimeZones[when.Format("-07:00")]++
+}
+
+// Merge adds the counts of other to c.
+func (c *CommitTimes) Merge(other CommitTimes) {
+	c.Local.Merge(other.Local)
+	c.UTC.Merge(other.UTC)
+	if len(other.TimeZones) > 0 && c.TimeZones == nil {
+		c.TimeZones = make(map[string]int, len(other.TimeZones))
+	}
+	for zone, count := range other.TimeZones {
+		c.TimeZones[zone] += count
+	}
+}
+
+// MainTimeZone returns the time zone most commits were authored in, the earliest offset
+// on ties, or "" if no commits were counted.
+func (c CommitTimes) MainTimeZone() string {
+	main := ""
+	for zone, count := range c.TimeZones {
+		if main == "" || count > c.TimeZones[main] || count == c.TimeZones[main] && zone < main {
+			main = zone
+		}
+	}
+	return main
+}
+
+// HourOfWeek counts commits by the hour of the week they fall in: index 24*weekday+hour,
+// with Sunday as weekday 0, as in time.Weekday.
+type HourOfWeek [7 * 24]int
+
+// Add counts a commit made at t, in t's time zone.
+func (h *HourOfWeek) Add(t time.Time) {
+	h[24*int(t.Weekday())+t.Hour()]++
+}
+
+// Merge adds the counts of other to h.
+func (h *HourOfWeek) Merge(other HourOfWeek) {
+	for i, count := range other {
+		h[i] += count
+	}
+}
+
+// At returns the count of the given weekday and hour.
+func (h HourOfWeek) At(weekday time.Weekday, hour int) int {
+	return h[24*int(weekday)+hour]
+}
+
+// Total returns the number of commits counted.
+func (h HourOfWeek) Total() int {
+	total := 0
+	for _, count := range h {
+		total += count
+	}
+	return total
 }
 
 // CommitHistoryItem represents a single commit in the repository's history.
@@ -77,11 +259,17 @@ type CommitHistoryItem struct {
 	Commit      string    `json:"commit"`  // SHA
 	Parents     []string  `json:"parents"` // List of parent SHAs
 	Tree        string    `json:"tree"`
-	Contributor string    `json:"contributor"` // Format: "Name (email)"
+	Contributor string    `json:"contributor"`      // Format: "Name (email)"
+	Author      string    `json:"author,omitempty"` // Format: "Name (email)"; may differ from the committer
 	Date        time.Time `json:"date"`
 	Message     string    `json:"message"`
 	Insertions  int       `json:"insertions"`
 	Deletions   int       `json:"deletions"`
+	Repository  string    `json:"repository,omitempty"` // Only set in combined workspace reports
+	// Release is the first release (tag) containing the commit; empty for unreleased commits.
+	Release string `json:"release,omitempty"`
+	// Issues are the issue references found in Message when a report is generated, see analysis.Issues.
+	Issues []string `json:"issues,omitempty"`
 	// FilesChanged is a map where key is filepath and value contains stats for that file in that commit.
 	// Example: {"file.py": {"insertions":10, "deletions":2, "lines": 12}}
 	// For simplicity, we'll store it as map[string]interface{} or define a more specific struct if needed.
@@ -97,3 +285,191 @@ type FileCommitStats struct {
 	Deletions  int `json:"deletions"`
 	Lines      int `json:"lines"` // Total lines in file after commit (may not be directly available in all git libs, might need calculation)
 }
+
+// TrendPoint captures blame-based ownership and codebase size at a sampled historical commit.
+type TrendPoint struct {
+	Label              string         `json:"label"`  // Tag name, month (YYYY-MM) or short SHA depending on sampling
+	Commit             string         `json:"commit"` // SHA
+	Date               time.Time      `json:"date"`
+	TotalLines         int            `json:"total_lines"`
+	LinesByContributor map[string]int `json:"lines_by_contributor"`
+}
+
+// OrphanedKnowledge measures how much of the surviving code is owned, by blame, by
+// contributors who have not committed for a while.
+type OrphanedKnowledge struct {
+	InactiveAfter string    `json:"inactive_after"` // Threshold, such as "6mo"
+	AsOf          time.Time `json:"as_of"`
+	// InactiveContributors are the owners of surviving lines whose last activity is older
+	// than the threshold, sorted by name.
+	InactiveContributors []string                 `json:"inactive_contributors"`
+	Repository           OrphanedShare            `json:"repository"`
+	Directories          map[string]OrphanedShare `json:"directories"`
+	Files                map[string]OrphanedShare `json:"files"`
+}
+
+// OrphanedShare is the part of some code owned by inactive contributors.
+type OrphanedShare struct {
+	TotalLines    int     `json:"total_lines"`
+	InactiveLines int     `json:"inactive_lines"`
+	Percentage    float64 `json:"percentage"`
+}
+
+// CommitMessages analyzes the commit messages of the history: Conventional Commits usage,
+// issue references and message quality. Merge commits are left out.
+type CommitMessages struct {
+	Policy        MessagePolicy           `json:"policy"`
+	Overall       MessageStats            `json:"overall"`
+	ByContributor map[string]MessageStats `json:"by_contributor"`
+	ByMonth       map[string]MessageStats `json:"by_month"` // Keyed "2006-01"
+	// Violations lists the commits breaking Policy, newest first.
+	Violations []MessageViolation `json:"violations"`
+}
+
+// MessagePolicy is the set of rules commit messages are checked against.
+type MessagePolicy struct {
+	MaxSubjectLength    int      `json:"max_subject_length"` // 0 disables the check
+	RequireConventional bool     `json:"require_conventional"`
+	AllowedTypes        []string `json:"allowed_types,omitempty"` // Conventional Commit types; empty allows any
+	RequireIssueRef     bool     `json:"require_issue_ref"`
+	RequireImperative   bool     `json:"require_imperative"`
+	RequireBody         bool     `json:"require_body"`
+}
+
+// MessageStats counts the properties of a set of commit messages.
+type MessageStats struct {
+	Commits       int `json:"commits"`
+	Conventional  int `json:"conventional"`    // Subjects in Conventional Commits form
+	Breaking      int `json:"breaking"`        // Conventional commits marked as breaking changes
+	WithIssueRefs int `json:"with_issue_refs"` // Messages referencing an issue, such as #123 or JIRA-456
+	WithBody      int `json:"with_body"`
+	Imperative    int `json:"imperative"` // Subjects starting with a verb in the imperative mood
+	Violations    int `json:"violations"`
+	// AverageSubjectLength is in characters.
+	AverageSubjectLength float64        `json:"average_subject_length"`
+	Types                map[string]int `json:"types,omitempty"`  // Conventional Commit types
+	Scopes               map[string]int `json:"scopes,omitempty"` // Conventional Commit scopes
+}
+
+// Share returns count as a fraction of the commits, or 0 without commits.
+func (s MessageStats) Share(count int) float64 {
+	if s.Commits == 0 {
+		return 0
+	}
+	return float64(count) / float64(s.Commits)
+}
+
+// MessageViolation is a commit whose message breaks the policy.
+type MessageViolation struct {
+	Commit      string    `json:"commit"`
+	Contributor string    `json:"contributor"`
+	Date        time.Time `json:"date"`
+	Subject     string    `json:"subject"`
+	Problems    []string  `json:"problems"`
+}
+
+// Release summarizes the commits first shipped in a release: those reachable from its tag
+// but from no earlier release.
+type Release struct {
+	Name    string   `json:"name"`              // The tag; "Unreleased" for the commits after the last release
+	Aliases []string `json:"aliases,omitempty"` // Other tags pointing at the same commit
+	// Unreleased marks the summary of the commits that are in no release yet.
+	Unreleased   bool      `json:"unreleased,omitempty"`
+	Commit       string    `json:"commit,omitempty"`     // The tagged commit
+	Date         time.Time `json:"date"`                 // Committer date of the tagged commit, or of the latest unreleased commit
+	Repository   string    `json:"repository,omitempty"` // Only set in combined workspace reports
+	Commits      int       `json:"commits"`
+	Contributors int       `json:"contributors"`
+	Insertions   int       `json:"insertions"`
+	Deletions    int       `json:"deletions"`
+	FilesTouched int       `json:"files_touched"`
+	// DaysSincePrevious is the time since the previous release, in days; 0 for the first one.
+	DaysSincePrevious float64 `json:"days_since_previous"`
+}
+
+// BranchLandscape lists the repository's local and remote-tracking branches.
+type BranchLandscape struct {
+	// Default is the branch the others are compared with; empty if there is none, and in
+	// combined workspace reports, where Branch.Default marks each repository's.
+	Default string `json:"default,omitempty"`
+	// StaleAfter, DivergedBehind and AsOf are the thresholds of the Stale and Diverged
+	// flags of the branches, which are set when a report is generated; see
+	// analysis.FlagBranches.
+	StaleAfter     string    `json:"stale_after,omitempty"`
+	DivergedBehind int       `json:"diverged_behind,omitempty"`
+	AsOf           time.Time `json:"as_of,omitempty"`
+	// Branches are sorted by name.
+	Branches []Branch `json:"branches"`
+}
+
+// Branch is a local or remote-tracking branch compared with the default branch.
+type Branch struct {
+	Name             string    `json:"name"` // Such as "feature" or "origin/feature"
+	Remote           bool      `json:"remote,omitempty"`
+	Default          bool      `json:"default,omitempty"`
+	Commit           string    `json:"commit"`
+	LastCommitDate   time.Time `json:"last_commit_date"`   // Committer date of the last commit
+	LastCommitAuthor string    `json:"last_commit_author"` // Author of the last commit, format: "Name (email)"
+	// Ahead and Behind count the commits only on the branch and only on the default branch.
+	Ahead  int `json:"ahead"`
+	Behind int `json:"behind"`
+	// Merged is set when the default branch contains the branch's last commit.
+	Merged    bool   `json:"merged"`
+	MergeBase string `json:"merge_base,omitempty"` // Latest commit shared with the default branch
+	// Files are the paths the branch changes since the merge base, sorted.
+	Files []string `json:"files,omitempty"`
+	// Stale marks branches without commits for a while, Diverged unmerged branches far
+	// behind the default branch.
+	Stale      bool   `json:"stale,omitempty"`
+	Diverged   bool   `json:"diverged,omitempty"`
+	Repository string `json:"repository,omitempty"` // Only set in combined workspace reports
+}
+
+// IssueSummary is the work done on an issue: the non-merge commits referencing it.
+type IssueSummary struct {
+	Key             string    `json:"key"`           // The reference, such as "#123" or "JIRA-456"
+	URL             string    `json:"url,omitempty"` // Link to the issue tracker, if configured
+	Commits         int       `json:"commits"`
+	Insertions      int       `json:"insertions"`
+	Deletions       int       `json:"deletions"`
+	Files           []string  `json:"files"`
+	Contributors    []string  `json:"contributors"`
+	FirstCommitDate time.Time `json:"first_commit_date"`
+	LastCommitDate  time.Time `json:"last_commit_date"`
+}
+
+// RepositorySummary is the per-repository breakdown shown in combined workspace reports.
+type RepositorySummary struct {
+	Name         string        `json:"name"`
+	Path         string        `json:"path"`
+	URL          string        `json:"url"`
+	Branch       string        `json:"branch"`
+	Commit       CommitDetails `json:"commit"`
+	CommitCount  int           `json:"commit_count"`
+	Contributors int           `json:"contributors"`
+	Files        int           `json:"files"`
+	TotalLines   int           `json:"total_lines"`
+	Insertions   int           `json:"insertions"`
+	Deletions    int           `json:"deletions"`
+}
+
+// Diagnostics lists the problems encountered during collection.
+type Diagnostics struct {
+	SkippedCommits []SkippedItem `json:"skipped_commits,omitempty"`
+	SkippedFiles   []SkippedItem `json:"skipped_files,omitempty"`
+	// Warnings are problems that did not cause data to be skipped, such as a missing remote URL.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// SkippedItem is a commit or file left out of the collected data.
+type SkippedItem struct {
+	Item       string `json:"item"`                 // Commit SHA or file path
+	Phase      string `json:"phase"`                // Collection phase, e.g. "commits", "blame" or "trend v1.0"
+	Reason     string `json:"reason"`               // Error message
+	Repository string `json:"repository,omitempty"` // Set in combined workspace reports
+}
+
+// Failures returns the number of skipped commits and files.
+func (d Diagnostics) Failures() int {
+	return len(d.SkippedCommits) + len(d.SkippedFiles)
+}
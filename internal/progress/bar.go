@@ -0,0 +1,131 @@
+package progress
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	barWidth = 30
+	// barRedrawInterval limits how often the bar is redrawn.
+	barRedrawInterval = 100 * time.Millisecond
+)
+
+// Bar is a Reporter for interactive terminals: the current phase is shown as a
+// single progress bar line that is redrawn in place, and messages are printed above it.
+type Bar struct {
+	out      *output
+	tracker  tracker
+	drawn    bool
+	lastDraw time.Time
+}
+
+// NewBar returns a progress bar Reporter writing to the terminal w.
+func NewBar(w io.Writer, level Level) *Bar {
+	return &Bar{out: &output{w: w, level: level}}
+}
+
+// clear erases the bar line, if drawn.
+func (b *Bar) clear() {
+	if b.drawn {
+		_, _ = fmt.Fprint(b.out.w, "\r\033[K")
+		b.drawn = false
+	}
+}
+
+// draw renders the bar line for the current phase.
+func (b *Bar) draw(now time.Time) {
+	if b.out.level < Normal || b.tracker.phase == "" {
+		return
+	}
+	stats := b.tracker.stats(now)
+	bar := ""
+	if stats.Total > 0 {
+		filled := stats.Done * barWidth / stats.Total
+		bar = "[" + strings.Repeat("=", filled) + strings.Repeat(" ", barWidth-filled) + "] "
+	}
+	_, _ = fmt.Fprintf(b.out.w, "\r\033[K%s %s%s", stats.Phase, bar, stats)
+	b.drawn = true
+	b.lastDraw = now
+}
+
+// StartPhase implements Reporter.
+func (b *Bar) StartPhase(name string, total int) {
+	b.out.mu.Lock()
+	defer b.out.mu.Unlock()
+	b.finish()
+	now := time.Now()
+	b.tracker.begin(name, total, now)
+	b.draw(now)
+}
+
+// Increment implements Reporter.
+func (b *Bar) Increment(item string) {
+	b.out.mu.Lock()
+	defer b.out.mu.Unlock()
+	b.tracker.done++
+	now := time.Now()
+	if b.out.level >= Verbose {
+		b.clear()
+		_, _ = fmt.Fprintf(b.out.w, "%s\n", item)
+		b.draw(now)
+		return
+	}
+	if now.Sub(b.lastDraw) >= barRedrawInterval || b.tracker.done == b.tracker.total {
+		b.draw(now)
+	}
+}
+
+// EndPhase implements Reporter.
+func (b *Bar) EndPhase() {
+	b.out.mu.Lock()
+	defer b.out.mu.Unlock()
+	b.finish()
+}
+
+// finish leaves the final state of the current phase on its own line.
+func (b *Bar) finish() {
+	if b.tracker.phase == "" {
+		return
+	}
+	if b.out.level >= Normal {
+		b.draw(time.Now())
+		_, _ = fmt.Fprintln(b.out.w)
+		b.drawn = false
+	}
+	b.tracker = tracker{}
+}
+
+// Infof implements Reporter.
+func (b *Bar) Infof(format string, args ...any) {
+	b.message(Normal, "", format, args...)
+}
+
+// Debugf implements Reporter.
+func (b *Bar) Debugf(format string, args ...any) {
+	b.message(Verbose, "", format, args...)
+}
+
+// Warnf implements Reporter.
+func (b *Bar) Warnf(format string, args ...any) {
+	b.message(Quiet, "Warning: ", format, args...)
+}
+
+func (b *Bar) message(level Level, label, format string, args ...any) {
+	b.out.mu.Lock()
+	defer b.out.mu.Unlock()
+	if b.out.level < level {
+		return
+	}
+	b.clear()
+	_, _ = fmt.Fprintf(b.out.w, "%s%s\n", label, fmt.Sprintf(format, args...))
+	b.draw(time.Now())
+}
+
+// Sub implements Reporter. Concurrent phases cannot share one bar line, so Sub
+// reporters print plain log lines to the same terminal instead.
+func (b *Bar) Sub(prefix string) Reporter {
+	return (&Log{out: b.out, now: time.Now}).Sub(prefix)
+}
@@ -0,0 +1,147 @@
+package progress
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// JSON is a Reporter emitting one JSON object per line, for log aggregation.
+// Progress events follow the same 10% / 5 second cadence as Log.
+type JSON struct {
+	out      *output
+	source   string
+	tracker  tracker
+	lastLine time.Time
+	lastStep int
+	now      func() time.Time
+}
+
+// jsonEvent is a line of JSON output. Optional fields are omitted when empty.
+type jsonEvent struct {
+	Time       time.Time `json:"time"`
+	Level      string    `json:"level"`
+	Event      string    `json:"event"`
+	Source     string    `json:"source,omitempty"`
+	Phase      string    `json:"phase,omitempty"`
+	Message    string    `json:"msg,omitempty"`
+	Item       string    `json:"item,omitempty"`
+	Done       int       `json:"done,omitempty"`
+	Total      int       `json:"total,omitempty"`
+	Percent    float64   `json:"percent,omitempty"`
+	Rate       float64   `json:"rate,omitempty"`
+	ETASeconds float64   `json:"eta_seconds,omitempty"`
+	Elapsed    float64   `json:"elapsed_seconds,omitempty"`
+}
+
+// NewJSON returns a JSON Lines Reporter writing to w.
+func NewJSON(w io.Writer, level Level) *JSON {
+	return &JSON{out: &output{w: w, level: level}, now: time.Now}
+}
+
+func (j *JSON) emit(event jsonEvent) {
+	event.Time = j.now().UTC()
+	event.Source = j.source
+	line, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	_, _ = j.out.w.Write(append(line, '\n'))
+}
+
+// progressEvent fills the counters of an event from stats.
+func progressEvent(level, name string, stats Stats) jsonEvent {
+	event := jsonEvent{
+		Level:      level,
+		Event:      name,
+		Phase:      stats.Phase,
+		Done:       stats.Done,
+		Total:      stats.Total,
+		Rate:       stats.Rate,
+		ETASeconds: stats.ETA.Seconds(),
+		Elapsed:    stats.Elapsed.Seconds(),
+	}
+	if percent := stats.Percent(); percent >= 0 {
+		event.Percent = percent
+	}
+	return event
+}
+
+// StartPhase implements Reporter.
+func (j *JSON) StartPhase(name string, total int) {
+	j.out.mu.Lock()
+	defer j.out.mu.Unlock()
+	now := j.now()
+	j.tracker.begin(name, total, now)
+	j.lastLine, j.lastStep = now, 0
+	if j.out.level >= Normal {
+		j.emit(jsonEvent{Level: "info", Event: "phase_start", Phase: name, Total: total})
+	}
+}
+
+// Increment implements Reporter.
+func (j *JSON) Increment(item string) {
+	j.out.mu.Lock()
+	defer j.out.mu.Unlock()
+	j.tracker.done++
+	now := j.now()
+	stats := j.tracker.stats(now)
+	switch {
+	case j.out.level >= Verbose:
+		event := progressEvent("debug", "item", stats)
+		event.Item = item
+		j.emit(event)
+	case j.out.level >= Normal:
+		step := int(stats.Percent()) / logStepPercent
+		if (stats.Total > 0 && step > j.lastStep && stats.Done < stats.Total) || now.Sub(j.lastLine) >= logInterval {
+			j.emit(progressEvent("info", "progress", stats))
+			j.lastLine, j.lastStep = now, step
+		}
+	}
+}
+
+// EndPhase implements Reporter.
+func (j *JSON) EndPhase() {
+	j.out.mu.Lock()
+	defer j.out.mu.Unlock()
+	if j.tracker.phase == "" {
+		return
+	}
+	if j.out.level >= Normal {
+		j.emit(progressEvent("info", "phase_end", j.tracker.stats(j.now())))
+	}
+	j.tracker = tracker{}
+}
+
+// Infof implements Reporter.
+func (j *JSON) Infof(format string, args ...any) {
+	j.message(Normal, "info", format, args...)
+}
+
+// Debugf implements Reporter.
+func (j *JSON) Debugf(format string, args ...any) {
+	j.message(Verbose, "debug", format, args...)
+}
+
+// Warnf implements Reporter.
+func (j *JSON) Warnf(format string, args ...any) {
+	j.message(Quiet, "warn", format, args...)
+}
+
+func (j *JSON) message(level Level, name, format string, args ...any) {
+	j.out.mu.Lock()
+	defer j.out.mu.Unlock()
+	if j.out.level >= level {
+		j.emit(jsonEvent{Level: name, Event: "message", Message: fmt.Sprintf(format, args...)})
+	}
+}
+
+// Sub implements Reporter.
+func (j *JSON) Sub(prefix string) Reporter {
+	source := prefix
+	if j.source != "" {
+		source = j.source + "/" + prefix
+	}
+	return &JSON{out: j.out, source: source, now: j.now}
+}
@@ -0,0 +1,116 @@
+package progress
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	// logStepPercent is the progress step between two periodic log lines.
+	logStepPercent = 10
+	// logInterval is the longest time between two periodic log lines.
+	logInterval = 5 * time.Second
+)
+
+// Log is a Reporter printing plain lines: one per phase start and end, and a
+// periodic progress line every 10% or 5 seconds. It suits CI logs.
+type Log struct {
+	out      *output
+	prefix   string
+	tracker  tracker
+	lastLine time.Time
+	lastStep int
+	now      func() time.Time
+}
+
+// NewLog returns a plain log Reporter writing to w.
+func NewLog(w io.Writer, level Level) *Log {
+	return &Log{out: &output{w: w, level: level}, now: time.Now}
+}
+
+func (l *Log) printf(format string, args ...any) {
+	_, _ = fmt.Fprintf(l.out.w, l.prefix+format+"\n", args...)
+}
+
+// StartPhase implements Reporter.
+func (l *Log) StartPhase(name string, total int) {
+	l.out.mu.Lock()
+	defer l.out.mu.Unlock()
+	now := l.now()
+	l.tracker.begin(name, total, now)
+	l.lastLine, l.lastStep = now, 0
+	if l.out.level < Normal {
+		return
+	}
+	if total > 0 {
+		l.printf("%s (%d items)...", name, total)
+	} else {
+		l.printf("%s...", name)
+	}
+}
+
+// Increment implements Reporter.
+func (l *Log) Increment(item string) {
+	l.out.mu.Lock()
+	defer l.out.mu.Unlock()
+	l.tracker.done++
+	now := l.now()
+	stats := l.tracker.stats(now)
+	switch {
+	case l.out.level >= Verbose:
+		l.printf("%s: %s %s", stats.Phase, stats, item)
+	case l.out.level >= Normal:
+		step := int(stats.Percent()) / logStepPercent
+		if (stats.Total > 0 && step > l.lastStep && stats.Done < stats.Total) || now.Sub(l.lastLine) >= logInterval {
+			l.printf("%s: %s", stats.Phase, stats)
+			l.lastLine, l.lastStep = now, step
+		}
+	}
+}
+
+// EndPhase implements Reporter.
+func (l *Log) EndPhase() {
+	l.out.mu.Lock()
+	defer l.out.mu.Unlock()
+	if l.tracker.phase == "" {
+		return
+	}
+	stats := l.tracker.stats(l.now())
+	if l.out.level >= Normal {
+		if stats.Done > 0 {
+			l.printf("%s done: %d items in %s (%.1f/s)", stats.Phase, stats.Done, FormatDuration(stats.Elapsed), stats.Rate)
+		} else {
+			l.printf("%s done in %s", stats.Phase, FormatDuration(stats.Elapsed))
+		}
+	}
+	l.tracker = tracker{}
+}
+
+// Infof implements Reporter.
+func (l *Log) Infof(format string, args ...any) {
+	l.message(Normal, "", format, args...)
+}
+
+// Debugf implements Reporter.
+func (l *Log) Debugf(format string, args ...any) {
+	l.message(Verbose, "", format, args...)
+}
+
+// Warnf implements Reporter.
+func (l *Log) Warnf(format string, args ...any) {
+	l.message(Quiet, "Warning: ", format, args...)
+}
+
+func (l *Log) message(level Level, label, format string, args ...any) {
+	l.out.mu.Lock()
+	defer l.out.mu.Unlock()
+	if l.out.level >= level {
+		l.printf("%s%s", label, fmt.Sprintf(format, args...))
+	}
+}
+
+// Sub implements Reporter.
+func (l *Log) Sub(prefix string) Reporter {
+	return &Log{out: l.out, prefix: l.prefix + "[" + prefix + "] ", now: l.now}
+}
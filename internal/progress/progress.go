@@ -0,0 +1,155 @@
+// Package progress reports the progress of long running collection phases.
+//
+// A Reporter receives phases with an optional item count, one Increment per finished
+// item, and informational, verbose and warning messages. Implementations render this
+// as a redrawn progress bar for terminals, periodic plain log lines for CI logs, or
+// JSON Lines events for machines; all of them compute throughput and ETA per phase.
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Level controls how much a Reporter prints.
+type Level int
+
+const (
+	// Quiet only prints warnings.
+	Quiet Level = iota
+	// Normal prints phases, periodic progress and informational messages.
+	Normal
+	// Verbose additionally prints every finished item and debug messages.
+	Verbose
+)
+
+// Output formats accepted by New.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Reporter receives progress and messages from a collection.
+// Implementations are safe for concurrent use.
+type Reporter interface {
+	// StartPhase begins a new phase, ending any current one. total is the number
+	// of items in the phase, or 0 if unknown.
+	StartPhase(name string, total int)
+	// Increment records one finished item of the current phase.
+	Increment(item string)
+	// EndPhase finishes the current phase.
+	EndPhase()
+	// Infof prints an informational message.
+	Infof(format string, args ...any)
+	// Debugf prints a message in verbose mode only.
+	Debugf(format string, args ...any)
+	// Warnf prints a warning, even in quiet mode.
+	Warnf(format string, args ...any)
+	// Sub returns a Reporter for work running concurrently with this one, such as
+	// another repository of a workspace. Its messages are prefixed with prefix.
+	Sub(prefix string) Reporter
+}
+
+// Stats is a snapshot of the progress of a phase.
+type Stats struct {
+	Phase   string
+	Done    int
+	Total   int // 0 if unknown
+	Elapsed time.Duration
+	Rate    float64       // Items per second
+	ETA     time.Duration // 0 if unknown
+}
+
+// Percent returns the finished share of the phase in percent, or -1 if the total is unknown.
+func (s Stats) Percent() float64 {
+	if s.Total <= 0 {
+		return -1
+	}
+	return float64(s.Done) * 100 / float64(s.Total)
+}
+
+// String renders the counts, throughput and ETA, e.g. "120/1200 (10%), 45.2/s, ETA 24s".
+func (s Stats) String() string {
+	text := fmt.Sprintf("%d", s.Done)
+	if s.Total > 0 {
+		text = fmt.Sprintf("%d/%d (%.0f%%)", s.Done, s.Total, s.Percent())
+	}
+	if s.Rate > 0 {
+		text += fmt.Sprintf(", %.1f/s", s.Rate)
+	}
+	if s.ETA > 0 {
+		text += ", ETA " + FormatDuration(s.ETA)
+	}
+	return text
+}
+
+// tracker keeps the counters of the current phase.
+type tracker struct {
+	phase string
+	total int
+	done  int
+	start time.Time
+}
+
+func (t *tracker) begin(name string, total int, now time.Time) {
+	*t = tracker{phase: name, total: total, start: now}
+}
+
+func (t *tracker) stats(now time.Time) Stats {
+	stats := Stats{Phase: t.phase, Done: t.done, Total: t.total, Elapsed: now.Sub(t.start)}
+	if seconds := stats.Elapsed.Seconds(); seconds > 0 && t.done > 0 {
+		stats.Rate = float64(t.done) / seconds
+		if t.total > t.done {
+			stats.ETA = time.Duration(float64(t.total-t.done) / stats.Rate * float64(time.Second))
+		}
+	}
+	return stats
+}
+
+// output is the destination shared by a reporter and its Sub reporters.
+type output struct {
+	mu    sync.Mutex
+	w     io.Writer
+	level Level
+}
+
+// New returns a Reporter writing to w in the given format (FormatText or FormatJSON).
+// Text output is a progress bar when w is a terminal and plain log lines otherwise.
+func New(w io.Writer, format string, level Level) (Reporter, error) {
+	switch format {
+	case FormatText, "":
+		if IsTerminal(w) {
+			return NewBar(w, level), nil
+		}
+		return NewLog(w, level), nil
+	case FormatJSON:
+		return NewJSON(w, level), nil
+	default:
+		return nil, fmt.Errorf("invalid log format '%s'. Must be '%s' or '%s'", format, FormatText, FormatJSON)
+	}
+}
+
+// IsTerminal reports whether w is a character device such as an interactive terminal.
+func IsTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// FormatDuration renders d rounded to a precision suitable for progress output.
+func FormatDuration(d time.Duration) string {
+	switch {
+	case d >= time.Hour:
+		return d.Round(time.Minute).String()
+	case d >= time.Minute:
+		return d.Round(time.Second).String()
+	default:
+		return d.Round(100 * time.Millisecond).String()
+	}
+}
@@ -0,0 +1,154 @@
+package progress
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeClock returns a clock function advancing by step on every call.
+func fakeClock(step time.Duration) func() time.Time {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return func() time.Time {
+		now = now.Add(step)
+		return now
+	}
+}
+
+func TestStats(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tr := tracker{phase: "Blame", total: 100, done: 25, start: start}
+	stats := tr.stats(start.Add(10 * time.Second))
+	if stats.Rate != 2.5 {
+		t.Errorf("Rate = %v, want 2.5", stats.Rate)
+	}
+	if stats.ETA != 30*time.Second {
+		t.Errorf("ETA = %v, want 30s", stats.ETA)
+	}
+	if got, want := stats.String(), "25/100 (25%), 2.5/s, ETA 30s"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	unknownTracker := tracker{phase: "Listing", done: 3, start: start}
+	unknown := unknownTracker.stats(start.Add(time.Second))
+	if unknown.Percent() != -1 || unknown.ETA != 0 {
+		t.Errorf("Stats without total = %+v, want unknown percent and ETA", unknown)
+	}
+}
+
+func TestLog_Cadence(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewLog(&buf, Normal)
+	log.now = fakeClock(time.Millisecond)
+
+	log.StartPhase("Processing files", 100)
+	for i := 0; i < 100; i++ {
+		log.Increment("file")
+	}
+	log.EndPhase()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	// Start line, one line per 10% step below 100%, and the summary.
+	if len(lines) != 11 {
+		t.Fatalf("Log output has %d lines, want 11:\n%s", len(lines), buf.String())
+	}
+	if lines[0] != "Processing files (100 items)..." {
+		t.Errorf("First line = %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "Processing files: 10/100 (10%)") {
+		t.Errorf("Second line = %q", lines[1])
+	}
+	if !strings.HasPrefix(lines[10], "Processing files done: 100 items in") {
+		t.Errorf("Last line = %q", lines[10])
+	}
+}
+
+func TestLog_Levels(t *testing.T) {
+	var buf bytes.Buffer
+	quiet := NewLog(&buf, Quiet)
+	quiet.StartPhase("Phase", 2)
+	quiet.Increment("a")
+	quiet.Infof("info")
+	quiet.Debugf("debug")
+	quiet.Warnf("careful %d", 1)
+	quiet.EndPhase()
+	if got := buf.String(); got != "Warning: careful 1\n" {
+		t.Errorf("Quiet output = %q, want only the warning", got)
+	}
+
+	buf.Reset()
+	verbose := NewLog(&buf, Verbose).Sub("api")
+	verbose.StartPhase("Phase", 1)
+	verbose.Increment("main.go")
+	verbose.Debugf("debug")
+	if out := buf.String(); !strings.Contains(out, "[api] Phase: 1/1 (100%)") || !strings.Contains(out, "main.go") || !strings.Contains(out, "[api] debug") {
+		t.Errorf("Verbose output = %q, want prefixed item and debug lines", out)
+	}
+}
+
+func TestJSON_Events(t *testing.T) {
+	var buf bytes.Buffer
+	reporter := NewJSON(&buf, Normal)
+	reporter.now = fakeClock(time.Second)
+	sub := reporter.Sub("web")
+
+	sub.StartPhase("Processing commits", 2)
+	sub.Increment("c1")
+	sub.Increment("c2")
+	sub.EndPhase()
+	sub.Warnf("skipped %s", "x")
+
+	var events []jsonEvent
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var event jsonEvent
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("Invalid JSON line %q: %v", line, err)
+		}
+		events = append(events, event)
+	}
+	if len(events) != 4 {
+		t.Fatalf("Got %d events, want 4: %s", len(events), buf.String())
+	}
+	if events[0].Event != "phase_start" || events[0].Total != 2 || events[0].Source != "web" {
+		t.Errorf("First event = %+v", events[0])
+	}
+	if events[1].Event != "progress" || events[1].Done != 1 || events[1].Percent != 50 || events[1].ETASeconds <= 0 {
+		t.Errorf("Progress event = %+v", events[1])
+	}
+	if events[2].Event != "phase_end" || events[2].Done != 2 {
+		t.Errorf("End event = %+v", events[2])
+	}
+	if events[3].Level != "warn" || events[3].Message != "skipped x" {
+		t.Errorf("Warning event = %+v", events[3])
+	}
+}
+
+func TestNew_InvalidFormat(t *testing.T) {
+	if _, err := New(&bytes.Buffer{}, "xml", Normal); err == nil {
+		t.Error("New() with invalid format expected error, got nil")
+	}
+	reporter, err := New(&bytes.Buffer{}, FormatText, Normal)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, ok := reporter.(*Log); !ok {
+		t.Errorf("New() for a non-terminal = %T, want *Log", reporter)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(NewLog(&buf, Normal))).With("repository", "api")
+	logger.Warn("skipped file", "file", "a b.go", "error", errors.New("boom"))
+	logger.Debug("hidden")
+	logger.WithGroup("blame").Info("done", "files", 3)
+
+	want := "Warning: skipped file repository=api file=\"a b.go\" error=boom\ndone repository=api blame.files=3\n"
+	if got := buf.String(); got != want {
+		t.Errorf("Handler output = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,86 @@
+package progress
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// Handler is a slog.Handler that forwards records to a Reporter, so diagnostics
+// logged through log/slog are filtered by the same level and interleave cleanly
+// with progress output (for example above a progress bar).
+// Attributes are appended to the message as key=value pairs.
+type Handler struct {
+	reporter Reporter
+	attrs    string
+	group    string
+}
+
+// NewHandler returns a slog.Handler writing to reporter.
+func NewHandler(reporter Reporter) *Handler {
+	return &Handler{reporter: reporter}
+}
+
+// Enabled implements slog.Handler. Filtering happens in the Reporter.
+func (h *Handler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+// Handle implements slog.Handler.
+func (h *Handler) Handle(_ context.Context, record slog.Record) error {
+	var b strings.Builder
+	b.WriteString(record.Message)
+	b.WriteString(h.attrs)
+	record.Attrs(func(attr slog.Attr) bool {
+		writeAttr(&b, h.group, attr)
+		return true
+	})
+
+	switch {
+	case record.Level >= slog.LevelWarn:
+		h.reporter.Warnf("%s", b.String())
+	case record.Level >= slog.LevelInfo:
+		h.reporter.Infof("%s", b.String())
+	default:
+		h.reporter.Debugf("%s", b.String())
+	}
+	return nil
+}
+
+// WithAttrs implements slog.Handler.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var b strings.Builder
+	b.WriteString(h.attrs)
+	for _, attr := range attrs {
+		writeAttr(&b, h.group, attr)
+	}
+	return &Handler{reporter: h.reporter, attrs: b.String(), group: h.group}
+}
+
+// WithGroup implements slog.Handler.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &Handler{reporter: h.reporter, attrs: h.attrs, group: h.group + name + "."}
+}
+
+// writeAttr appends " key=value" for attr, quoting values that contain spaces.
+func writeAttr(b *strings.Builder, group string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+	if attr.Value.Kind() == slog.KindGroup {
+		for _, member := range attr.Value.Group() {
+			writeAttr(b, group+attr.Key+".", member)
+		}
+		return
+	}
+	value := attr.Value.String()
+	if strings.ContainsAny(value, " \t\n\"") {
+		value = fmt.Sprintf("%q", value)
+	}
+	fmt.Fprintf(b, " %s%s=%s", group, attr.Key, value)
+}
@@ -0,0 +1,179 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/user/git-inquisitor-go/internal/models"
+)
+
+// Size of the contributor sparklines, in SVG user units.
+const (
+	sparklineWidth  = 100
+	sparklineHeight = 20
+)
+
+// buildSparklines returns, for each contributor with commits, the points of an SVG
+// polyline plotting their monthly commits over the months spanned by all contributors,
+// so that sparklines line up with each other.
+func buildSparklines(contributors map[string]models.Contributor) map[string]string {
+	months := monthRange(contributors)
+	sparklines := make(map[string]string, len(contributors))
+	if len(months) == 0 {
+		return sparklines
+	}
+	for name, contributor := range contributors {
+		peak := 0
+		for _, count := range contributor.MonthlyCommits {
+			if count > peak {
+				peak = count
+			}
+		}
+		if peak == 0 {
+			continue
+		}
+		points := make([]string, len(months))
+		for i, month := range months {
+			x := 0.0
+			if len(months) > 1 {
+				x = float64(i) * sparklineWidth / float64(len(months)-1)
+			}
+			y := sparklineHeight - 1 - float64(contributor.MonthlyCommits[month])*(sparklineHeight-2)/float64(peak)
+			points[i] = fmt.Sprintf("%.1f,%.1f", x, y)
+		}
+		sparklines[name] = strings.Join(points, " ")
+	}
+	return sparklines
+}
+
+// monthRange lists the months ("2006-01") from the earliest to the latest month in
+// which any contributor committed.
+func monthRange(contributors map[string]models.Contributor) []string {
+	var first, last time.Time
+	for _, contributor := range contributors {
+		for month := range contributor.MonthlyCommits {
+			date, err := time.Parse("2006-01", month)
+			if err != nil {
+				continue
+			}
+			if first.IsZero() || date.Before(first) {
+				first = date
+			}
+			if date.After(last) {
+				last = date
+			}
+		}
+	}
+	if first.IsZero() {
+		return nil
+	}
+	var months []string
+	for month := first; !month.After(last); month = month.AddDate(0, 1, 0) {
+		months = append(months, month.Format("2006-01"))
+	}
+	return months
+}
+
+// Working hours used for the after-hours share: weekdays from 9:00 to 18:00.
+const (
+	workdayStart = 9
+	workdayEnd   = 18
+)
+
+// PunchCardSeries is the commit times of the repository or of one contributor.
+type PunchCardSeries struct {
+	Name  string
+	Local models.HourOfWeek
+	UTC   models.HourOfWeek
+	// AfterHours is the share of commits authored on weekends or outside working hours, local time.
+	AfterHours float64
+}
+
+// TimeZoneRow counts the contributors whose commits are mostly authored in a time zone,
+// and the commits authored in it.
+type TimeZoneRow struct {
+	Zone         string
+	Contributors int
+	Commits      int
+}
+
+// CommitTimes holds the punch cards and time zone distribution of the HTML report.
+type CommitTimes struct {
+	// Series lists the whole repository first, then contributors by commit count.
+	Series    []PunchCardSeries
+	TimeZones []TimeZoneRow
+}
+
+// buildCommitTimes gathers the punch cards of the repository and its contributors and
+// the time zones they commit from, sorted from west to east.
+func buildCommitTimes(data *models.CollectedData) CommitTimes {
+	var commitTimes CommitTimes
+	commitTimes.Series = append(commitTimes.Series, newPunchCardSeries("All contributors", data.CommitTimes))
+
+	names := make([]string, 0, len(data.Contributors))
+	for name := range data.Contributors {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := data.Contributors[names[i]], data.Contributors[names[j]]
+		if a.CommitCount != b.CommitCount {
+			return a.CommitCount > b.CommitCount
+		}
+		return names[i] < names[j]
+	})
+
+	zones := make(map[string]*TimeZoneRow)
+	zoneRow := func(zone string) *TimeZoneRow {
+		if zones[zone] == nil {
+			zones[zone] = &TimeZoneRow{Zone: zone}
+		}
+		return zones[zone]
+	}
+	for _, name := range names {
+		times := data.Contributors[name].CommitTimes
+		if times.Local.Total() == 0 {
+			continue
+		}
+		commitTimes.Series = append(commitTimes.Series, newPunchCardSeries(name, times))
+		zoneRow(times.MainTimeZone()).Contributors++
+	}
+	for zone, count := range data.CommitTimes.TimeZones {
+		zoneRow(zone).Commits += count
+	}
+	for _, row := range zones {
+		commitTimes.TimeZones = append(commitTimes.TimeZones, *row)
+	}
+	sort.Slice(commitTimes.TimeZones, func(i, j int) bool {
+		return utcOffsetMinutes(commitTimes.TimeZones[i].Zone) < utcOffsetMinutes(commitTimes.TimeZones[j].Zone)
+	})
+	return commitTimes
+}
+
+// newPunchCardSeries returns the punch card series of times.
+func newPunchCardSeries(name string, times models.CommitTimes) PunchCardSeries {
+	series := PunchCardSeries{Name: name, Local: times.Local, UTC: times.UTC}
+	total, afterHours := times.Local.Total(), 0
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		for hour := 0; hour < 24; hour++ {
+			if weekday == time.Saturday || weekday == time.Sunday || hour < workdayStart || hour >= workdayEnd {
+				afterHours += times.Local.At(weekday, hour)
+			}
+		}
+	}
+	if total > 0 {
+		series.AfterHours = float64(afterHours) / float64(total)
+	}
+	return series
+}
+
+// utcOffsetMinutes parses a UTC offset such as "-05:30" into minutes east of UTC.
+func utcOffsetMinutes(zone string) int {
+	offset, err := time.Parse("-07:00", zone)
+	if err != nil {
+		return 0
+	}
+	_, seconds := offset.Zone()
+	return seconds / 60
+}
@@ -0,0 +1,81 @@
+package report
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/user/git-inquisitor-go/internal/models"
+)
+
+// maxBranchFiles bounds the files listed for a branch in the branch table.
+const maxBranchFiles = 20
+
+// BranchRow is a branch in the branch section.
+type BranchRow struct {
+	models.Branch
+	// FileList names the first files the branch changes, for a tooltip.
+	FileList string
+}
+
+// Branches holds the branch section of the HTML report.
+type Branches struct {
+	*models.BranchLandscape
+	Local, Remote, Merged, Stale, Diverged int
+	// Rows lists the default branches first, then the others by last commit, oldest
+	// (most likely abandoned) first.
+	Rows []BranchRow
+}
+
+// buildBranches counts and orders data's branches for the report, or returns nil if
+// there are none.
+func buildBranches(data *models.CollectedData) *Branches {
+	if data.Branches == nil || len(data.Branches.Branches) == 0 {
+		return nil
+	}
+	branches := &Branches{BranchLandscape: data.Branches}
+	for _, branch := range data.Branches.Branches {
+		if branch.Remote {
+			branches.Remote++
+		} else {
+			branches.Local++
+		}
+		if branch.Merged && !branch.Default {
+			branches.Merged++
+		}
+		if branch.Stale {
+			branches.Stale++
+		}
+		if branch.Diverged {
+			branches.Diverged++
+		}
+		row := BranchRow{Branch: branch}
+		files := branch.Files
+		if len(files) > maxBranchFiles {
+			files = files[:maxBranchFiles]
+		}
+		row.FileList = strings.Join(files, "\n")
+		if len(branch.Files) > maxBranchFiles {
+			row.FileList += "\n…"
+		}
+		branches.Rows = append(branches.Rows, row)
+	}
+	sortBranches(branches.Rows, func(row BranchRow) models.Branch { return row.Branch })
+	return branches
+}
+
+// sortBranches puts default branches first and the others by last commit, oldest first.
+func sortBranches[T any](rows []T, branch func(T) models.Branch) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := branch(rows[i]), branch(rows[j])
+		if a.Default != b.Default {
+			return a.Default
+		}
+		if !a.LastCommitDate.Equal(b.LastCommitDate) {
+			return a.LastCommitDate.Before(b.LastCommitDate)
+		}
+		if a.Repository != b.Repository {
+			return a.Repository < b.Repository
+		}
+		return a.Name < b.Name
+	})
+}
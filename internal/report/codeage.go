@@ -0,0 +1,86 @@
+package report
+
+import (
+	"path"
+	"sort"
+
+	"github.com/user/git-inquisitor-go/internal/models"
+)
+
+// maxAgeHeatmapDirectories bounds the rows of the directory code age heatmap; the
+// directories with the most lines are kept.
+const maxAgeHeatmapDirectories = 40
+
+// AgeHeatmapRow is a row of a code age heatmap: how the lines of a directory or
+// contributor are spread over the buckets of models.LineAgeBuckets.
+type AgeHeatmapRow struct {
+	Name   string
+	Lines  int
+	Shares []float64 // Fraction of Lines in each bucket
+}
+
+// CodeAge holds the code age heatmaps of the HTML report.
+type CodeAge struct {
+	Buckets      []string
+	Directories  []AgeHeatmapRow
+	Contributors []AgeHeatmapRow
+	// Truncated is set when only the largest directories are shown.
+	Truncated bool
+}
+
+// buildCodeAge aggregates the line ages of files by directory and takes those of the
+// contributors. Directories are sorted by path and contributors by active lines.
+func buildCodeAge(data *models.CollectedData) CodeAge {
+	codeAge := CodeAge{Buckets: models.LineAgeBuckets}
+
+	byDirectory := make(map[string]*models.AgeHistogram)
+	for filePath, file := range data.Files {
+		dir := path.Dir(filePath)
+		if byDirectory[dir] == nil {
+			byDirectory[dir] = &models.AgeHistogram{}
+		}
+		byDirectory[dir].Merge(file.LineAges)
+	}
+	for dir, ages := range byDirectory {
+		if row, ok := newAgeHeatmapRow(dir, *ages); ok {
+			codeAge.Directories = append(codeAge.Directories, row)
+		}
+	}
+	if len(codeAge.Directories) > maxAgeHeatmapDirectories {
+		sort.Slice(codeAge.Directories, func(i, j int) bool {
+			return codeAge.Directories[i].Lines > codeAge.Directories[j].Lines
+		})
+		codeAge.Directories = codeAge.Directories[:maxAgeHeatmapDirectories]
+		codeAge.Truncated = true
+	}
+	sort.Slice(codeAge.Directories, func(i, j int) bool {
+		return codeAge.Directories[i].Name < codeAge.Directories[j].Name
+	})
+
+	for name, contributor := range data.Contributors {
+		if row, ok := newAgeHeatmapRow(name, contributor.LineAges); ok {
+			codeAge.Contributors = append(codeAge.Contributors, row)
+		}
+	}
+	sort.Slice(codeAge.Contributors, func(i, j int) bool {
+		a, b := codeAge.Contributors[i], codeAge.Contributors[j]
+		if a.Lines != b.Lines {
+			return a.Lines > b.Lines
+		}
+		return a.Name < b.Name
+	})
+	return codeAge
+}
+
+// newAgeHeatmapRow returns the heatmap row of ages, or false if it has no lines.
+func newAgeHeatmapRow(name string, ages models.AgeHistogram) (AgeHeatmapRow, bool) {
+	total := ages.Total()
+	if total == 0 {
+		return AgeHeatmapRow{}, false
+	}
+	row := AgeHeatmapRow{Name: name, Lines: total}
+	for _, count := range ages.Counts() {
+		row.Shares = append(row.Shares, float64(count)/float64(total))
+	}
+	return row, true
+}
@@ -0,0 +1,106 @@
+package report
+
+import (
+	"html/template"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/user/git-inquisitor-go/internal/models"
+)
+
+// maxIssueRows bounds the issues listed in the HTML report.
+const maxIssueRows = 50
+
+// issueURLs maps the issue references of data to their tracker URLs. References
+// without a URL, or with one that is not HTTP(S), are left out and not linked.
+func issueURLs(data *models.CollectedData) map[string]string {
+	urls := make(map[string]string, len(data.Issues))
+	for _, issue := range data.Issues {
+		if strings.HasPrefix(issue.URL, "https://") || strings.HasPrefix(issue.URL, "http://") {
+			urls[issue.Key] = issue.URL
+		}
+	}
+	return urls
+}
+
+// topIssues returns the issues with the most commits, which data.Issues lists first.
+func topIssues(data *models.CollectedData) []models.IssueSummary {
+	if len(data.Issues) > maxIssueRows {
+		return data.Issues[:maxIssueRows]
+	}
+	return data.Issues
+}
+
+// linkIssues renders text with the references of refs that have a URL in urls turned
+// into links, and the linked references that do not appear in text (such as those in
+// a message body) appended. plain renders the rest of text and link a reference.
+func linkIssues(text string, refs []string, urls map[string]string, plain func(string) string, link func(ref, url string) string) string {
+	var linked []string
+	for _, ref := range refs {
+		if urls[ref] != "" {
+			linked = append(linked, ref)
+		}
+	}
+	if len(linked) == 0 {
+		return plain(text)
+	}
+	// Prefer the longest reference where several start at the same place, such as #12 over #1.
+	sort.Slice(linked, func(i, j int) bool { return len(linked[i]) > len(linked[j]) })
+
+	var out strings.Builder
+	found := make(map[string]bool)
+	start := 0
+	for i := 0; i < len(text); i++ {
+		for _, ref := range linked {
+			if !strings.HasPrefix(text[i:], ref) || !isReferenceBoundary(text, i, i+len(ref)) {
+				continue
+			}
+			out.WriteString(plain(text[start:i]))
+			out.WriteString(link(ref, urls[ref]))
+			found[ref] = true
+			i += len(ref) - 1
+			start = i + 1
+			break
+		}
+	}
+	out.WriteString(plain(text[start:]))
+	for _, ref := range refs {
+		if urls[ref] != "" && !found[ref] {
+			out.WriteString(" ")
+			out.WriteString(link(ref, urls[ref]))
+		}
+	}
+	return out.String()
+}
+
+// isReferenceBoundary reports whether text[start:end] is not part of a longer word: it
+// is not preceded by a word character, like #1 in x#1, nor continues one.
+func isReferenceBoundary(text string, start, end int) bool {
+	if start > 0 {
+		if before, _ := utf8.DecodeLastRuneInString(text[:start]); isWordRune(before) {
+			return false
+		}
+	}
+	if end < len(text) {
+		last, _ := utf8.DecodeLastRuneInString(text[:end])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if isWordRune(last) && isWordRune(after) {
+			return false
+		}
+	}
+	return true
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// htmlIssueLinks renders text as HTML with its issue references linked, see linkIssues.
+// Both text and the links are escaped.
+func htmlIssueLinks(text string, refs []string, urls map[string]string) template.HTML {
+	return template.HTML(linkIssues(text, refs, urls, template.HTMLEscapeString, func(ref, url string) string {
+		return `<a href="` + template.HTMLEscapeString(url) + `" target="_blank" rel="noopener">` + template.HTMLEscapeString(ref) + `</a>`
+	}))
+}
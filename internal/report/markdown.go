@@ -0,0 +1,157 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/user/git-inquisitor-go/internal/models"
+)
+
+// --- Markdown Report Adapter ---
+
+// MarkdownReportAdapter generates reports in Markdown format, for wikis, pull requests
+// and chat: a summary, the contributors, the work by issue and the history, with issue
+// references linked.
+type MarkdownReportAdapter struct {
+	reportBuf bytes.Buffer
+}
+
+// markdownEscaper escapes the characters with a meaning in Markdown table cells.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "|", `\|`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "<", `\<`, ">", `\>`,
+	"\r\n", " ", "\n", " ",
+)
+
+// markdownURLEscaper escapes the characters that would end a Markdown link destination.
+var markdownURLEscaper = strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29", "<", "%3C", ">", "%3E")
+
+// PrepareData renders data as Markdown.
+func (mra *MarkdownReportAdapter) PrepareData(data *models.CollectedData) error {
+	urls := issueURLs(data)
+	link := func(ref, url string) string {
+		return "[" + markdownEscaper.Replace(ref) + "](" + markdownURLEscaper.Replace(url) + ")"
+	}
+	var b bytes.Buffer
+
+	b.WriteString("# Git Inquisitor Report\n\n")
+	b.WriteString("| | |\n| --- | --- |\n")
+	repo := data.Metadata.Repo
+	if repo.URL != "" {
+		fmt.Fprintf(&b, "| Repository | %s |\n", markdownEscaper.Replace(repo.URL))
+	}
+	if repo.Branch != "" {
+		fmt.Fprintf(&b, "| Branch | %s |\n", markdownEscaper.Replace(repo.Branch))
+	}
+	if repo.Commit.SHA != "" {
+		fmt.Fprintf(&b, "| Commit | `%s` (%s) |\n", shortSha(repo.Commit.SHA), repo.Commit.Date.Format("2006-01-02"))
+	}
+	fmt.Fprintf(&b, "| Commits | %d |\n", len(data.History))
+	fmt.Fprintf(&b, "| Contributors | %d |\n", len(data.Contributors))
+	fmt.Fprintf(&b, "| Files | %d |\n", len(data.Files))
+	fmt.Fprintf(&b, "| Collected | %s |\n", data.Metadata.Collector.DateCollected.Format("2006-01-02 15:04:05 MST"))
+
+	if len(data.Repositories) > 0 {
+		b.WriteString("\n## Repositories\n\n")
+		b.WriteString("| Repository | Branch | Commits | Contributors | Files | Lines |\n| --- | --- | ---: | ---: | ---: | ---: |\n")
+		for _, summary := range data.Repositories {
+			fmt.Fprintf(&b, "| %s | %s | %d | %d | %d | %d |\n", markdownEscaper.Replace(summary.Name), markdownEscaper.Replace(summary.Branch),
+				summary.CommitCount, summary.Contributors, summary.Files, summary.TotalLines)
+		}
+	}
+
+	b.WriteString("\n## Contributors\n\n")
+	b.WriteString("| Contributor | Commits | Insertions | Deletions | Active Lines |\n| --- | ---: | ---: | ---: | ---: |\n")
+	names := make([]string, 0, len(data.Contributors))
+	for name := range data.Contributors {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := data.Contributors[names[i]], data.Contributors[names[j]]
+		if a.CommitCount != b.CommitCount {
+			return a.CommitCount > b.CommitCount
+		}
+		return names[i] < names[j]
+	})
+	for _, name := range names {
+		contributor := data.Contributors[name]
+		fmt.Fprintf(&b, "| %s | %d | %d | %d | %d |\n", markdownEscaper.Replace(name),
+			contributor.CommitCount, contributor.Insertions, contributor.Deletions, contributor.ActiveLines)
+	}
+
+	if len(data.Issues) > 0 {
+		b.WriteString("\n## Issues\n\n")
+		b.WriteString("| Issue | Commits | Insertions | Deletions | Files | Contributors | Last Commit |\n| --- | ---: | ---: | ---: | ---: | --- | --- |\n")
+		for _, issue := range data.Issues {
+			fmt.Fprintf(&b, "| %s | %d | %d | %d | %d | %s | %s |\n",
+				linkIssues(issue.Key, []string{issue.Key}, urls, markdownEscaper.Replace, link),
+				issue.Commits, issue.Insertions, issue.Deletions, len(issue.Files),
+				markdownEscaper.Replace(strings.Join(issue.Contributors, ", ")), issue.LastCommitDate.Format("2006-01-02"))
+		}
+	}
+
+	if data.Branches != nil && len(data.Branches.Branches) > 0 {
+		b.WriteString("\n## Branches\n\n")
+		if data.Branches.Default != "" {
+			fmt.Fprintf(&b, "Compared with `%s`.\n\n", data.Branches.Default)
+		}
+		b.WriteString("| Branch | Last Commit | Author | Ahead | Behind | Files | Status |\n| --- | --- | --- | ---: | ---: | ---: | --- |\n")
+		branches := make([]models.Branch, len(data.Branches.Branches))
+		copy(branches, data.Branches.Branches)
+		sortBranches(branches, func(branch models.Branch) models.Branch { return branch })
+		for _, branch := range branches {
+			name := branch.Name
+			if branch.Repository != "" {
+				name = branch.Repository + ": " + name
+			}
+			var status []string
+			if branch.Default {
+				status = append(status, "default")
+			} else if branch.Merged {
+				status = append(status, "merged")
+			}
+			if branch.Stale {
+				status = append(status, "stale")
+			}
+			if branch.Diverged {
+				status = append(status, "diverged")
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %d | %d | %d | %s |\n", markdownEscaper.Replace(name), branch.LastCommitDate.Format("2006-01-02"),
+				markdownEscaper.Replace(strings.Split(branch.LastCommitAuthor, " (")[0]), branch.Ahead, branch.Behind, len(branch.Files), strings.Join(status, ", "))
+		}
+	}
+
+	b.WriteString("\n## History\n\n")
+	b.WriteString("| Commit | Date | Contributor | Message | Insertions | Deletions |\n| --- | --- | --- | --- | ---: | ---: |\n")
+	history := make([]models.CommitHistoryItem, len(data.History))
+	copy(history, data.History)
+	sort.SliceStable(history, func(i, j int) bool { return history[i].Date.After(history[j].Date) })
+	for _, item := range history {
+		subject := strings.SplitN(item.Message, "\n", 2)[0]
+		fmt.Fprintf(&b, "| `%s` | %s | %s | %s | %d | %d |\n", shortSha(item.Commit), item.Date.Format("2006-01-02"),
+			markdownEscaper.Replace(strings.Split(item.Contributor, " (")[0]),
+			linkIssues(subject, item.Issues, urls, markdownEscaper.Replace, link), item.Insertions, item.Deletions)
+	}
+
+	mra.reportBuf = b
+	return nil
+}
+
+// shortSha abbreviates a commit hash to 8 characters.
+func shortSha(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
+
+// Write saves the Markdown report to the specified output file.
+func (mra *MarkdownReportAdapter) Write(outputFilePath string) error {
+	if err := os.MkdirAll(filepath.Dir(outputFilePath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for Markdown report file %s: %w", outputFilePath, err)
+	}
+	return os.WriteFile(outputFilePath, mra.reportBuf.Bytes(), 0600)
+}
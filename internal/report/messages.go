@@ -0,0 +1,74 @@
+package report
+
+import (
+	"sort"
+
+	"github.com/user/git-inquisitor-go/internal/models"
+)
+
+// maxViolationRows bounds the commits listed as breaking the commit message policy.
+const maxViolationRows = 50
+
+// MessageRow is a contributor or month in the commit message section.
+type MessageRow struct {
+	Name string
+	models.MessageStats
+}
+
+// TypeCount is the number of commits of a Conventional Commit type.
+type TypeCount struct {
+	Type    string
+	Commits int
+}
+
+// Messages holds the commit message section of the HTML report.
+type Messages struct {
+	*models.CommitMessages
+	// Contributors are sorted by commit count, Months chronologically.
+	Contributors []MessageRow
+	Months       []MessageRow
+	// Types are sorted by commit count.
+	Types []TypeCount
+	// TopViolations are the most recent violations.
+	TopViolations []models.MessageViolation
+}
+
+// buildMessages arranges data's commit message analysis for the report, or returns nil
+// if there is none.
+func buildMessages(data *models.CollectedData) *Messages {
+	if data.CommitMessages == nil {
+		return nil
+	}
+	messages := &Messages{CommitMessages: data.CommitMessages}
+	for name, stats := range data.CommitMessages.ByContributor {
+		messages.Contributors = append(messages.Contributors, MessageRow{Name: name, MessageStats: stats})
+	}
+	sort.Slice(messages.Contributors, func(i, j int) bool {
+		a, b := messages.Contributors[i], messages.Contributors[j]
+		if a.Commits != b.Commits {
+			return a.Commits > b.Commits
+		}
+		return a.Name < b.Name
+	})
+	for month, stats := range data.CommitMessages.ByMonth {
+		messages.Months = append(messages.Months, MessageRow{Name: month, MessageStats: stats})
+	}
+	sort.Slice(messages.Months, func(i, j int) bool {
+		return messages.Months[i].Name < messages.Months[j].Name
+	})
+	for commitType, commits := range data.CommitMessages.Overall.Types {
+		messages.Types = append(messages.Types, TypeCount{Type: commitType, Commits: commits})
+	}
+	sort.Slice(messages.Types, func(i, j int) bool {
+		a, b := messages.Types[i], messages.Types[j]
+		if a.Commits != b.Commits {
+			return a.Commits > b.Commits
+		}
+		return a.Type < b.Type
+	})
+	messages.TopViolations = data.CommitMessages.Violations
+	if len(messages.TopViolations) > maxViolationRows {
+		messages.TopViolations = messages.TopViolations[:maxViolationRows]
+	}
+	return messages
+}
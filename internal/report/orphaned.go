@@ -0,0 +1,57 @@
+package report
+
+import (
+	"sort"
+
+	"github.com/user/git-inquisitor-go/internal/models"
+)
+
+// maxOrphanedRows bounds the directories and files listed in the orphaned knowledge section.
+const maxOrphanedRows = 20
+
+// OrphanedRow is a directory or file in the orphaned knowledge section.
+type OrphanedRow struct {
+	Path string
+	models.OrphanedShare
+}
+
+// Orphaned holds the orphaned knowledge section of the HTML report.
+type Orphaned struct {
+	*models.OrphanedKnowledge
+	// TopDirectories and TopFiles are those with the most lines owned by inactive contributors.
+	TopDirectories []OrphanedRow
+	TopFiles       []OrphanedRow
+}
+
+// buildOrphaned ranks the directories and files of data's orphaned knowledge analysis,
+// or returns nil if there is none.
+func buildOrphaned(data *models.CollectedData) *Orphaned {
+	if data.OrphanedKnowledge == nil {
+		return nil
+	}
+	return &Orphaned{
+		OrphanedKnowledge: data.OrphanedKnowledge,
+		TopDirectories:    topOrphaned(data.OrphanedKnowledge.Directories),
+		TopFiles:          topOrphaned(data.OrphanedKnowledge.Files),
+	}
+}
+
+// topOrphaned returns the entries of shares with inactive lines, most inactive lines first.
+func topOrphaned(shares map[string]models.OrphanedShare) []OrphanedRow {
+	var rows []OrphanedRow
+	for path, share := range shares {
+		if share.InactiveLines > 0 {
+			rows = append(rows, OrphanedRow{Path: path, OrphanedShare: share})
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].InactiveLines != rows[j].InactiveLines {
+			return rows[i].InactiveLines > rows[j].InactiveLines
+		}
+		return rows[i].Path < rows[j].Path
+	})
+	if len(rows) > maxOrphanedRows {
+		rows = rows[:maxOrphanedRows]
+	}
+	return rows
+}
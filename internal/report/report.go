@@ -87,6 +87,8 @@ func (hra *HTMLReportAdapter) PrepareData(data *models.CollectedData) error {
 		hra.chartData = charts
 	}
 
+	urls := issueURLs(data)
+
 	// Define template functions (Go equivalent of Jinja filters/globals)
 	funcMap := template.FuncMap{
 		"ToUpper":    strings.ToUpper,
@@ -117,16 +119,20 @@ func (hra *HTMLReportAdapter) PrepareData(data *models.CollectedData) error {
 		"FormatDate": func(t time.Time) string {
 			return t.Format("2006-01-02")
 		},
-		"ShortSha": func(sha string) string {
-			if len(sha) > 8 {
-				return sha[:8]
-			}
-			return sha
+		"Percent": func(share float64) string {
+			return fmt.Sprintf("%.0f%%", share*100)
 		},
+		"ShortSha": shortSha,
 		"CommitterName": func(contributor string) string {
 			parts := strings.Split(contributor, " (")
 			return parts[0]
 		},
+		"LinkIssues": func(text string, refs []string) template.HTML {
+			return htmlIssueLinks(text, refs, urls)
+		},
+		"IssueLink": func(ref string) template.HTML {
+			return htmlIssueLinks(ref, []string{ref}, urls)
+		},
 		"CommitMsgShort": func(msg string) string {
 			lines := strings.Split(msg, "\n")
 			return lines[0] // First line as short message
@@ -179,11 +185,25 @@ func (hra *HTMLReportAdapter) PrepareData(data *models.CollectedData) error {
 	}
 
 	templateData := struct {
-		Data      *models.CollectedData
-		ChartData chart.HTMLChartData
+		Data        *models.CollectedData
+		ChartData   chart.HTMLChartData
+		CodeAge     CodeAge
+		Orphaned    *Orphaned
+		Sparklines  map[string]string
+		CommitTimes CommitTimes
+		Messages    *Messages
+		Issues      []models.IssueSummary
+		Branches    *Branches
 	}{
-		Data:      hra.rawDatarawData,
-		ChartData: hra.chartData,
+		Data:        hra.rawDatarawData,
+		ChartData:   hra.chartData,
+		CodeAge:     buildCodeAge(hra.rawDatarawData),
+		Orphaned:    buildOrphaned(hra.rawDatarawData),
+		Sparklines:  buildSparklines(hra.rawDatarawData.Contributors),
+		CommitTimes: buildCommitTimes(hra.rawDatarawData),
+		Messages:    buildMessages(hra.rawDatarawData),
+		Issues:      topIssues(hra.rawDatarawData),
+		Branches:    buildBranches(hra.rawDatarawData),
 	}
 
 	var buf bytes.Buffer
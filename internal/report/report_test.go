@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -37,6 +38,7 @@ func getTestCollectedData() *models.CollectedData {
 				Insertions:  10,
 				Deletions:   2,
 				ActiveLines: 8,
+				LineAges:    models.AgeHistogram{Under30Days: 8},
 			},
 		},
 		Files: map[string]models.FileData{
@@ -46,8 +48,43 @@ func getTestCollectedData() *models.CollectedData {
 				LinesByContributor: map[string]int{
 					"Test User": 8,
 				},
+				LineAges: models.AgeHistogram{Under30Days: 8},
 			},
 		},
+		OrphanedKnowledge: &models.OrphanedKnowledge{
+			InactiveAfter:        "6mo",
+			AsOf:                 time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+			InactiveContributors: []string{"Test User"},
+			Repository:           models.OrphanedShare{TotalLines: 8, InactiveLines: 8, Percentage: 100},
+			Directories:          map[string]models.OrphanedShare{".": {TotalLines: 8, InactiveLines: 8, Percentage: 100}},
+			Files:                map[string]models.OrphanedShare{"main.go": {TotalLines: 8, InactiveLines: 8, Percentage: 100}},
+		},
+		Releases: []models.Release{
+			{Name: "v1.0", Commit: "abcdef1234567890", Date: time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC), Commits: 1, Contributors: 1, Insertions: 10, Deletions: 2, FilesTouched: 1},
+		},
+		Branches: &models.BranchLandscape{
+			Default:    "main",
+			StaleAfter: "90d",
+			Branches: []models.Branch{
+				{Name: "main", Default: true, Merged: true, Commit: "abcdef1234567890", LastCommitDate: time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC),
+					LastCommitAuthor: "Test User (test@example.com)"},
+				{Name: "origin/spike", Remote: true, Commit: "1234567890abcdef", LastCommitDate: time.Date(2023, 6, 1, 11, 0, 0, 0, time.UTC),
+					LastCommitAuthor: "Test User (test@example.com)", Ahead: 3, Behind: 1, Files: []string{"spike.go"}, Stale: true},
+			},
+		},
+		CommitMessages: &models.CommitMessages{
+			Policy:        models.MessagePolicy{MaxSubjectLength: 10},
+			Overall:       models.MessageStats{Commits: 1, Imperative: 0, Violations: 1, AverageSubjectLength: 14},
+			ByContributor: map[string]models.MessageStats{"Test User": {Commits: 1, Violations: 1, AverageSubjectLength: 14}},
+			ByMonth:       map[string]models.MessageStats{"2024-01": {Commits: 1, Violations: 1, AverageSubjectLength: 14}},
+			Violations: []models.MessageViolation{{
+				Commit:      "abcdef1234567890",
+				Contributor: "Test User (test@example.com)",
+				Date:        time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC),
+				Subject:     "Initial commit",
+				Problems:    []string{"subject is 14 characters long (max 10)"},
+			}},
+		},
 		History: []models.CommitHistoryItem{
 			{
 				Commit:      "abcdef1234567890",
@@ -194,6 +231,21 @@ func TestHTMLReportAdapter_TemplateFunctions(t *testing.T) {
 	if !strings.Contains(adapter.reportBuf.String(), data.Metadata.Repo.Commit.SHA) {
 		t.Errorf("HTML report does not contain expected SHA %s", data.Metadata.Repo.Commit.SHA)
 	}
+	if !strings.Contains(adapter.reportBuf.String(), "Line Age by Directory") {
+		t.Error("HTML report does not contain the code age heatmaps")
+	}
+	if !strings.Contains(adapter.reportBuf.String(), "Orphaned Knowledge") {
+		t.Error("HTML report does not contain the orphaned knowledge section")
+	}
+	if !strings.Contains(adapter.reportBuf.String(), "Release Timeline") {
+		t.Error("HTML report does not contain the release timeline")
+	}
+	if !strings.Contains(adapter.reportBuf.String(), "Branches by Last Commit") {
+		t.Error("HTML report does not contain the branch section")
+	}
+	if !strings.Contains(adapter.reportBuf.String(), "subject is 14 characters long (max 10)") {
+		t.Error("HTML report does not list the commit message policy violations")
+	}
 
 }
 
@@ -250,3 +302,143 @@ func TestHTMLReportAdapter_Write(t *testing.T) {
 	t.Log("Skipping chart content check as chart import was removed.")
 
 }
+
+func TestBuildCodeAge(t *testing.T) {
+	data := &models.CollectedData{
+		Files: map[string]models.FileData{
+			"cmd/main.go":     {LineAges: models.AgeHistogram{Under30Days: 1, Older: 3}},
+			"cmd/util.go":     {LineAges: models.AgeHistogram{Older: 4}},
+			"README.md":       {LineAges: models.AgeHistogram{Under1Year: 2}},
+			"empty/empty.txt": {},
+		},
+		Contributors: map[string]models.Contributor{
+			"Alice": {LineAges: models.AgeHistogram{Older: 7}},
+			"Bob":   {LineAges: models.AgeHistogram{Under30Days: 1, Under1Year: 2}},
+			"Carol": {},
+		},
+	}
+	codeAge := buildCodeAge(data)
+
+	if len(codeAge.Directories) != 2 || codeAge.Directories[0].Name != "." || codeAge.Directories[1].Name != "cmd" {
+		t.Fatalf("Directories = %+v, want . and cmd", codeAge.Directories)
+	}
+	cmd := codeAge.Directories[1]
+	if cmd.Lines != 8 || cmd.Shares[0] != 0.125 || cmd.Shares[4] != 0.875 {
+		t.Errorf("cmd row = %+v, want 8 lines, 1/8 under 30 days and 7/8 older", cmd)
+	}
+	if len(codeAge.Contributors) != 2 || codeAge.Contributors[0].Name != "Alice" || codeAge.Contributors[1].Name != "Bob" {
+		t.Errorf("Contributors = %+v, want Alice then Bob", codeAge.Contributors)
+	}
+	if len(codeAge.Buckets) != len(cmd.Shares) {
+		t.Errorf("%d buckets for %d shares", len(codeAge.Buckets), len(cmd.Shares))
+	}
+}
+
+func TestBuildSparklines(t *testing.T) {
+	contributors := map[string]models.Contributor{
+		"Alice": {MonthlyCommits: map[string]int{"2024-01": 2, "2024-03": 1}},
+		"Bob":   {MonthlyCommits: map[string]int{"2024-02": 4}},
+		"Carol": {},
+	}
+	sparklines := buildSparklines(contributors)
+
+	if want := "0.0,1.0 50.0,19.0 100.0,10.0"; sparklines["Alice"] != want {
+		t.Errorf("Alice sparkline = %q, want %q", sparklines["Alice"], want)
+	}
+	if want := "0.0,19.0 50.0,1.0 100.0,19.0"; sparklines["Bob"] != want {
+		t.Errorf("Bob sparkline = %q, want %q", sparklines["Bob"], want)
+	}
+	if _, ok := sparklines["Carol"]; ok {
+		t.Error("Carol, without commits, has a sparkline")
+	}
+}
+
+func TestBuildCommitTimes(t *testing.T) {
+	var alice, bob, repo models.CommitTimes
+	for _, date := range []string{"2024-01-29T10:00:00+02:00", "2024-01-29T22:00:00+02:00", "2024-01-30T11:00:00-05:00"} {
+		when, _ := time.Parse(time.RFC3339, date)
+		alice.Add(when)
+		repo.Add(when)
+	}
+	when, _ := time.Parse(time.RFC3339, "2024-01-27T11:00:00-05:00") // A Saturday
+	bob.Add(when)
+	repo.Add(when)
+	data := &models.CollectedData{
+		Contributors: map[string]models.Contributor{
+			"Alice": {CommitCount: 3, CommitTimes: alice},
+			"Bob":   {CommitCount: 1, CommitTimes: bob},
+			"Carol": {CommitCount: 1},
+		},
+		CommitTimes: repo,
+	}
+
+	commitTimes := buildCommitTimes(data)
+	var names []string
+	for _, series := range commitTimes.Series {
+		names = append(names, series.Name)
+	}
+	if want := []string{"All contributors", "Alice", "Bob"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("Series = %v, want %v", names, want)
+	}
+	if got := commitTimes.Series[1].AfterHours; got != 1.0/3 {
+		t.Errorf("Alice AfterHours = %v, want 1/3", got)
+	}
+	if got := commitTimes.Series[0].AfterHours; got != 0.5 {
+		t.Errorf("repository AfterHours = %v, want 0.5", got)
+	}
+	want := []TimeZoneRow{{Zone: "-05:00", Contributors: 1, Commits: 2}, {Zone: "+02:00", Contributors: 1, Commits: 2}}
+	if !reflect.DeepEqual(commitTimes.TimeZones, want) {
+		t.Errorf("TimeZones = %+v, want %+v", commitTimes.TimeZones, want)
+	}
+}
+
+func TestLinkIssues(t *testing.T) {
+	urls := map[string]string{"#1": "https://example.com/1", "#12": "https://example.com/12", "OPS-7": "https://example.com/OPS-7"}
+	link := func(ref, url string) string { return "[" + ref + "](" + url + ")" }
+	plain := func(text string) string { return text }
+
+	got := linkIssues("Fix #12 and #1, not x#1 (OPS-7)", []string{"#12", "#1", "OPS-7", "#99"}, urls, plain, link)
+	want := "Fix [#12](https://example.com/12) and [#1](https://example.com/1), not x#1 ([OPS-7](https://example.com/OPS-7))"
+	if got != want {
+		t.Errorf("linkIssues() = %q, want %q", got, want)
+	}
+
+	// References cut off from a truncated subject, or in the body, are appended.
+	if got, want := linkIssues("Fix the...", []string{"#12"}, urls, plain, link), "Fix the... [#12](https://example.com/12)"; got != want {
+		t.Errorf("linkIssues() = %q, want %q", got, want)
+	}
+
+	if got, want := htmlIssueLinks("<b> #1", []string{"#1"}, urls), `&lt;b&gt; <a href="https://example.com/1" target="_blank" rel="noopener">#1</a>`; string(got) != want {
+		t.Errorf("htmlIssueLinks() = %q, want %q", got, want)
+	}
+}
+
+func TestMarkdownReportAdapter(t *testing.T) {
+	data := getTestCollectedData()
+	data.History[0].Message = "Initial commit | setup (#3)"
+	data.History[0].Issues = []string{"#3"}
+	data.Issues = []models.IssueSummary{{Key: "#3", URL: "https://github.com/acme/widgets/issues/3", Commits: 1, Contributors: []string{"Test User"}}}
+
+	adapter := &MarkdownReportAdapter{}
+	if err := adapter.PrepareData(data); err != nil {
+		t.Fatalf("PrepareData() error = %v", err)
+	}
+	outputFile := filepath.Join(t.TempDir(), "report.md")
+	if err := adapter.Write(outputFile); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read Markdown report: %v", err)
+	}
+	for _, want := range []string{
+		"| Test User | 1 | 10 | 2 | 8 |",
+		"| [#3](https://github.com/acme/widgets/issues/3) | 1 |",
+		`| Initial commit \| setup ([#3](https://github.com/acme/widgets/issues/3)) |`,
+		"| main | 2024-01-01 | Test User | 0 | 0 | 0 | default |\n| origin/spike | 2023-06-01 | Test User | 3 | 1 | 1 | stale |",
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("Markdown report does not contain %q:\n%s", want, content)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package store
+
+import (
+	"archive/zip"
+	"encoding/gob"
+	"fmt"
+	"os"
+
+	"github.com/user/git-inquisitor-go/internal/models"
+)
+
+// LegacySuffix is the file name suffix of caches written before the store existed:
+// a zip archive holding a single gob-encoded models.CollectedData named data.gob.
+const LegacySuffix = ".zip.gob"
+
+// ReadLegacy decodes a legacy gob-in-zip cache file.
+func ReadLegacy(path string) (*models.CollectedData, error) {
+	zipReader, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open legacy cache file %s: %w", path, err)
+	}
+	defer zipReader.Close()
+
+	if len(zipReader.File) == 0 || zipReader.File[0].Name != "data.gob" {
+		return nil, fmt.Errorf("invalid legacy cache file format: data.gob not found")
+	}
+
+	dataFile, err := zipReader.File[0].Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open data.gob from zip: %w", err)
+	}
+	defer dataFile.Close()
+
+	var data models.CollectedData
+	if err := gob.NewDecoder(dataFile).Decode(&data); err != nil {
+		return nil, fmt.Errorf("failed to gob-decode data: %w", err)
+	}
+	return &data, nil
+}
+
+// MigrateLegacy converts the legacy cache file at legacyPath into a store in dir
+// and removes the legacy file once the store is complete. upgrade, if not nil, is
+// called on the decoded data before it is written.
+func MigrateLegacy(legacyPath, dir string, upgrade func(*models.CollectedData)) (*models.CollectedData, error) {
+	data, err := ReadLegacy(legacyPath)
+	if err != nil {
+		return nil, err
+	}
+	if upgrade != nil {
+		upgrade(data)
+	}
+	if err := Write(dir, data); err != nil {
+		return nil, fmt.Errorf("failed to migrate legacy cache %s: %w", legacyPath, err)
+	}
+	if err := os.Remove(legacyPath); err != nil {
+		return nil, fmt.Errorf("failed to remove migrated legacy cache %s: %w", legacyPath, err)
+	}
+	return data, nil
+}
@@ -0,0 +1,108 @@
+package store
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/user/git-inquisitor-go/internal/models"
+)
+
+// HistorySpool holds history items on disk instead of in memory, in the format of the
+// history section, for collections whose history does not fit in memory.
+// WriteWithHistory copies it into a store.
+type HistorySpool struct {
+	file     *os.File
+	buffered *bufio.Writer
+	encoder  *json.Encoder
+	records  int
+}
+
+// NewHistorySpool creates an empty spool file in dir. Its name is hidden, so cache
+// listings ignore it; Remove deletes it.
+func NewHistorySpool(dir string) (*HistorySpool, error) {
+	file, err := os.CreateTemp(dir, ".history-*.jsonl")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create history spool in %s: %w", dir, err)
+	}
+	buffered := bufio.NewWriter(file)
+	return &HistorySpool{file: file, buffered: buffered, encoder: json.NewEncoder(buffered)}, nil
+}
+
+// Append adds item after the items appended before.
+func (s *HistorySpool) Append(item models.CommitHistoryItem) error {
+	if err := s.encoder.Encode(item); err != nil {
+		return fmt.Errorf("failed to write to history spool %s: %w", s.file.Name(), err)
+	}
+	s.records++
+	return nil
+}
+
+// Records returns the number of items appended.
+func (s *HistorySpool) Records() int {
+	return s.records
+}
+
+// Each calls fn for every item in the spool, in the order they were appended.
+func (s *HistorySpool) Each(fn func(item models.CommitHistoryItem) error) error {
+	if err := s.buffered.Flush(); err != nil {
+		return fmt.Errorf("failed to write to history spool %s: %w", s.file.Name(), err)
+	}
+	return readLines(s.file.Name(), SectionHistory, func(decode func(any) error) error {
+		var item models.CommitHistoryItem
+		if err := decode(&item); err != nil {
+			return err
+		}
+		return fn(item)
+	})
+}
+
+// copyTo writes the spooled items to path.
+func (s *HistorySpool) copyTo(path string) error {
+	if err := s.buffered.Flush(); err != nil {
+		return fmt.Errorf("failed to write to history spool %s: %w", s.file.Name(), err)
+	}
+	if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	target, err := os.Create(path) // #nosec G304 -- path inside the store directory
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(target, s.file); err != nil {
+		target.Close()
+		return err
+	}
+	if _, err := s.file.Seek(0, io.SeekEnd); err != nil { // Further appends go to the end again
+		target.Close()
+		return err
+	}
+	return target.Close()
+}
+
+// Remove closes and deletes the spool file.
+func (s *HistorySpool) Remove() error {
+	s.file.Close()
+	if err := os.Remove(s.file.Name()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove history spool %s: %w", s.file.Name(), err)
+	}
+	return nil
+}
+
+// WriteWithHistory is Write for data whose history is held in spool rather than in
+// data.History, which is ignored.
+func WriteWithHistory(dir string, data *models.CollectedData, spool *HistorySpool) error {
+	return write(dir, data, spool)
+}
+
+// writeSpooledHistory writes the history section of a store from spool.
+func writeSpooledHistory(dir string, spool *HistorySpool) (int, error) {
+	path := filepath.Join(dir, sectionFile(SectionHistory))
+	if err := spool.copyTo(path); err != nil {
+		return 0, fmt.Errorf("failed to write %s section: %w", SectionHistory, err)
+	}
+	return spool.Records(), nil
+}
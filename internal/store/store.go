@@ -0,0 +1,380 @@
+// Package store persists collected data as a versioned, segmented on-disk store.
+//
+// A store is a directory holding a manifest, a core document and one JSON Lines file
+// per section:
+//
+//	manifest.json       format name, format version and the record count of every section
+//	core.json           everything except the sections (metadata, trend, ...)
+//	history.jsonl       one models.CommitHistoryItem per line, oldest first
+//	files.jsonl         one {"path": ..., "data": models.FileData} per line
+//	contributors.jsonl  one {"name": ..., "data": models.Contributor} per line
+//
+// Sections are written and read record by record, so they can be streamed or loaded
+// individually, and the files are plain JSON for use by other tools.
+package store
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/user/git-inquisitor-go/internal/models"
+)
+
+const (
+	// FormatName identifies a git-inquisitor store in its manifest.
+	FormatName = "git-inquisitor-store"
+	// FormatVersion is the store layout version written by this package.
+	// Stores with a newer version are rejected by Read.
+	FormatVersion = 1
+
+	ManifestFile = "manifest.json"
+	CoreFile     = "core.json"
+
+	SectionHistory      = "history"
+	SectionFiles        = "files"
+	SectionContributors = "contributors"
+)
+
+// ErrUnsupportedVersion is returned when a store was written by a newer format version.
+var ErrUnsupportedVersion = errors.New("unsupported store format version")
+
+// Manifest describes the layout of a store.
+type Manifest struct {
+	Format   string                 `json:"format"`
+	Version  int                    `json:"version"`
+	Created  time.Time              `json:"created"`
+	Sections map[string]SectionInfo `json:"sections"`
+}
+
+// SectionInfo describes one section file of a store.
+type SectionInfo struct {
+	File    string `json:"file"`
+	Records int    `json:"records"`
+}
+
+// fileRecord is a line of the files section.
+type fileRecord struct {
+	Path string          `json:"path"`
+	Data models.FileData `json:"data"`
+}
+
+// contributorRecord is a line of the contributors section.
+type contributorRecord struct {
+	Name string             `json:"name"`
+	Data models.Contributor `json:"data"`
+}
+
+// sectionFile returns the file name of a section.
+func sectionFile(section string) string {
+	return section + ".jsonl"
+}
+
+// Exists reports whether dir holds a store.
+func Exists(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ManifestFile))
+	return err == nil
+}
+
+// Write stores data in dir, replacing any previous store in it. The store is built
+// in a temporary sibling directory and renamed into place, so readers never observe
+// a partially written store. Files and contributors are written in sorted order so
+// identical data produces identical files.
+func Write(dir string, data *models.CollectedData) error {
+	return write(dir, data, nil)
+}
+
+// write implements Write and WriteWithHistory; history comes from spool unless it is nil.
+func write(dir string, data *models.CollectedData, spool *HistorySpool) error {
+	parent := filepath.Dir(dir)
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return fmt.Errorf("failed to create store directory %s: %w", parent, err)
+	}
+	tempDir, err := os.MkdirTemp(parent, "."+filepath.Base(dir)+".tmp-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary store directory in %s: %w", parent, err)
+	}
+	defer os.RemoveAll(tempDir) // No-op once renamed into place
+
+	if err := writeStore(tempDir, data, spool); err != nil {
+		return err
+	}
+	return replaceDir(tempDir, dir)
+}
+
+// writeStore writes every file of a store into the existing directory dir,
+// taking the history from spool unless it is nil.
+func writeStore(dir string, data *models.CollectedData, spool *HistorySpool) error {
+	manifest := Manifest{
+		Format:   FormatName,
+		Version:  FormatVersion,
+		Created:  time.Now().UTC(),
+		Sections: make(map[string]SectionInfo),
+	}
+
+	var records int
+	var err error
+	if spool != nil {
+		records, err = writeSpooledHistory(dir, spool)
+	} else {
+		records, err = writeSection(dir, SectionHistory, func(enc *json.Encoder) (int, error) {
+			for _, item := range data.History {
+				if err := enc.Encode(item); err != nil {
+					return 0, err
+				}
+			}
+			return len(data.History), nil
+		})
+	}
+	if err != nil {
+		return err
+	}
+	manifest.Sections[SectionHistory] = SectionInfo{File: sectionFile(SectionHistory), Records: records}
+
+	records, err = writeSection(dir, SectionFiles, func(enc *json.Encoder) (int, error) {
+		for _, path := range sortedKeys(data.Files) {
+			if err := enc.Encode(fileRecord{Path: path, Data: data.Files[path]}); err != nil {
+				return 0, err
+			}
+		}
+		return len(data.Files), nil
+	})
+	if err != nil {
+		return err
+	}
+	manifest.Sections[SectionFiles] = SectionInfo{File: sectionFile(SectionFiles), Records: records}
+
+	records, err = writeSection(dir, SectionContributors, func(enc *json.Encoder) (int, error) {
+		for _, name := range sortedKeys(data.Contributors) {
+			if err := enc.Encode(contributorRecord{Name: name, Data: data.Contributors[name]}); err != nil {
+				return 0, err
+			}
+		}
+		return len(data.Contributors), nil
+	})
+	if err != nil {
+		return err
+	}
+	manifest.Sections[SectionContributors] = SectionInfo{File: sectionFile(SectionContributors), Records: records}
+
+	// The core document carries every field that is not a section, including ones added later.
+	core := *data
+	core.History = nil
+	core.Files = nil
+	core.Contributors = nil
+	if err := writeJSON(filepath.Join(dir, CoreFile), core); err != nil {
+		return err
+	}
+
+	// The manifest goes last: a store without one is incomplete and ignored.
+	return writeJSON(filepath.Join(dir, ManifestFile), manifest)
+}
+
+// replaceDir renames src to dst. An existing dst is moved aside (to a hidden name) first
+// and removed afterwards, since directories cannot be renamed over non-empty ones.
+func replaceDir(src, dst string) error {
+	var old string
+	if _, err := os.Stat(dst); err == nil {
+		old = filepath.Join(filepath.Dir(dst), fmt.Sprintf(".%s.old-%d", filepath.Base(dst), time.Now().UnixNano()))
+		if err := os.Rename(dst, old); err != nil {
+			return fmt.Errorf("failed to move previous store %s aside: %w", dst, err)
+		}
+	}
+	if err := os.Rename(src, dst); err != nil {
+		return fmt.Errorf("failed to move store into place at %s: %w", dst, err)
+	}
+	if old != "" {
+		if err := os.RemoveAll(old); err != nil {
+			return fmt.Errorf("failed to remove previous store %s: %w", old, err)
+		}
+	}
+	return nil
+}
+
+// writeSection creates the JSON Lines file of a section and fills it using write.
+func writeSection(dir, section string, write func(enc *json.Encoder) (int, error)) (int, error) {
+	path := filepath.Join(dir, sectionFile(section))
+	file, err := os.Create(path) // #nosec G304 -- path inside the store directory
+	if err != nil {
+		return 0, fmt.Errorf("failed to create %s section %s: %w", section, path, err)
+	}
+	defer file.Close()
+
+	buffered := bufio.NewWriter(file)
+	records, err := write(json.NewEncoder(buffered))
+	if err != nil {
+		return 0, fmt.Errorf("failed to write %s section: %w", section, err)
+	}
+	if err := buffered.Flush(); err != nil {
+		return 0, fmt.Errorf("failed to write %s section: %w", section, err)
+	}
+	if err := file.Close(); err != nil {
+		return 0, fmt.Errorf("failed to close %s section: %w", section, err)
+	}
+	return records, nil
+}
+
+// writeJSON writes value as an indented JSON document.
+func writeJSON(path string, value any) error {
+	content, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", filepath.Base(path), err)
+	}
+	if err := os.WriteFile(path, append(content, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// ReadManifest reads and validates the manifest of the store in dir.
+func ReadManifest(dir string) (*Manifest, error) {
+	path := filepath.Join(dir, ManifestFile)
+	content, err := os.ReadFile(path) // #nosec G304 -- path inside the store directory
+	if err != nil {
+		return nil, fmt.Errorf("failed to read store manifest %s: %w", path, err)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to decode store manifest %s: %w", path, err)
+	}
+	if manifest.Format != FormatName {
+		return nil, fmt.Errorf("%s is not a %s manifest", path, FormatName)
+	}
+	if manifest.Version > FormatVersion {
+		return nil, fmt.Errorf("%w: %d (supported: %d)", ErrUnsupportedVersion, manifest.Version, FormatVersion)
+	}
+	return &manifest, nil
+}
+
+// ReadCore reads everything except the sections from the store in dir.
+// The section fields of the result are empty but non-nil.
+func ReadCore(dir string) (*models.CollectedData, error) {
+	if _, err := ReadManifest(dir); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, CoreFile)
+	content, err := os.ReadFile(path) // #nosec G304 -- path inside the store directory
+	if err != nil {
+		return nil, fmt.Errorf("failed to read store core %s: %w", path, err)
+	}
+	var data models.CollectedData
+	if err := json.Unmarshal(content, &data); err != nil {
+		return nil, fmt.Errorf("failed to decode store core %s: %w", path, err)
+	}
+	data.History = []models.CommitHistoryItem{}
+	data.Files = make(map[string]models.FileData)
+	data.Contributors = make(map[string]models.Contributor)
+	return &data, nil
+}
+
+// Read loads the complete store in dir.
+func Read(dir string) (*models.CollectedData, error) {
+	data, err := ReadCore(dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := EachHistory(dir, func(item models.CommitHistoryItem) error {
+		data.History = append(data.History, item)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	if err := EachFile(dir, func(path string, file models.FileData) error {
+		data.Files[path] = file
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	if err := EachContributor(dir, func(name string, contributor models.Contributor) error {
+		data.Contributors[name] = contributor
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// EachHistory calls fn for every history item of the store in dir, oldest first.
+func EachHistory(dir string, fn func(item models.CommitHistoryItem) error) error {
+	return readSection(dir, SectionHistory, func(decode func(any) error) error {
+		var item models.CommitHistoryItem
+		if err := decode(&item); err != nil {
+			return err
+		}
+		return fn(item)
+	})
+}
+
+// EachFile calls fn for every file of the store in dir, in path order.
+func EachFile(dir string, fn func(path string, file models.FileData) error) error {
+	return readSection(dir, SectionFiles, func(decode func(any) error) error {
+		var record fileRecord
+		if err := decode(&record); err != nil {
+			return err
+		}
+		return fn(record.Path, record.Data)
+	})
+}
+
+// EachContributor calls fn for every contributor of the store in dir, in name order.
+func EachContributor(dir string, fn func(name string, contributor models.Contributor) error) error {
+	return readSection(dir, SectionContributors, func(decode func(any) error) error {
+		var record contributorRecord
+		if err := decode(&record); err != nil {
+			return err
+		}
+		return fn(record.Name, record.Data)
+	})
+}
+
+// readSection streams the records of a section to handle, one line at a time.
+func readSection(dir, section string, handle func(decode func(any) error) error) error {
+	return readLines(filepath.Join(dir, sectionFile(section)), section, handle)
+}
+
+// readLines streams the JSON Lines records of the section file at path to handle.
+func readLines(path, section string, handle func(decode func(any) error) error) error {
+	file, err := os.Open(path) // #nosec G304 -- path inside the store directory
+	if err != nil {
+		return fmt.Errorf("failed to open %s section %s: %w", section, path, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024) // Records with many contributors can be long
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		decode := func(value any) error {
+			if err := json.Unmarshal(scanner.Bytes(), value); err != nil {
+				return fmt.Errorf("failed to decode %s record at %s:%d: %w", section, path, line, err)
+			}
+			return nil
+		}
+		if err := handle(decode); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read %s section %s: %w", section, path, err)
+	}
+	return nil
+}
+
+// sortedKeys returns the keys of m in ascending order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
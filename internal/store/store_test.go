@@ -0,0 +1,227 @@
+package store
+
+import (
+	"archive/zip"
+	"encoding/gob"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/git-inquisitor-go/internal/models"
+)
+
+func testData() *models.CollectedData {
+	day := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	return &models.CollectedData{
+		Metadata: models.Metadata{
+			Collector: models.CollectorMetadata{InquisitorVersion: "test", DateCollected: day},
+			Repo:      models.RepoMetadata{Commit: models.CommitDetails{SHA: "abc", Date: day}},
+		},
+		Contributors: map[string]models.Contributor{
+			"Jane": {Identities: []string{"jane@example.com"}, CommitCount: 2, ActiveLines: 3},
+			"Bob":  {Identities: []string{"bob@example.com"}, CommitCount: 1, ActiveLines: 1},
+		},
+		Files: map[string]models.FileData{
+			"b.go": {TotalLines: 1, LinesByContributor: map[string]int{"Bob": 1}},
+			"a.go": {TotalLines: 3, LinesByContributor: map[string]int{"Jane": 3}},
+		},
+		History: []models.CommitHistoryItem{
+			{Commit: "c1", Contributor: "Jane (jane@example.com)", Date: day, Insertions: 3},
+			{Commit: "c2", Contributor: "Bob (bob@example.com)", Date: day.Add(time.Hour), Insertions: 1},
+		},
+		Trend: []models.TrendPoint{{Label: "HEAD", Commit: "c2", Date: day, TotalLines: 4}},
+	}
+}
+
+func TestWriteRead_RoundTrip(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "store")
+	data := testData()
+	if err := Write(dir, data); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if !Exists(dir) {
+		t.Fatal("Exists() = false after Write()")
+	}
+
+	loaded, err := Read(dir)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if !reflect.DeepEqual(loaded, data) {
+		t.Errorf("Read() = %+v, want %+v", loaded, data)
+	}
+
+	manifest, err := ReadManifest(dir)
+	if err != nil {
+		t.Fatalf("ReadManifest() error = %v", err)
+	}
+	if manifest.Version != FormatVersion || manifest.Sections[SectionHistory].Records != 2 {
+		t.Errorf("ReadManifest() = %+v, want version %d with 2 history records", manifest, FormatVersion)
+	}
+
+	// Sections are plain JSON Lines, sorted for stable output.
+	content, err := os.ReadFile(filepath.Join(dir, "files.jsonl"))
+	if err != nil {
+		t.Fatalf("Failed to read files section: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], `{"path":"a.go"`) {
+		t.Errorf("files.jsonl = %q, want a.go first", content)
+	}
+}
+
+func TestWriteWithHistory_Spool(t *testing.T) {
+	tempDir := t.TempDir()
+	data := testData()
+	spool, err := NewHistorySpool(tempDir)
+	if err != nil {
+		t.Fatalf("NewHistorySpool() error = %v", err)
+	}
+	defer spool.Remove()
+	for _, item := range data.History {
+		if err := spool.Append(item); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	dir := filepath.Join(tempDir, "store")
+	spooled := *data
+	spooled.History = nil // Taken from the spool
+	if err := WriteWithHistory(dir, &spooled, spool); err != nil {
+		t.Fatalf("WriteWithHistory() error = %v", err)
+	}
+	loaded, err := Read(dir)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if !reflect.DeepEqual(loaded, data) {
+		t.Errorf("Read() = %+v, want %+v", loaded, data)
+	}
+
+	// The spool remains usable after being copied into the store.
+	if err := spool.Append(models.CommitHistoryItem{Commit: "c3"}); err != nil {
+		t.Fatalf("Append() after write error = %v", err)
+	}
+	var commits []string
+	if err := spool.Each(func(item models.CommitHistoryItem) error {
+		commits = append(commits, item.Commit)
+		return nil
+	}); err != nil {
+		t.Fatalf("Each() error = %v", err)
+	}
+	if strings.Join(commits, ",") != "c1,c2,c3" {
+		t.Errorf("Each() commits = %v, want c1,c2,c3", commits)
+	}
+}
+
+func TestWrite_ReplacesExistingStore(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "store")
+	if err := Write(dir, testData()); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	updated := testData()
+	updated.History = updated.History[:1]
+	if err := Write(dir, updated); err != nil {
+		t.Fatalf("second Write() error = %v", err)
+	}
+
+	loaded, err := Read(dir)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(loaded.History) != 1 {
+		t.Errorf("Read() history length = %d, want 1 after replacement", len(loaded.History))
+	}
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		t.Fatalf("Failed to read dir: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Directory contains %d entries, want only the store (no temporary leftovers)", len(entries))
+	}
+}
+
+func TestReadCore_SkipsSections(t *testing.T) {
+	dir := t.TempDir()
+	if err := Write(dir, testData()); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	core, err := ReadCore(dir)
+	if err != nil {
+		t.Fatalf("ReadCore() error = %v", err)
+	}
+	if core.Metadata.Repo.Commit.SHA != "abc" || len(core.Trend) != 1 {
+		t.Errorf("ReadCore() metadata = %+v, trend = %+v", core.Metadata, core.Trend)
+	}
+	if len(core.History) != 0 || len(core.Files) != 0 || len(core.Contributors) != 0 {
+		t.Errorf("ReadCore() loaded sections: %+v", core)
+	}
+
+	var commits []string
+	if err := EachHistory(dir, func(item models.CommitHistoryItem) error {
+		commits = append(commits, item.Commit)
+		return nil
+	}); err != nil {
+		t.Fatalf("EachHistory() error = %v", err)
+	}
+	if !reflect.DeepEqual(commits, []string{"c1", "c2"}) {
+		t.Errorf("EachHistory() commits = %v, want [c1 c2]", commits)
+	}
+}
+
+func TestReadManifest_NewerVersion(t *testing.T) {
+	dir := t.TempDir()
+	if err := Write(dir, testData()); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	manifest := `{"format":"git-inquisitor-store","version":99,"sections":{}}`
+	if err := os.WriteFile(filepath.Join(dir, ManifestFile), []byte(manifest), 0600); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+	if _, err := Read(dir); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("Read() error = %v, want ErrUnsupportedVersion", err)
+	}
+}
+
+func TestMigrateLegacy(t *testing.T) {
+	root := t.TempDir()
+	legacyPath := filepath.Join(root, "abc"+LegacySuffix)
+	data := testData()
+
+	file, err := os.Create(legacyPath)
+	if err != nil {
+		t.Fatalf("Failed to create legacy cache: %v", err)
+	}
+	zipWriter := zip.NewWriter(file)
+	entry, err := zipWriter.Create("data.gob")
+	if err != nil {
+		t.Fatalf("Failed to create zip entry: %v", err)
+	}
+	if err := gob.NewEncoder(entry).Encode(data); err != nil {
+		t.Fatalf("Failed to encode legacy data: %v", err)
+	}
+	if err := zipWriter.Close(); err != nil {
+		t.Fatalf("Failed to close zip: %v", err)
+	}
+	file.Close()
+
+	dir := filepath.Join(root, "abc")
+	if _, err := MigrateLegacy(legacyPath, dir, nil); err != nil {
+		t.Fatalf("MigrateLegacy() error = %v", err)
+	}
+	if _, err := os.Stat(legacyPath); !os.IsNotExist(err) {
+		t.Errorf("Legacy cache still exists after migration")
+	}
+	loaded, err := Read(dir)
+	if err != nil {
+		t.Fatalf("Read() after migration error = %v", err)
+	}
+	if len(loaded.History) != 2 || loaded.Files["a.go"].TotalLines != 3 {
+		t.Errorf("Migrated data = %+v", loaded)
+	}
+}
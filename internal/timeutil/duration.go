@@ -0,0 +1,54 @@
+// Package timeutil provides small time helpers shared by the CLI and analyses.
+package timeutil
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Day, Week, Month and Year are the calendar-ish units accepted by ParseDuration.
+// Months and years are approximations (30 and 365 days).
+const (
+	Day   = 24 * time.Hour
+	Week  = 7 * Day
+	Month = 30 * Day
+	Year  = 365 * Day
+)
+
+// ParseDuration parses a duration such as "90d", "6mo", "2w", "1y" or any value
+// accepted by time.ParseDuration (e.g. "36h"). A number with a bare "m" suffix, which
+// time.ParseDuration would take as minutes, is rejected as a likely typo for "mo".
+func ParseDuration(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if number, ok := strings.CutSuffix(value, "m"); ok {
+		if _, err := strconv.ParseFloat(number, 64); err == nil {
+			return 0, fmt.Errorf("ambiguous duration %q: use %smo for months, or a Go duration such as 1h30m for minutes", value, number)
+		}
+	}
+	units := []struct {
+		suffix string
+		unit   time.Duration
+	}{
+		{"mo", Month},
+		{"d", Day},
+		{"w", Week},
+		{"y", Year},
+	}
+	for _, u := range units {
+		if !strings.HasSuffix(value, u.suffix) {
+			continue
+		}
+		count, err := strconv.ParseFloat(strings.TrimSuffix(value, u.suffix), 64)
+		if err != nil || count < 0 {
+			break
+		}
+		return time.Duration(count * float64(u.unit)), nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		return 0, fmt.Errorf("invalid duration %q: use a number followed by d, w, mo, y or a Go duration such as 36h", value)
+	}
+	return duration, nil
+}
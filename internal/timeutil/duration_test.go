@@ -0,0 +1,45 @@
+package timeutil
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	testCases := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"90d", 90 * Day, false},
+		{"2w", 2 * Week, false},
+		{"6mo", 6 * Month, false},
+		{"1y", Year, false},
+		{"1.5d", 36 * time.Hour, false},
+		{"36h", 36 * time.Hour, false},
+		{"1h30m", 90 * time.Minute, false},
+		{"6m", 0, true}, // Months are "mo"
+		{"30m", 0, true},
+		{"", 0, true},
+		{"-5d", 0, true},
+		{"soon", 0, true},
+	}
+	for _, tc := range testCases {
+		got, err := ParseDuration(tc.value)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("ParseDuration(%q) error = %v, wantErr %v", tc.value, err, tc.wantErr)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("ParseDuration(%q) = %v, want %v", tc.value, got, tc.want)
+		}
+	}
+}
+
+func TestParseDuration_SuggestsMonths(t *testing.T) {
+	_, err := ParseDuration("6m")
+	if err == nil || !strings.Contains(err.Error(), "6mo") {
+		t.Errorf("ParseDuration(\"6m\") error = %v, want a suggestion of 6mo", err)
+	}
+}
@@ -0,0 +1,432 @@
+// Package workspace collects several repositories and merges them into a single combined report.
+package workspace
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/user/git-inquisitor-go/internal/collector"
+	"github.com/user/git-inquisitor-go/internal/models"
+	"github.com/user/git-inquisitor-go/internal/progress"
+)
+
+// RepoResult is the outcome of collecting a single repository of the workspace.
+type RepoResult struct {
+	Name string
+	Path string
+	Data *models.CollectedData
+	Err  error
+}
+
+// ResolveRepos builds the list of repository paths from explicit paths, a glob pattern
+// and a manifest file (one path per line, '#' starts a comment, relative paths are
+// resolved against the manifest's directory). Duplicate paths are removed.
+func ResolveRepos(paths []string, glob, manifest string) ([]string, error) {
+	candidates := append([]string{}, paths...)
+
+	if glob != "" {
+		matches, err := filepath.Glob(glob)
+		if err != nil {
+			return nil, fmt.Errorf("invalid glob pattern '%s': %w", glob, err)
+		}
+		for _, match := range matches {
+			if looksLikeRepo(match) {
+				candidates = append(candidates, match)
+			}
+		}
+	}
+
+	if manifest != "" {
+		manifestPaths, err := readManifest(manifest)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, manifestPaths...)
+	}
+
+	seen := make(map[string]bool)
+	var repos []string
+	for _, candidate := range candidates {
+		absPath, err := filepath.Abs(candidate)
+		if err != nil {
+			return nil, fmt.Errorf("error getting absolute path for '%s': %w", candidate, err)
+		}
+		if seen[absPath] {
+			continue
+		}
+		seen[absPath] = true
+		repos = append(repos, absPath)
+	}
+	if len(repos) == 0 {
+		return nil, fmt.Errorf("no repositories specified (use paths, --glob or --manifest)")
+	}
+	return repos, nil
+}
+
+// readManifest reads repository paths from a manifest file.
+func readManifest(manifest string) ([]string, error) {
+	file, err := os.Open(manifest) // #nosec G304 -- manifest path is supplied by the user on purpose
+	if err != nil {
+		return nil, fmt.Errorf("failed to open manifest %s: %w", manifest, err)
+	}
+	defer file.Close()
+
+	baseDir := filepath.Dir(manifest)
+	var paths []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if idx := strings.Index(line, "#"); idx != -1 {
+			line = strings.TrimSpace(line[:idx])
+		}
+		if line == "" {
+			continue
+		}
+		if !filepath.IsAbs(line) {
+			line = filepath.Join(baseDir, line)
+		}
+		paths = append(paths, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read manifest %s: %w", manifest, err)
+	}
+	return paths, nil
+}
+
+// looksLikeRepo reports whether path is a directory containing a .git entry or a HEAD file.
+func looksLikeRepo(path string) bool {
+	stat, err := os.Stat(path)
+	if err != nil || !stat.IsDir() {
+		return false
+	}
+	if _, err := os.Stat(filepath.Join(path, ".git")); err == nil {
+		return true
+	}
+	_, err = os.Stat(filepath.Join(path, "HEAD"))
+	return err == nil
+}
+
+// Collect runs the collector for every repository, at most parallel at a time.
+// Each repository uses (and refreshes) its own cache and reports its progress to a
+// Sub reporter of reporter (nil for plain log lines on stdout). Diagnostics go to
+// logger with a repository attribute, or to the Sub reporter when logger is nil.
+// Cancelling ctx stops all collections. Results are returned in the order of repoPaths.
+func Collect(ctx context.Context, repoPaths []string, parallel int, opts collector.Options, reporter progress.Reporter, logger *slog.Logger) []RepoResult {
+	if parallel <= 0 {
+		parallel = runtime.NumCPU()
+	}
+	if reporter == nil {
+		reporter = progress.NewLog(os.Stdout, progress.Normal)
+	}
+	names := repoNames(repoPaths)
+	results := make([]RepoResult, len(repoPaths))
+
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for i, repoPath := range repoPaths {
+		wg.Add(1)
+		go func(i int, repoPath string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i] = RepoResult{Name: names[i], Path: repoPath}
+			col, err := collector.NewGitDataCollector(repoPath)
+			if err != nil {
+				results[i].Err = fmt.Errorf("failed to initialize collector for %s: %w", repoPath, err)
+				return
+			}
+			col.Options = opts
+			col.Progress = reporter.Sub(names[i])
+			if logger != nil {
+				col.Logger = logger.With("repository", names[i])
+			}
+			if err := col.Collect(ctx); err != nil {
+				results[i].Err = fmt.Errorf("error during data collection for %s: %w", repoPath, err)
+				return
+			}
+			// The combined report needs every repository's full history.
+			if err := col.LoadHistory(); err != nil {
+				results[i].Err = fmt.Errorf("failed to load spilled history for %s: %w", repoPath, err)
+				return
+			}
+			results[i].Data = &col.Data
+		}(i, repoPath)
+	}
+	wg.Wait()
+	return results
+}
+
+// repoNames derives a unique display name for each repository from its directory name.
+func repoNames(repoPaths []string) []string {
+	names := make([]string, len(repoPaths))
+	used := make(map[string]int)
+	for i, repoPath := range repoPaths {
+		name := strings.TrimSuffix(filepath.Base(repoPath), ".git")
+		used[name]++
+		if used[name] > 1 {
+			name = fmt.Sprintf("%s-%d", name, used[name])
+		}
+		names[i] = name
+	}
+	return names
+}
+
+// Merge combines the collected data of several repositories into one dataset.
+// Contributors are unified across repositories: names that share an email address
+// are treated as the same person and reported under their most active name.
+// File paths are prefixed with the repository name and history entries are tagged
+// with their repository.
+func Merge(results []RepoResult) *models.CollectedData {
+	identities := unifyIdentities(results)
+
+	merged := &models.CollectedData{
+		Metadata: models.Metadata{
+			Collector: models.CollectorMetadata{
+				InquisitorVersion: collector.InquisitorVersion,
+				DateCollected:     time.Now().UTC(),
+				Platform:          fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
+				GoVersion:         runtime.Version(),
+			},
+			Repo: models.RepoMetadata{
+				URL:    fmt.Sprintf("workspace (%d repositories)", len(results)),
+				Branch: "n/a",
+			},
+		},
+		Contributors: make(map[string]models.Contributor),
+		Files:        make(map[string]models.FileData),
+		History:      []models.CommitHistoryItem{},
+	}
+
+	for _, result := range results {
+		if result.Data == nil {
+			continue
+		}
+		data := result.Data
+		if merged.Metadata.Collector.GitVersion == "" {
+			merged.Metadata.Collector.User = data.Metadata.Collector.User
+			merged.Metadata.Collector.Hostname = data.Metadata.Collector.Hostname
+			merged.Metadata.Collector.GitVersion = data.Metadata.Collector.GitVersion
+		}
+		if data.Metadata.Repo.Commit.Date.After(merged.Metadata.Repo.Commit.Date) {
+			merged.Metadata.Repo.Commit = data.Metadata.Repo.Commit
+		}
+
+		summary := models.RepositorySummary{
+			Name:         result.Name,
+			Path:         result.Path,
+			URL:          data.Metadata.Repo.URL,
+			Branch:       data.Metadata.Repo.Branch,
+			Commit:       data.Metadata.Repo.Commit,
+			CommitCount:  len(data.History),
+			Contributors: len(data.Contributors),
+			Files:        len(data.Files),
+		}
+
+		for name, contributor := range data.Contributors {
+			canonical := identities.canonical(name)
+			combined := merged.Contributors[canonical]
+			if combined.Repositories == nil {
+				combined.Repositories = make(map[string]int)
+			}
+			for _, identity := range contributor.Identities {
+				if !containsString(combined.Identities, identity) {
+					combined.Identities = append(combined.Identities, identity)
+				}
+			}
+			combined.CommitCount += contributor.CommitCount
+			combined.Insertions += contributor.Insertions
+			combined.Deletions += contributor.Deletions
+			combined.ActiveLines += contributor.ActiveLines
+			combined.LineAges.Merge(contributor.LineAges)
+			mergeActivity(&combined, contributor)
+			combined.CommitTimes.Merge(contributor.CommitTimes)
+			combined.Repositories[result.Name] += contributor.CommitCount
+			merged.Contributors[canonical] = combined
+
+			summary.Insertions += contributor.Insertions
+			summary.Deletions += contributor.Deletions
+		}
+
+		for path, file := range data.Files {
+			linesByContributor := make(map[string]int, len(file.LinesByContributor))
+			for name, lines := range file.LinesByContributor {
+				linesByContributor[identities.canonical(name)] += lines
+			}
+			file.LinesByContributor = linesByContributor
+			if file.LineAgesByContributor != nil {
+				lineAgesByContributor := make(map[string]models.AgeHistogram, len(file.LineAgesByContributor))
+				for name, ages := range file.LineAgesByContributor {
+					combinedAges := lineAgesByContributor[identities.canonical(name)]
+					combinedAges.Merge(ages)
+					lineAgesByContributor[identities.canonical(name)] = combinedAges
+				}
+				file.LineAgesByContributor = lineAgesByContributor
+			}
+			merged.Files[result.Name+"/"+path] = file
+			summary.TotalLines += file.TotalLines
+		}
+
+		merged.CommitTimes.Merge(data.CommitTimes)
+		for _, release := range data.Releases {
+			release.Repository = result.Name
+			merged.Releases = append(merged.Releases, release)
+		}
+		if data.Branches != nil {
+			if merged.Branches == nil {
+				merged.Branches = &models.BranchLandscape{Branches: []models.Branch{}}
+			}
+			for _, branch := range data.Branches.Branches {
+				branch.Repository = result.Name
+				files := make([]string, len(branch.Files))
+				for i, path := range branch.Files {
+					files[i] = result.Name + "/" + path
+				}
+				branch.Files = files
+				merged.Branches.Branches = append(merged.Branches.Branches, branch)
+			}
+		}
+
+		for _, item := range data.History {
+			item.Repository = result.Name
+			merged.History = append(merged.History, item)
+		}
+
+		diagnostics := &merged.Diagnostics
+		for _, item := range data.Diagnostics.SkippedCommits {
+			item.Repository = result.Name
+			diagnostics.SkippedCommits = append(diagnostics.SkippedCommits, item)
+		}
+		for _, item := range data.Diagnostics.SkippedFiles {
+			item.Repository = result.Name
+			diagnostics.SkippedFiles = append(diagnostics.SkippedFiles, item)
+		}
+		for _, warning := range data.Diagnostics.Warnings {
+			diagnostics.Warnings = append(diagnostics.Warnings, fmt.Sprintf("[%s] %s", result.Name, warning))
+		}
+		merged.Repositories = append(merged.Repositories, summary)
+	}
+
+	// Keep the combined history oldest to newest, matching single repository collection.
+	sort.SliceStable(merged.History, func(i, j int) bool {
+		return merged.History[i].Date.Before(merged.History[j].Date)
+	})
+	sort.SliceStable(merged.Releases, func(i, j int) bool {
+		return merged.Releases[i].Date.Before(merged.Releases[j].Date)
+	})
+	return merged
+}
+
+// mergeActivity adds the activity timeline of contributor, from one repository, to combined.
+// Days active in several repositories are counted once per repository, and streaks are
+// not joined across repositories.
+func mergeActivity(combined *models.Contributor, contributor models.Contributor) {
+	if !contributor.FirstCommitDate.IsZero() && (combined.FirstCommitDate.IsZero() || contributor.FirstCommitDate.Before(combined.FirstCommitDate)) {
+		combined.FirstCommitDate = contributor.FirstCommitDate
+	}
+	if contributor.LastCommitDate.After(combined.LastCommitDate) {
+		combined.LastCommitDate = contributor.LastCommitDate
+	}
+	combined.ActiveDays += contributor.ActiveDays
+	if contributor.LongestStreak > combined.LongestStreak {
+		combined.LongestStreak = contributor.LongestStreak
+	}
+	combined.WeeklyCommits = addCounts(combined.WeeklyCommits, contributor.WeeklyCommits)
+	combined.MonthlyCommits = addCounts(combined.MonthlyCommits, contributor.MonthlyCommits)
+}
+
+// addCounts adds the counts of src to dst, allocating dst if needed, and returns dst.
+func addCounts(dst, src map[string]int) map[string]int {
+	if len(src) == 0 {
+		return dst
+	}
+	if dst == nil {
+		dst = make(map[string]int, len(src))
+	}
+	for key, count := range src {
+		dst[key] += count
+	}
+	return dst
+}
+
+// identityMap maps contributor names to a canonical name shared by all aliases of one person.
+type identityMap map[string]string
+
+func (im identityMap) canonical(name string) string {
+	if canonical, ok := im[name]; ok {
+		return canonical
+	}
+	return name
+}
+
+// unifyIdentities groups contributor names that share an email address (case-insensitive)
+// across all repositories, and picks the name with the most commits as the canonical one.
+func unifyIdentities(results []RepoResult) identityMap {
+	parent := make(map[string]string)
+	var find func(string) string
+	find = func(key string) string {
+		if parent[key] == "" || parent[key] == key {
+			parent[key] = key
+			return key
+		}
+		root := find(parent[key])
+		parent[key] = root
+		return root
+	}
+	union := func(a, b string) {
+		rootA, rootB := find(a), find(b)
+		if rootA != rootB {
+			parent[rootB] = rootA
+		}
+	}
+
+	commitsByName := make(map[string]int)
+	for _, result := range results {
+		if result.Data == nil {
+			continue
+		}
+		for name, contributor := range result.Data.Contributors {
+			nameKey := "name:" + name
+			find(nameKey)
+			commitsByName[name] += contributor.CommitCount
+			for _, identity := range contributor.Identities {
+				union(nameKey, "email:"+strings.ToLower(identity))
+			}
+		}
+	}
+
+	// Pick the most active name in every group, breaking ties alphabetically.
+	best := make(map[string]string)
+	for name := range commitsByName {
+		root := find("name:" + name)
+		current, ok := best[root]
+		if !ok || commitsByName[name] > commitsByName[current] ||
+			(commitsByName[name] == commitsByName[current] && name < current) {
+			best[root] = name
+		}
+	}
+
+	identities := make(identityMap, len(commitsByName))
+	for name := range commitsByName {
+		identities[name] = best[find("name:"+name)]
+	}
+	return identities
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,193 @@
+package workspace
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/git-inquisitor-go/internal/collector"
+	"github.com/user/git-inquisitor-go/internal/models"
+	"github.com/user/git-inquisitor-go/internal/progress"
+)
+
+// createTestRepo creates a git repository at path with a single commit by the given identity.
+func createTestRepo(t *testing.T, path, name, email string) {
+	t.Helper()
+	if err := os.MkdirAll(path, 0755); err != nil {
+		t.Fatalf("Failed to create repo dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(path, "README.md"), []byte("hello\nworld\n"), 0600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	for _, args := range [][]string{
+		{"init"},
+		{"config", "user.name", name},
+		{"config", "user.email", email},
+		{"add", "."},
+		{"commit", "-m", "initial"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", path}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+}
+
+func TestResolveRepos(t *testing.T) {
+	root := t.TempDir()
+	createTestRepo(t, filepath.Join(root, "repos", "alpha"), "A", "a@example.com")
+	createTestRepo(t, filepath.Join(root, "repos", "beta"), "B", "b@example.com")
+	if err := os.MkdirAll(filepath.Join(root, "repos", "not-a-repo"), 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+
+	manifest := filepath.Join(root, "repos.txt")
+	content := "# workspace manifest\nrepos/alpha\n\nrepos/beta # trailing comment\n"
+	if err := os.WriteFile(manifest, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+
+	fromManifest, err := ResolveRepos(nil, "", manifest)
+	if err != nil {
+		t.Fatalf("ResolveRepos(manifest) error = %v", err)
+	}
+	if len(fromManifest) != 2 {
+		t.Errorf("ResolveRepos(manifest) = %v, want 2 repositories", fromManifest)
+	}
+
+	// The glob also matches the non-repository directory, which must be skipped,
+	// and the explicit path duplicates a glob match.
+	fromGlob, err := ResolveRepos([]string{filepath.Join(root, "repos", "alpha")}, filepath.Join(root, "repos", "*"), "")
+	if err != nil {
+		t.Fatalf("ResolveRepos(glob) error = %v", err)
+	}
+	if len(fromGlob) != 2 {
+		t.Errorf("ResolveRepos(glob) = %v, want 2 repositories", fromGlob)
+	}
+
+	if _, err := ResolveRepos(nil, "", ""); err == nil {
+		t.Error("ResolveRepos() with no inputs expected error, got nil")
+	}
+}
+
+func TestRepoNames(t *testing.T) {
+	names := repoNames([]string{"/src/a/service", "/src/b/service.git", "/src/tools"})
+	want := []string{"service", "service-2", "tools"}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("repoNames()[%d] = %s, want %s", i, names[i], want[i])
+		}
+	}
+}
+
+func TestMerge_UnifiesIdentities(t *testing.T) {
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	results := []RepoResult{
+		{
+			Name: "api",
+			Data: &models.CollectedData{
+				Contributors: map[string]models.Contributor{
+					"Jane Doe": {Identities: []string{"jane@example.com"}, CommitCount: 5, Insertions: 50, ActiveLines: 40},
+				},
+				Files: map[string]models.FileData{
+					"main.go": {TotalLines: 40, LinesByContributor: map[string]int{"Jane Doe": 40}},
+				},
+				History: []models.CommitHistoryItem{{Commit: "a2", Date: day.Add(48 * time.Hour)}},
+			},
+		},
+		{
+			Name: "web",
+			Data: &models.CollectedData{
+				Contributors: map[string]models.Contributor{
+					"jdoe": {Identities: []string{"JANE@example.com"}, CommitCount: 2, Insertions: 20, ActiveLines: 10},
+					"Bob":  {Identities: []string{"bob@example.com"}, CommitCount: 1, Insertions: 5, ActiveLines: 5},
+				},
+				Files: map[string]models.FileData{
+					"index.js": {TotalLines: 15, LinesByContributor: map[string]int{"jdoe": 10, "Bob": 5}},
+				},
+				History: []models.CommitHistoryItem{{Commit: "w1", Date: day}},
+				Branches: &models.BranchLandscape{Default: "main", Branches: []models.Branch{
+					{Name: "main", Default: true},
+					{Name: "redesign", Ahead: 1, Files: []string{"index.js"}},
+				}},
+				Diagnostics: models.Diagnostics{
+					SkippedFiles: []models.SkippedItem{{Item: "big.bin", Phase: "blame", Reason: "timeout"}},
+				},
+			},
+		},
+	}
+
+	merged := Merge(results)
+
+	if len(merged.Contributors) != 2 {
+		t.Fatalf("Merged contributors = %v, want Jane Doe and Bob", merged.Contributors)
+	}
+	jane, ok := merged.Contributors["Jane Doe"]
+	if !ok {
+		t.Fatalf("Merged contributors missing canonical name 'Jane Doe': %v", merged.Contributors)
+	}
+	if jane.CommitCount != 7 || jane.ActiveLines != 50 {
+		t.Errorf("Jane Doe = %+v, want 7 commits and 50 active lines", jane)
+	}
+	if jane.Repositories["api"] != 5 || jane.Repositories["web"] != 2 {
+		t.Errorf("Jane Doe repositories = %v, want api:5 web:2", jane.Repositories)
+	}
+
+	if lines := merged.Files["web/index.js"].LinesByContributor["Jane Doe"]; lines != 10 {
+		t.Errorf("web/index.js lines by Jane Doe = %d, want 10", lines)
+	}
+	if len(merged.Repositories) != 2 || merged.Repositories[1].TotalLines != 15 {
+		t.Errorf("Repository summaries = %+v, want 2 entries with web totalling 15 lines", merged.Repositories)
+	}
+	if len(merged.Diagnostics.SkippedFiles) != 1 || merged.Diagnostics.SkippedFiles[0].Repository != "web" {
+		t.Errorf("Merged diagnostics = %+v, want big.bin skipped in web", merged.Diagnostics)
+	}
+	if merged.History[0].Commit != "w1" || merged.History[0].Repository != "web" {
+		t.Errorf("First history item = %+v, want w1 from web (oldest first)", merged.History[0])
+	}
+	if branches := merged.Branches; branches == nil || len(branches.Branches) != 2 || branches.Default != "" ||
+		branches.Branches[1].Repository != "web" || branches.Branches[1].Files[0] != "web/index.js" {
+		t.Errorf("Merged branches = %+v, want web's main and redesign, with repository paths", merged.Branches)
+	}
+}
+
+func TestCollect(t *testing.T) {
+	root := t.TempDir()
+	alpha := filepath.Join(root, "alpha")
+	beta := filepath.Join(root, "beta")
+	createTestRepo(t, alpha, "Jane Doe", "jane@example.com")
+	createTestRepo(t, beta, "jdoe", "jane@example.com")
+
+	out, err := exec.Command("git", "-C", alpha, "rev-parse", "HEAD").Output()
+	if err != nil {
+		t.Fatalf("git rev-parse failed: %v", err)
+	}
+	alphaHead := strings.TrimSpace(string(out))
+
+	// The ignored commit only exists in alpha: beta skips it with a warning.
+	opts := collector.Options{CacheDir: t.TempDir(), IgnoreRevs: []string{alphaHead}, SkipUnknownIgnoreRevs: true}
+	results := Collect(context.Background(), []string{alpha, beta}, 2, opts, progress.NewLog(io.Discard, progress.Quiet), nil)
+	for _, result := range results {
+		if result.Err != nil {
+			t.Fatalf("Collect() error for %s: %v", result.Name, result.Err)
+		}
+	}
+	if revs := results[0].Data.Metadata.Collector.IgnoredRevs; len(revs) != 1 || revs[0] != alphaHead {
+		t.Errorf("alpha IgnoredRevs = %v, want [%s]", revs, alphaHead)
+	}
+	if warnings := results[1].Data.Diagnostics.Warnings; len(warnings) == 0 || !strings.Contains(warnings[0], alphaHead) {
+		t.Errorf("beta warnings = %v, want the unknown ignored revision", warnings)
+	}
+
+	merged := Merge(results)
+	if len(merged.Contributors) != 1 {
+		t.Errorf("Merged contributors = %v, want a single unified contributor", merged.Contributors)
+	}
+	if len(merged.Files) != 2 {
+		t.Errorf("Merged files = %v, want alpha/README.md and beta/README.md", merged.Files)
+	}
+}
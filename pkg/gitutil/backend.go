@@ -0,0 +1,105 @@
+package gitutil
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/user/git-inquisitor-go/internal/models"
+)
+
+// Names of the available backends, as accepted by NewBackend.
+const (
+	BackendGoGit = "go-git"
+	BackendCLI   = "cli"
+)
+
+// Backend computes the expensive per-commit and per-file data: commit stats, blame
+// and file listings. Commits are always resolved with go-git; a Backend only decides
+// how they are diffed and blamed. Implementations are safe for concurrent use.
+type Backend interface {
+	// Name returns the backend's name (BackendGoGit or BackendCLI).
+	Name() string
+	// Version describes the git implementation, for CollectorMetadata.GitVersion.
+	Version(ctx context.Context) (string, error)
+	// CommitStats diffs commit against its first parent (or the empty tree for a root commit).
+	CommitStats(ctx context.Context, commit *object.Commit) (insertions, deletions int, filesChanged map[string]models.FileCommitStats, err error)
+	// Blame attributes the lines of filePath at commit.
+	Blame(ctx context.Context, commit *object.Commit, filePath string) (*models.FileBlameStats, error)
+	// FilePaths lists the non-binary files at commit.
+	FilePaths(ctx context.Context, commit *object.Commit) ([]string, error)
+}
+
+// BackendOptions configures a Backend.
+type BackendOptions struct {
+	// FastStats makes the go-git backend use GetCommitStatsFast; the CLI backend always
+	// takes its stats from `git log --numstat`.
+	FastStats bool
+	// Blame tunes how lines are attributed by Blame.
+	Blame BlameOptions
+}
+
+// NewBackend returns the backend called name for the repository repo, opened from dir,
+// whose history is collected from head. An empty name selects go-git.
+func NewBackend(name string, repo *git.Repository, dir string, head plumbing.Hash, opts BackendOptions) (Backend, error) {
+	switch name {
+	case "", BackendGoGit:
+		return NewGoGitBackend(repo, opts), nil
+	case BackendCLI:
+		return NewCLIBackend(dir, head, opts.Blame)
+	default:
+		return nil, ValidateBackend(name)
+	}
+}
+
+// ValidateBackend checks a backend name given on the command line.
+func ValidateBackend(name string) error {
+	switch name {
+	case "", BackendGoGit, BackendCLI:
+		return nil
+	default:
+		return fmt.Errorf("unknown backend %q: must be %q or %q", name, BackendGoGit, BackendCLI)
+	}
+}
+
+// GoGitBackend is the pure Go backend built on go-git.
+type GoGitBackend struct {
+	repo       *git.Repository
+	lineCounts *LineCountCache // Set when stats are computed with GetCommitStatsFast
+	blame      BlameOptions
+}
+
+// NewGoGitBackend returns a go-git backend for repo.
+func NewGoGitBackend(repo *git.Repository, opts BackendOptions) *GoGitBackend {
+	backend := &GoGitBackend{repo: repo, blame: opts.Blame}
+	if opts.FastStats {
+		backend.lineCounts = NewLineCountCache()
+	}
+	return backend
+}
+
+// Name implements Backend.
+func (b *GoGitBackend) Name() string { return BackendGoGit }
+
+// Version implements Backend.
+func (b *GoGitBackend) Version(_ context.Context) (string, error) { return GetGitVersion() }
+
+// CommitStats implements Backend with GetCommitStats, or GetCommitStatsFast for fast stats.
+func (b *GoGitBackend) CommitStats(ctx context.Context, commit *object.Commit) (int, int, map[string]models.FileCommitStats, error) {
+	if b.lineCounts != nil {
+		return GetCommitStatsFast(ctx, commit, b.lineCounts)
+	}
+	return GetCommitStats(ctx, commit)
+}
+
+// Blame implements Backend with GetBlameForFileWithOptions.
+func (b *GoGitBackend) Blame(ctx context.Context, commit *object.Commit, filePath string) (*models.FileBlameStats, error) {
+	return GetBlameForFileWithOptions(ctx, b.repo, commit, filePath, b.blame)
+}
+
+// FilePaths implements Backend with GetFilePaths.
+func (b *GoGitBackend) FilePaths(_ context.Context, commit *object.Commit) ([]string, error) {
+	return GetFilePaths(b.repo, commit)
+}
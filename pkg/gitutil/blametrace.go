@@ -0,0 +1,483 @@
+package gitutil
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// Minimum number of alphanumeric characters in a block of lines for it to count as
+// moved within a file or copied from another file, as git blame's -M and -C defaults.
+const (
+	minMoveScore = 20
+	minCopyScore = 40
+)
+
+// maxTraceHops bounds how many commits in a row a line is traced back through.
+const maxTraceHops = 16
+
+// BlameOptions tunes how lines are attributed by blame. The zero value is plain blame.
+type BlameOptions struct {
+	// IgnoreRevs are commits whose changes are attributed to the previous author of
+	// each line, such as mass reformatting commits.
+	IgnoreRevs []plumbing.Hash
+	// IgnoreWhitespace attributes lines that a commit only changed in whitespace to
+	// their previous author, like `git blame -w`.
+	IgnoreWhitespace bool
+	// DetectMoves attributes blocks of lines moved within a file to their previous
+	// author, like `git blame -M`.
+	DetectMoves bool
+	// DetectCopies also attributes blocks moved or copied from other files changed in
+	// the same commit to their previous author, like `git blame -C`. It implies DetectMoves.
+	DetectCopies bool
+}
+
+// ignoreSet returns IgnoreRevs as a set, or nil if there are none.
+func (o BlameOptions) ignoreSet() map[plumbing.Hash]bool {
+	if len(o.IgnoreRevs) == 0 {
+		return nil
+	}
+	set := make(map[plumbing.Hash]bool, len(o.IgnoreRevs))
+	for _, hash := range o.IgnoreRevs {
+		set[hash] = true
+	}
+	return set
+}
+
+// followsChanges reports whether lines are traced through any commit that changed
+// them only in whitespace, or moved or copied them.
+func (o BlameOptions) followsChanges() bool {
+	return o.IgnoreWhitespace || o.DetectMoves || o.DetectCopies
+}
+
+// Flags returns the `git blame` flags equivalent to o, except for IgnoreRevs.
+func (o BlameOptions) Flags() []string {
+	var flags []string
+	if o.IgnoreWhitespace {
+		flags = append(flags, "-w")
+	}
+	if o.DetectMoves || o.DetectCopies {
+		flags = append(flags, "-M")
+	}
+	if o.DetectCopies {
+		flags = append(flags, "-C")
+	}
+	return flags
+}
+
+// blameWithOptions blames filePath at commit and then traces lines further back than
+// go-git's blame does, one commit at a time: lines of ignored commits go to the line they
+// replaced in the commit's first parent, as `git blame --ignore-rev` does, and, depending
+// on opts, lines that a commit only reindented, moved or copied go to their source line.
+// Lines that cannot be traced (pure additions, root commits) stay where blame put them.
+func blameWithOptions(ctx context.Context, repo *git.Repository, commit *object.Commit, filePath string, opts BlameOptions) ([]blameLine, error) {
+	tracer := &blameTracer{
+		ctx:    ctx,
+		repo:   repo,
+		opts:   opts,
+		ignore: opts.ignoreSet(),
+		blames: make(map[traceKey]*tracedBlame),
+	}
+	start, err := tracer.blameFile(commit, filePath)
+	if err != nil {
+		return nil, err
+	}
+	lines := start.lines
+	if len(tracer.ignore) > 0 || opts.followsChanges() {
+		texts := start.texts
+		paths := make([]string, len(lines)) // The file each line is currently attributed in
+		for i := range paths {
+			paths[i] = filePath
+		}
+		done := make([]bool, len(lines)) // Lines that cannot be traced further back
+		for hop := 0; hop < maxTraceHops; hop++ {
+			groups := make(map[traceKey][]int)
+			var keys []traceKey // In order of first appearance, for deterministic results
+			for i, line := range lines {
+				if done[i] || line.Hash.IsZero() {
+					continue
+				}
+				if !tracer.ignore[line.Hash] && !opts.followsChanges() {
+					done[i] = true
+					continue
+				}
+				key := traceKey{hash: line.Hash, path: paths[i]}
+				if _, ok := groups[key]; !ok {
+					keys = append(keys, key)
+				}
+				groups[key] = append(groups[key], i)
+			}
+			if len(keys) == 0 {
+				break
+			}
+			for _, key := range keys {
+				if err := ctx.Err(); err != nil {
+					return nil, err
+				}
+				traced, errTrace := tracer.trace(key, groups[key], lines, texts, paths)
+				if errTrace != nil {
+					return nil, errTrace
+				}
+				for _, i := range groups[key] {
+					if !traced[i] {
+						done[i] = true
+					}
+				}
+			}
+		}
+	}
+
+	result := make([]blameLine, 0, len(lines))
+	for _, line := range lines {
+		if !line.Hash.IsZero() { // Lines go-git could not attribute
+			result = append(result, line)
+		}
+	}
+	return result, nil
+}
+
+// traceKey identifies a file at a commit.
+type traceKey struct {
+	hash plumbing.Hash
+	path string
+}
+
+// tracedBlame is the blame of a file, with the text of each line.
+type tracedBlame struct {
+	lines []blameLine
+	texts []string
+}
+
+// lineSource is the line a traced line came from in the parent commit.
+type lineSource struct {
+	path string
+	line int
+}
+
+// blameTracer traces blamed lines back through commits for blameWithOptions, caching
+// the blames of the parent commits it visits.
+type blameTracer struct {
+	ctx    context.Context
+	repo   *git.Repository
+	opts   BlameOptions
+	ignore map[plumbing.Hash]bool
+	blames map[traceKey]*tracedBlame // Nil entries for files that could not be blamed
+}
+
+// blameFile blames filePath at commit, keeping one (possibly zero) blameLine per line.
+func (t *blameTracer) blameFile(commit *object.Commit, filePath string) (*tracedBlame, error) {
+	result, err := blameContext(t.ctx, commit, filePath)
+	if err != nil {
+		return nil, err
+	}
+	blame := &tracedBlame{
+		lines: make([]blameLine, len(result.Lines)),
+		texts: make([]string, len(result.Lines)),
+	}
+	for i, line := range result.Lines {
+		if line == nil {
+			continue
+		}
+		blame.lines[i] = blameLine{Hash: line.Hash, Author: line.Author, AuthorName: line.AuthorName, Date: line.Date}
+		blame.texts[i] = line.Text
+	}
+	return blame, nil
+}
+
+// parentBlame returns the cached blame of filePath at commit, or nil if it cannot be blamed.
+func (t *blameTracer) parentBlame(commit *object.Commit, filePath string) (*tracedBlame, error) {
+	key := traceKey{hash: commit.Hash, path: filePath}
+	if blame, ok := t.blames[key]; ok {
+		return blame, nil
+	}
+	blame, err := t.blameFile(commit, filePath)
+	if err != nil {
+		if t.ctx.Err() != nil {
+			return nil, t.ctx.Err()
+		}
+		blame = nil
+	}
+	t.blames[key] = blame
+	return blame, nil
+}
+
+// trace re-attributes the lines at indexes, which are attributed to key's file at key's
+// commit, to their source lines in the commit's first parent. It updates lines, texts and
+// paths in place and reports which indexes were re-attributed.
+func (t *blameTracer) trace(key traceKey, indexes []int, lines []blameLine, texts, paths []string) (map[int]bool, error) {
+	traced := make(map[int]bool)
+	commit, err := t.repo.CommitObject(key.hash)
+	if err != nil || commit.NumParents() == 0 {
+		return traced, nil // Nothing to go back to
+	}
+	parent, err := commit.Parent(0)
+	if err != nil {
+		return traced, nil
+	}
+	childLines, err := fileLines(commit, key.path)
+	if err != nil {
+		return traced, nil
+	}
+	parentLines, _ := textFileLines(parent, key.path) // Nil if the commit added (or renamed) the file
+
+	// The lines are unchanged since the commit, so they appear there with the same text.
+	positions := alignLines(indexes, texts, childLines)
+	sources := make(map[int]lineSource)
+	for child, parentLine := range mapLinesToParent(parentLines, childLines, t.opts.IgnoreWhitespace, t.ignore[key.hash]) {
+		if parentLine >= 0 {
+			sources[child] = lineSource{path: key.path, line: parentLine}
+		}
+	}
+
+	if t.opts.DetectMoves || t.opts.DetectCopies {
+		pending := make(map[int]bool)
+		for _, position := range positions {
+			if _, ok := sources[position]; position >= 0 && !ok {
+				pending[position] = true
+			}
+		}
+		t.matchBlocks(childLines, pending, key.path, parentLines, minMoveScore, sources)
+		if t.opts.DetectCopies && len(pending) > 0 {
+			for _, path := range t.changedFiles(parent, commit) {
+				if path == key.path || len(pending) == 0 {
+					continue
+				}
+				if sourceLines, errSource := textFileLines(parent, path); errSource == nil {
+					t.matchBlocks(childLines, pending, path, sourceLines, minCopyScore, sources)
+				}
+			}
+		}
+	}
+
+	for k, i := range indexes {
+		source, ok := sources[positions[k]]
+		if positions[k] < 0 || !ok {
+			continue
+		}
+		blame, errBlame := t.parentBlame(parent, source.path)
+		if errBlame != nil {
+			return nil, errBlame
+		}
+		if blame == nil || source.line >= len(blame.lines) || blame.lines[source.line].Hash.IsZero() {
+			continue
+		}
+		lines[i] = blame.lines[source.line]
+		texts[i] = blame.texts[source.line]
+		paths[i] = source.path
+		traced[i] = true
+	}
+	return traced, nil
+}
+
+// matchBlocks finds, for runs of the pending lines of child, the longest identical run of
+// lines in source (a file of the parent commit called path), and records it in sources
+// when it has at least minScore alphanumeric characters. Matched lines are no longer pending.
+func (t *blameTracer) matchBlocks(child []string, pending map[int]bool, path string, source []string, minScore int, sources map[int]lineSource) {
+	if len(source) == 0 || len(pending) == 0 {
+		return
+	}
+	normalized := make([]string, len(source))
+	index := make(map[string][]int)
+	for q, line := range source {
+		normalized[q] = t.normalize(line)
+		if normalized[q] != "" {
+			index[normalized[q]] = append(index[normalized[q]], q)
+		}
+	}
+	for p := 0; p < len(child); p++ {
+		if !pending[p] {
+			continue
+		}
+		bestStart, bestLen := -1, 0
+		for _, q := range index[t.normalize(child[p])] {
+			n := 0
+			for p+n < len(child) && q+n < len(source) && pending[p+n] && t.normalize(child[p+n]) == normalized[q+n] {
+				n++
+			}
+			if n > bestLen {
+				bestStart, bestLen = q, n
+			}
+		}
+		if bestLen == 0 || alnumCount(child[p:p+bestLen]) < minScore {
+			continue
+		}
+		for n := 0; n < bestLen; n++ {
+			sources[p+n] = lineSource{path: path, line: bestStart + n}
+			delete(pending, p+n)
+		}
+		p += bestLen - 1
+	}
+}
+
+// changedFiles lists the files of parent that commit modified or deleted, the candidate
+// sources of copied lines.
+func (t *blameTracer) changedFiles(parent, commit *object.Commit) []string {
+	parentTree, err := parent.Tree()
+	if err != nil {
+		return nil
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil
+	}
+	changes, err := parentTree.DiffContext(t.ctx, tree)
+	if err != nil {
+		return nil
+	}
+	var paths []string
+	for _, change := range changes {
+		if change.From.Name != "" {
+			paths = append(paths, change.From.Name)
+		}
+	}
+	return paths
+}
+
+// normalize returns the form of line compared by the tracer: without any whitespace when
+// whitespace is ignored, otherwise unchanged.
+func (t *blameTracer) normalize(line string) string {
+	if !t.opts.IgnoreWhitespace {
+		return line
+	}
+	return stripWhitespace(line)
+}
+
+// stripWhitespace removes all whitespace from line.
+func stripWhitespace(line string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, line)
+}
+
+// alnumCount counts the letters and digits in lines.
+func alnumCount(lines []string) int {
+	count := 0
+	for _, line := range lines {
+		for _, r := range line {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// fileLines returns the lines of filePath at commit.
+func fileLines(commit *object.Commit, filePath string) ([]string, error) {
+	file, err := commit.File(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return file.Lines()
+}
+
+// textFileLines is fileLines for files that may be binary, which have no lines.
+func textFileLines(commit *object.Commit, filePath string) ([]string, error) {
+	file, err := commit.File(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if binary, errBinary := file.IsBinary(); errBinary != nil || binary {
+		return nil, errBinary
+	}
+	return file.Lines()
+}
+
+// alignLines finds, for the lines at indexes, their position in target by text, in order
+// where possible; lines that moved relative to each other are looked up from the start.
+// Lines not found get -1.
+func alignLines(indexes []int, texts []string, target []string) []int {
+	positions := make([]int, len(indexes))
+	used := make([]bool, len(target))
+	next := 0
+	for k, i := range indexes {
+		positions[k] = -1
+		for _, from := range []int{next, 0} {
+			for j := from; j < len(target); j++ {
+				if !used[j] && target[j] == texts[i] {
+					positions[k] = j
+					break
+				}
+			}
+			if positions[k] >= 0 {
+				break
+			}
+		}
+		if positions[k] >= 0 {
+			used[positions[k]] = true
+			next = positions[k] + 1
+		}
+	}
+	return positions
+}
+
+// mapLinesToParent maps each line of child to the line of parent it was kept from,
+// comparing lines without whitespace if ignoreWhitespace is set. With pairReplaced, lines
+// within a replaced block map to the parent line at the same offset. Other lines map to -1.
+func mapLinesToParent(parent, child []string, ignoreWhitespace, pairReplaced bool) []int {
+	mapping := make([]int, len(child))
+	for i := range mapping {
+		mapping[i] = -1
+	}
+	if len(parent) == 0 {
+		return mapping
+	}
+	if ignoreWhitespace {
+		parent, child = stripAll(parent), stripAll(child)
+	}
+	diffs := diff.Do(joinLines(parent), joinLines(child))
+	parentLine, childLine := 0, 0
+	var deleted []int // Parent lines removed just before the current insertion
+	for _, d := range diffs {
+		count := strings.Count(d.Text, "\n")
+		switch d.Type {
+		case diffmatchpatch.DiffEqual:
+			for n := 0; n < count; n++ {
+				mapping[childLine] = parentLine
+				parentLine++
+				childLine++
+			}
+			deleted = nil
+		case diffmatchpatch.DiffDelete:
+			for n := 0; n < count; n++ {
+				deleted = append(deleted, parentLine)
+				parentLine++
+			}
+		case diffmatchpatch.DiffInsert:
+			for n := 0; n < count; n++ {
+				if pairReplaced && n < len(deleted) {
+					mapping[childLine] = deleted[n]
+				}
+				childLine++
+			}
+			deleted = nil
+		}
+	}
+	return mapping
+}
+
+// stripAll applies stripWhitespace to each line.
+func stripAll(lines []string) []string {
+	stripped := make([]string, len(lines))
+	for i, line := range lines {
+		stripped[i] = stripWhitespace(line)
+	}
+	return stripped
+}
+
+// joinLines joins lines with a newline after each, so every line is counted by the diff.
+func joinLines(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
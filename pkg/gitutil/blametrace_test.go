@@ -0,0 +1,91 @@
+package gitutil
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBlame_WhitespaceMovesCopies(t *testing.T) {
+	repoPath, cleanup := createTestRepo(t)
+	defer cleanup()
+
+	commit := func(author string, files map[string]string) {
+		t.Helper()
+		for name, content := range files {
+			if err := os.WriteFile(filepath.Join(repoPath, name), []byte(content), 0600); err != nil {
+				t.Fatalf("Failed to write file: %v", err)
+			}
+		}
+		if out, err := exec.Command("git", "-C", repoPath, "add", "-A").CombinedOutput(); err != nil {
+			t.Fatalf("Failed to git add: %v: %s", err, out)
+		}
+		cmd := exec.Command("git", "-C", repoPath, "commit", "-m", "change", "--author", author+" <"+strings.ToLower(author)+"@example.com>")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("Failed to git commit: %v: %s", err, out)
+		}
+	}
+	parse := "func parseConfiguration(input string) (Config, error) {\n\treturn decodeConfiguration(input)\n}\n"
+	render := "func renderTemplate(name string, data any) string {\n\treturn executeTemplate(name, data)\n}\n"
+	helper := "func normalizeIdentifier(value string) string {\n\treturn strings.ToLower(value)\n}\n"
+	commit("Alice", map[string]string{"a.go": parse + render, "b.go": helper})
+	// Bob only reindents, Carol swaps the functions and Dave moves the helper over from b.go.
+	reindented := strings.ReplaceAll(parse, "\t", "    ")
+	commit("Bob", map[string]string{"a.go": reindented + render})
+	commit("Carol", map[string]string{"a.go": render + reindented})
+	commit("Dave", map[string]string{"a.go": render + reindented + helper, "b.go": "\n"})
+
+	repo, err := OpenRepository(repoPath)
+	if err != nil {
+		t.Fatalf("OpenRepository() error = %v", err)
+	}
+	head, _ := GetHeadCommit(repo)
+	ctx := context.Background()
+
+	// Right after Bob's reindent, ignoring whitespace leaves everything to Alice.
+	reindent, err := repo.ResolveRevision("HEAD~2")
+	if err != nil {
+		t.Fatalf("ResolveRevision() error = %v", err)
+	}
+	reindentCommit, _ := repo.CommitObject(*reindent)
+	for _, name := range []string{BackendGoGit, BackendCLI} {
+		backend, _ := NewBackend(name, repo, repoPath, head.Hash, BackendOptions{Blame: BlameOptions{IgnoreWhitespace: true}})
+		stats, err := backend.Blame(ctx, reindentCommit, "a.go")
+		if err != nil {
+			t.Fatalf("%s Blame() error = %v", name, err)
+		}
+		if want := map[string]int{"Alice": 6}; !reflect.DeepEqual(stats.LinesByContributor, want) {
+			t.Errorf("%s Blame() ignoring whitespace at the reindent = %v, want %v", name, stats.LinesByContributor, want)
+		}
+	}
+
+	tests := []struct {
+		name string
+		opts BlameOptions
+		want map[string]int
+	}{
+		{"none", BlameOptions{}, map[string]int{"Alice": 3, "Carol": 3, "Dave": 3}},
+		{"whitespace", BlameOptions{IgnoreWhitespace: true}, map[string]int{"Alice": 3, "Carol": 3, "Dave": 3}},
+		{"moves", BlameOptions{DetectMoves: true}, map[string]int{"Alice": 5, "Bob": 1, "Dave": 3}},
+		{"whitespace and copies", BlameOptions{IgnoreWhitespace: true, DetectCopies: true}, map[string]int{"Alice": 9}},
+	}
+	for _, tt := range tests {
+		for _, name := range []string{BackendGoGit, BackendCLI} {
+			backend, err := NewBackend(name, repo, repoPath, head.Hash, BackendOptions{Blame: tt.opts})
+			if err != nil {
+				t.Fatalf("NewBackend(%s) error = %v", name, err)
+			}
+			stats, err := backend.Blame(ctx, head, "a.go")
+			if err != nil {
+				t.Fatalf("%s Blame() error = %v", name, err)
+			}
+			if !reflect.DeepEqual(stats.LinesByContributor, tt.want) {
+				t.Errorf("%s: %s Blame() = %v, want %v", tt.name, name, stats.LinesByContributor, tt.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package gitutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Branch is a local or remote-tracking branch.
+type Branch struct {
+	Name   string // Short name, such as "main" or "origin/feature"
+	Remote bool
+	Hash   plumbing.Hash
+}
+
+// ListBranches lists the local and remote-tracking branches of repo, sorted by name.
+// Symbolic references, such as origin/HEAD, are left out.
+func ListBranches(repo *git.Repository) ([]Branch, error) {
+	refIter, err := repo.References()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list references: %w", err)
+	}
+	var branches []Branch
+	err = refIter.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() != plumbing.HashReference {
+			return nil
+		}
+		switch name := ref.Name(); {
+		case name.IsBranch():
+			branches = append(branches, Branch{Name: name.Short(), Hash: ref.Hash()})
+		case name.IsRemote() && !strings.HasSuffix(name.String(), "/HEAD"):
+			branches = append(branches, Branch{Name: name.Short(), Remote: true, Hash: ref.Hash()})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+	sort.Slice(branches, func(i, j int) bool { return branches[i].Name < branches[j].Name })
+	return branches, nil
+}
+
+// DefaultBranch returns the name of the branch among branches that the others are
+// compared with: the one origin/HEAD points at (preferring its local branch), else main
+// or master, else the checked-out branch. It returns "" if none of them is a branch.
+func DefaultBranch(repo *git.Repository, branches []Branch) string {
+	known := make(map[string]bool, len(branches))
+	for _, branch := range branches {
+		known[branch.Name] = true
+	}
+	var candidates []string
+	if ref, err := repo.Reference(plumbing.NewRemoteHEADReferenceName("origin"), false); err == nil && ref.Type() == plumbing.SymbolicReference {
+		remote := ref.Target().Short()
+		candidates = append(candidates, strings.TrimPrefix(remote, "origin/"), remote)
+	}
+	candidates = append(candidates, "main", "origin/main", "master", "origin/master")
+	if head, err := repo.Head(); err == nil && head.Name().IsBranch() {
+		candidates = append(candidates, head.Name().Short())
+	}
+	for _, name := range candidates {
+		if known[name] {
+			return name
+		}
+	}
+	return ""
+}
+
+// BranchComparison compares a branch with a base branch.
+type BranchComparison struct {
+	Ahead  int // Commits reachable from the branch but not from the base
+	Behind int // Commits reachable from the base but not from the branch
+	// MergeBase is the most recent common ancestor of the branch and the base; the zero
+	// hash if they share no history.
+	MergeBase plumbing.Hash
+}
+
+// commitNode is a commit of a commitGraph.
+type commitNode struct {
+	parents []plumbing.Hash
+	when    time.Time
+}
+
+// commitGraph holds the parents of the commits read so far, so the histories of many
+// branches can be walked without reading their commits again.
+type commitGraph struct {
+	repo  *git.Repository
+	nodes map[plumbing.Hash]commitNode
+}
+
+// load reads start and its ancestors into the graph. Ancestors missing from the
+// repository, as in shallow clones, end the history.
+func (g *commitGraph) load(ctx context.Context, start plumbing.Hash) error {
+	pending := []plumbing.Hash{start}
+	for len(pending) > 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		hash := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if _, ok := g.nodes[hash]; ok {
+			continue
+		}
+		commit, err := g.repo.CommitObject(hash)
+		if errors.Is(err, plumbing.ErrObjectNotFound) && hash != start {
+			g.nodes[hash] = commitNode{}
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read commit %s: %w", hash, err)
+		}
+		g.nodes[hash] = commitNode{parents: commit.ParentHashes, when: commit.Committer.When}
+		for _, parent := range commit.ParentHashes {
+			if _, ok := g.nodes[parent]; !ok {
+				pending = append(pending, parent)
+			}
+		}
+	}
+	return nil
+}
+
+// walk visits the loaded commits reachable from starts, each once, and does not walk
+// past those for which visit returns false.
+func (g *commitGraph) walk(starts []plumbing.Hash, visit func(plumbing.Hash) bool) {
+	seen := make(map[plumbing.Hash]bool)
+	pending := append([]plumbing.Hash(nil), starts...)
+	for len(pending) > 0 {
+		hash := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if seen[hash] {
+			continue
+		}
+		seen[hash] = true
+		if visit(hash) {
+			pending = append(pending, g.nodes[hash].parents...)
+		}
+	}
+}
+
+// CompareBranches compares each of tips with base, like `git rev-list --left-right
+// --count base...tip` and `git merge-base base tip`. Where several common ancestors
+// qualify, MergeBase is the most recently committed one.
+func CompareBranches(ctx context.Context, repo *git.Repository, base plumbing.Hash, tips []plumbing.Hash) ([]BranchComparison, error) {
+	graph := &commitGraph{repo: repo, nodes: make(map[plumbing.Hash]commitNode)}
+	if err := graph.load(ctx, base); err != nil {
+		return nil, err
+	}
+	inBase := make(map[plumbing.Hash]bool)
+	graph.walk([]plumbing.Hash{base}, func(hash plumbing.Hash) bool {
+		inBase[hash] = true
+		return true
+	})
+
+	comparisons := make([]BranchComparison, len(tips))
+	for i, tip := range tips {
+		if err := graph.load(ctx, tip); err != nil {
+			return nil, err
+		}
+		var comparison BranchComparison
+		var boundary []plumbing.Hash
+		graph.walk([]plumbing.Hash{tip}, func(hash plumbing.Hash) bool {
+			if inBase[hash] {
+				boundary = append(boundary, hash)
+				return false
+			}
+			comparison.Ahead++
+			return true
+		})
+		common := 0
+		graph.walk(boundary, func(plumbing.Hash) bool {
+			common++
+			return true
+		})
+		comparison.Behind = len(inBase) - common
+		for _, hash := range boundary {
+			if comparison.MergeBase.IsZero() || graph.nodes[hash].when.After(graph.nodes[comparison.MergeBase].when) {
+				comparison.MergeBase = hash
+			}
+		}
+		comparisons[i] = comparison
+	}
+	return comparisons, nil
+}
+
+// ChangedFiles returns the paths that differ between the trees of the commits from and
+// to, sorted. A zero from compares with an empty tree.
+func ChangedFiles(ctx context.Context, repo *git.Repository, from, to plumbing.Hash) ([]string, error) {
+	toCommit, err := repo.CommitObject(to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit %s: %w", to, err)
+	}
+	toTree, err := toCommit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tree of commit %s: %w", to, err)
+	}
+	var fromTree *object.Tree
+	if !from.IsZero() {
+		fromCommit, err := repo.CommitObject(from)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read commit %s: %w", from, err)
+		}
+		if fromTree, err = fromCommit.Tree(); err != nil {
+			return nil, fmt.Errorf("failed to read tree of commit %s: %w", from, err)
+		}
+	}
+	changes, err := object.DiffTreeWithOptions(ctx, fromTree, toTree, object.DefaultDiffTreeOptions)
+	if err != nil {
+		return nil, fmt.Errorf("could not diff %s and %s: %w", from, to, err)
+	}
+	paths := make([]string, 0, len(changes))
+	for _, change := range changes {
+		name := change.To.Name
+		if name == "" {
+			name = change.From.Name
+		}
+		paths = append(paths, name)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
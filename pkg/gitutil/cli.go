@@ -0,0 +1,371 @@
+package gitutil
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/user/git-inquisitor-go/internal/models"
+)
+
+// commitMarker starts each commit in the `git log` output parsed by loadNumstat.
+const commitMarker = "\x01"
+
+// numstatWindow is how many commits of the history CLIBackend reads the stats of at once.
+const numstatWindow = 1024
+
+// CLIBackend runs the git command line tool, which is considerably faster than go-git
+// for blame and diffs on large repositories. It requires git 2.31 or later.
+//
+// Commit stats are read with `git log --numstat` in windows of numstatWindow commits,
+// following the history of head oldest first, as the collector asks for them. Only the
+// hashes of the history and the stats of the last two windows are held at a time, and
+// stats are released as they are handed out. numstat does not report file sizes, so the
+// Lines of each FileCommitStats is left at zero.
+type CLIBackend struct {
+	dir   string
+	head  plumbing.Hash
+	blame BlameOptions
+
+	mu      sync.Mutex
+	history []plumbing.Hash                  // Commits of head, oldest first, once listed
+	next    int                              // Position in history of the next window
+	windows [][]plumbing.Hash                // Commits of the last windows read
+	numstat map[plumbing.Hash]*commitNumstat // Stats of the windows not yet handed out
+}
+
+// commitNumstat is the numstat of a single commit.
+type commitNumstat struct {
+	insertions, deletions int
+	files                 map[string]models.FileCommitStats
+}
+
+// add records the numstat fields (insertions, deletions) of the file at path.
+// Binary files are reported as "-", which counts as zero lines.
+func (n *commitNumstat) add(fields []string, path string) {
+	insertions, _ := strconv.Atoi(fields[0])
+	deletions, _ := strconv.Atoi(fields[1])
+	n.insertions += insertions
+	n.deletions += deletions
+	n.files[path] = models.FileCommitStats{Insertions: insertions, Deletions: deletions}
+}
+
+// NewCLIBackend returns a backend running git in the repository at dir, whose
+// history is collected from head, blaming with opts.
+func NewCLIBackend(dir string, head plumbing.Hash, opts BlameOptions) (*CLIBackend, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, fmt.Errorf("the %s backend needs the git command: %w", BackendCLI, err)
+	}
+	return &CLIBackend{dir: dir, head: head, blame: opts}, nil
+}
+
+// Name implements Backend.
+func (b *CLIBackend) Name() string { return BackendCLI }
+
+// Version implements Backend with the output of `git --version`.
+func (b *CLIBackend) Version(ctx context.Context) (string, error) {
+	out, err := b.git(ctx, "--version")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// CommitStats implements Backend with `git log --numstat`, diffing merges against their first parent.
+func (b *CLIBackend) CommitStats(ctx context.Context, commit *object.Commit) (int, int, map[string]models.FileCommitStats, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.numstat == nil {
+		out, err := b.git(ctx, "rev-list", "--reverse", b.head.String())
+		if err != nil {
+			return 0, 0, nil, fmt.Errorf("failed to list the history of %s: %w", b.head, err)
+		}
+		for _, line := range strings.Fields(string(out)) {
+			b.history = append(b.history, plumbing.NewHash(line))
+		}
+		b.numstat = make(map[plumbing.Hash]*commitNumstat)
+	}
+	stats, ok := b.numstat[commit.Hash]
+	if !ok && b.next < len(b.history) {
+		if err := b.loadWindow(ctx); err != nil {
+			return 0, 0, nil, err
+		}
+		stats, ok = b.numstat[commit.Hash]
+	}
+	if ok {
+		delete(b.numstat, commit.Hash) // Each commit is asked for once; free its stats
+	} else {
+		// Not in the history of head, or far from the windows: read this commit on its own.
+		numstat, err := b.loadNumstat(ctx, nil, "-1", commit.Hash.String())
+		if err != nil {
+			return 0, 0, nil, err
+		}
+		if stats, ok = numstat[commit.Hash]; !ok {
+			return 0, 0, nil, fmt.Errorf("git log did not report commit %s", commit.Hash)
+		}
+	}
+	return stats.insertions, stats.deletions, stats.files, nil
+}
+
+// loadWindow reads the stats of the next numstatWindow commits of the history. The stats
+// left over from the window before the previous one are dropped first: commits are asked
+// for roughly in history order, so those not handed out yet are read on their own if needed.
+func (b *CLIBackend) loadWindow(ctx context.Context) error {
+	window := b.history[b.next:min(b.next+numstatWindow, len(b.history))]
+	b.next += len(window)
+	if len(b.windows) == 2 {
+		for _, hash := range b.windows[0] {
+			delete(b.numstat, hash)
+		}
+		b.windows = b.windows[1:]
+	}
+	b.windows = append(b.windows, window)
+
+	numstat, err := b.loadNumstat(ctx, window, "--no-walk=unsorted", "--stdin")
+	if err != nil {
+		return err
+	}
+	for hash, stats := range numstat {
+		b.numstat[hash] = stats
+	}
+	return nil
+}
+
+// loadNumstat runs `git log --numstat` with args, and revs on its standard input, and parses
+// its NUL separated output as it is produced: a commit marker with the hash, then
+// "<ins>\t<del>\t<path>" per file, where renames have an empty path followed by the old and
+// the new path.
+func (b *CLIBackend) loadNumstat(ctx context.Context, revs []plumbing.Hash, args ...string) (map[plumbing.Hash]*commitNumstat, error) {
+	logArgs := append([]string{
+		"log", "-z", "--numstat", "--root", "-M", "--diff-merges=first-parent",
+		"--no-show-signature", "--no-textconv", "--format=tformat:" + commitMarker + "%H",
+	}, args...)
+	var stdin strings.Builder
+	for _, rev := range revs {
+		stdin.WriteString(rev.String() + "\n")
+	}
+
+	numstat := make(map[plumbing.Hash]*commitNumstat)
+	err := b.gitStream(ctx, logArgs, strings.NewReader(stdin.String()), func(out io.Reader) error {
+		scanner := bufio.NewScanner(out)
+		scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+		scanner.Split(scanNUL)
+
+		var current *commitNumstat
+		var fields []string // Counts of a rename whose paths are still to come
+		renamePaths := 0
+		for scanner.Scan() {
+			token := strings.TrimPrefix(scanner.Text(), "\n")
+			if renamePaths > 0 {
+				if renamePaths--; renamePaths == 0 { // The new path
+					current.add(fields, token)
+				}
+				continue
+			}
+			if token == "" {
+				continue
+			}
+			if strings.HasPrefix(token, commitMarker) {
+				current = &commitNumstat{files: make(map[string]models.FileCommitStats)}
+				numstat[plumbing.NewHash(strings.TrimPrefix(token, commitMarker))] = current
+				continue
+			}
+			fields = strings.SplitN(token, "\t", 3)
+			if len(fields) != 3 || current == nil {
+				return fmt.Errorf("unexpected git log output %q", token)
+			}
+			if fields[2] == "" { // Rename: the old and the new path follow
+				renamePaths = 2
+				continue
+			}
+			current.add(fields, fields[2])
+		}
+		return scanner.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return numstat, nil
+}
+
+// Blame implements Backend with `git blame --porcelain`. Unlike go-git's blame it stops
+// as soon as ctx is done. The repository's blame.ignoreRevsFile setting is overridden,
+// since the ignored commits were already resolved into the backend's BlameOptions.
+func (b *CLIBackend) Blame(ctx context.Context, commit *object.Commit, filePath string) (*models.FileBlameStats, error) {
+	args := append([]string{"-c", "blame.ignoreRevsFile=", "blame", "--porcelain"}, b.blame.Flags()...)
+	for _, hash := range b.blame.IgnoreRevs {
+		args = append(args, "--ignore-rev", hash.String())
+	}
+	args = append(args, commit.Hash.String(), "--", filePath)
+	out, err := b.git(ctx, args...)
+	if err != nil {
+		return &models.FileBlameStats{LinesByContributor: make(map[string]int)},
+			fmt.Errorf("failed to get blame for file %s at commit %s: %w", filePath, commit.Hash.String(), err)
+	}
+	lines, err := parseBlamePorcelain(out)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse blame for file %s at commit %s: %w", filePath, commit.Hash.String(), err)
+	}
+	return blameStatsFromLines(lines, commit.Committer.When), nil
+}
+
+// parseBlamePorcelain parses the output of `git blame --porcelain` into one blameLine per line.
+// Commit details are only printed the first time a commit appears, so they are remembered by hash.
+func parseBlamePorcelain(out []byte) ([]blameLine, error) {
+	type commitInfo struct {
+		name, mail string
+		time       int64
+		tz         string
+	}
+	commits := make(map[string]*commitInfo)
+	var lines []blameLine
+	var current *commitInfo
+	var currentHash string
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024) // Lines of minified files can be long
+	expectHeader := true
+	for scanner.Scan() {
+		text := scanner.Text()
+		if expectHeader {
+			fields := strings.Fields(text)
+			if len(fields) < 3 {
+				return nil, fmt.Errorf("unexpected blame header %q", text)
+			}
+			currentHash = fields[0]
+			if current = commits[currentHash]; current == nil {
+				current = &commitInfo{}
+				commits[currentHash] = current
+			}
+			expectHeader = false
+			continue
+		}
+		if strings.HasPrefix(text, "\t") { // The line's content ends its entry
+			date := time.Unix(current.time, 0)
+			if loc, err := time.Parse("-0700", current.tz); err == nil {
+				date = date.In(loc.Location())
+			}
+			lines = append(lines, blameLine{
+				Hash:       plumbing.NewHash(currentHash),
+				Author:     current.mail,
+				AuthorName: current.name,
+				Date:       date,
+			})
+			expectHeader = true
+			continue
+		}
+		key, value, _ := strings.Cut(text, " ")
+		switch key {
+		case "author":
+			current.name = value
+		case "author-mail":
+			current.mail = strings.Trim(value, "<>")
+		case "author-time":
+			current.time, _ = strconv.ParseInt(value, 10, 64)
+		case "author-tz":
+			current.tz = value
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// FilePaths implements Backend with `git ls-tree`, which also works in bare mirrors, unlike
+// `git ls-files`. Binary files are skipped like GetFilePaths does: files that are not empty
+// must be listed by `git grep -I`, which leaves out the files git considers binary.
+func (b *CLIBackend) FilePaths(ctx context.Context, commit *object.Commit) ([]string, error) {
+	rev := commit.Hash.String()
+	tree, err := b.git(ctx, "ls-tree", "-r", "-l", "-z", rev)
+	if err != nil {
+		return nil, fmt.Errorf("could not list files at commit %s: %w", rev, err)
+	}
+	text, err := b.git(ctx, "grep", "-I", "-l", "-z", "-e", "", rev, "--")
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 { // No file has a line
+		text, err = nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not find the text files at commit %s: %w", rev, err)
+	}
+	isText := make(map[string]bool)
+	for _, name := range strings.Split(string(text), "\x00") {
+		isText[strings.TrimPrefix(name, rev+":")] = true
+	}
+
+	var files []string
+	for _, entry := range strings.Split(string(tree), "\x00") {
+		// "<mode> <type> <object> <size>\t<path>", the size padded with spaces.
+		info, path, ok := strings.Cut(entry, "\t")
+		fields := strings.Fields(info)
+		if !ok || len(fields) != 4 || fields[1] != "blob" {
+			continue // Submodules have no content of their own
+		}
+		if fields[3] == "0" || isText[path] {
+			files = append(files, path)
+		}
+	}
+	return files, nil
+}
+
+// gitStream runs git with args in the repository, reading stdin, and hands its standard output to read
+// while it runs, so large outputs need not be held in memory.
+func (b *CLIBackend) gitStream(ctx context.Context, args []string, stdin io.Reader, read func(out io.Reader) error) error {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", b.dir}, args...)...) // #nosec G204 -- fixed git subcommands
+	cmd.Stdin = stdin
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("git %s failed: %w", args[0], err)
+	}
+	errRead := read(out)
+	if errRead != nil {
+		io.Copy(io.Discard, out) // Let git finish writing before waiting for it
+	}
+	errWait := cmd.Wait()
+	switch {
+	case ctx.Err() != nil:
+		return ctx.Err()
+	case errWait != nil:
+		return fmt.Errorf("git %s failed: %w: %s", args[0], errWait, strings.TrimSpace(stderr.String()))
+	}
+	return errRead
+}
+
+// scanNUL is a bufio.SplitFunc for NUL terminated tokens.
+func scanNUL(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// git runs git with args in the repository and returns its standard output.
+func (b *CLIBackend) git(ctx context.Context, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", b.dir}, args...)...) // #nosec G204 -- fixed git subcommands
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("git %s failed: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
@@ -0,0 +1,117 @@
+package gitutil
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+func TestCLIBackend_MatchesGoGit(t *testing.T) {
+	repoPath, cleanup := createTestRepo(t)
+	defer cleanup()
+
+	commit := func(author string, files map[string]string) {
+		t.Helper()
+		for name, content := range files {
+			if err := os.MkdirAll(filepath.Dir(filepath.Join(repoPath, name)), 0750); err != nil {
+				t.Fatalf("Failed to create directory: %v", err)
+			}
+			if err := os.WriteFile(filepath.Join(repoPath, name), []byte(content), 0600); err != nil {
+				t.Fatalf("Failed to write file: %v", err)
+			}
+		}
+		if out, err := exec.Command("git", "-C", repoPath, "add", "-A").CombinedOutput(); err != nil {
+			t.Fatalf("Failed to git add: %v: %s", err, out)
+		}
+		cmd := exec.Command("git", "-C", repoPath, "commit", "-m", "change", "--author", author+" <"+strings.ToLower(author)+"@example.com>")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("Failed to git commit: %v: %s", err, out)
+		}
+	}
+	commit("Alice", map[string]string{"a.txt": "1\n2\n3\n", "bin.dat": "\x00\x01", "empty.txt": ""})
+	commit("Bob", map[string]string{"a.txt": "1\ntwo\n3\n4\n", "dir/b.txt": "x\n"})
+	if out, err := exec.Command("git", "-C", repoPath, "mv", "dir/b.txt", "dir/renamed.txt").CombinedOutput(); err != nil {
+		t.Fatalf("Failed to git mv: %v: %s", err, out)
+	}
+	commit("Alice", map[string]string{"a.txt": "1\ntwo\n"})
+
+	repo, err := OpenRepository(repoPath)
+	if err != nil {
+		t.Fatalf("OpenRepository() error = %v", err)
+	}
+	head, _ := GetHeadCommit(repo)
+	commits, err := IterateCommits(context.Background(), repo, head)
+	if err != nil {
+		t.Fatalf("IterateCommits() error = %v", err)
+	}
+
+	ctx := context.Background()
+	goGit, err := NewBackend(BackendGoGit, repo, repoPath, head.Hash, BackendOptions{})
+	if err != nil {
+		t.Fatalf("NewBackend(go-git) error = %v", err)
+	}
+	cli, err := NewBackend(BackendCLI, repo, repoPath, head.Hash, BackendOptions{})
+	if err != nil {
+		t.Fatalf("NewBackend(cli) error = %v", err)
+	}
+
+	if version, err := cli.Version(ctx); err != nil || !strings.HasPrefix(version, "git version") {
+		t.Errorf("CLI Version() = %q, %v, want the output of git --version", version, err)
+	}
+
+	wantPaths, _ := goGit.FilePaths(ctx, head)
+	paths, err := cli.FilePaths(ctx, head)
+	if err != nil {
+		t.Fatalf("CLI FilePaths() error = %v", err)
+	}
+	sort.Strings(wantPaths)
+	sort.Strings(paths)
+	if !reflect.DeepEqual(paths, wantPaths) {
+		t.Errorf("CLI FilePaths() = %v, want %v", paths, wantPaths)
+	}
+
+	for _, c := range commits {
+		wantIns, wantDel, _, _ := goGit.CommitStats(ctx, c)
+		ins, del, files, err := cli.CommitStats(ctx, c)
+		if err != nil {
+			t.Fatalf("CLI CommitStats(%s) error = %v", c.Hash, err)
+		}
+		if ins != wantIns || del != wantDel {
+			t.Errorf("Commit %s: CLI stats +%d -%d, go-git stats +%d -%d (%v)", c.Hash, ins, del, wantIns, wantDel, files)
+		}
+	}
+
+	// Asked for again, HEAD is read on its own; the rename is reported under the new path.
+	_, _, files, err := cli.CommitStats(ctx, head)
+	if err != nil {
+		t.Fatalf("CLI CommitStats(HEAD) error = %v", err)
+	}
+	if _, ok := files["dir/renamed.txt"]; !ok || len(files) != 2 {
+		t.Errorf("CLI CommitStats(HEAD) files = %v, want a.txt and dir/renamed.txt", files)
+	}
+
+	for _, path := range wantPaths {
+		want, _ := goGit.Blame(ctx, head, path)
+		got, err := cli.Blame(ctx, head, path)
+		if err != nil {
+			t.Fatalf("CLI Blame(%s) error = %v", path, err)
+		}
+		if got.TotalLines != want.TotalLines || got.TotalCommits != want.TotalCommits ||
+			!reflect.DeepEqual(got.LinesByContributor, want.LinesByContributor) || !got.DateIntroduced.Equal(want.DateIntroduced) {
+			t.Errorf("CLI Blame(%s) = %+v, want %+v", path, got, want)
+		}
+	}
+}
+
+func TestNewBackend_Unknown(t *testing.T) {
+	if _, err := NewBackend("svn", nil, "", plumbing.ZeroHash, BackendOptions{}); err == nil {
+		t.Error("NewBackend(svn) error = nil, want an unknown backend error")
+	}
+}
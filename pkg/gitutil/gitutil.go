@@ -1,6 +1,7 @@
 package gitutil
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"strings"
@@ -8,6 +9,7 @@ import (
 
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/diff"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/user/git-inquisitor-go/internal/models"
 )
@@ -18,7 +20,7 @@ func OpenRepository(path string) (*git.Repository, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to open repository at %s: %w", path, err)
 	}
-	return repo, nil
+	return withShallowGrafts(repo)
 }
 
 // GetHeadCommit retrieves the commit object for the repository's HEAD.
@@ -68,6 +70,37 @@ func GetRepoRemoteURL(repo *git.Repository) (string, error) {
 	return "unknown (origin remote has no URL)", fmt.Errorf("'origin' remote has no URLs")
 }
 
+// GetTagCommits maps each tagged commit to the names of the tags pointing at it.
+// Annotated tags are peeled to the commit they reference; tags pointing at
+// non-commit objects (e.g. trees or blobs) are skipped.
+func GetTagCommits(repo *git.Repository) (map[plumbing.Hash][]string, error) {
+	tagIter, err := repo.Tags()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	defer tagIter.Close()
+
+	tagged := make(map[plumbing.Hash][]string)
+	err = tagIter.ForEach(func(ref *plumbing.Reference) error {
+		hash := ref.Hash()
+		if tagObj, errTag := repo.TagObject(hash); errTag == nil {
+			commit, errCommit := tagObj.Commit()
+			if errCommit != nil {
+				return nil // Annotated tag for something other than a commit
+			}
+			hash = commit.Hash
+		} else if _, errCommit := repo.CommitObject(hash); errCommit != nil {
+			return nil
+		}
+		tagged[hash] = append(tagged[hash], ref.Name().Short())
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed while iterating tags: %w", err)
+	}
+	return tagged, nil
+}
+
 // GetRepoBranch attempts to get the current branch name.
 // If in a detached HEAD state, it returns the commit SHA.
 func GetRepoBranch(repo *git.Repository, headCommit *object.Commit) (string, error) {
@@ -90,7 +123,8 @@ func GetRepoBranch(repo *git.Repository, headCommit *object.Commit) (string, err
 // A simpler approach for now is to get all commits from HEAD and then sort them if needed,
 // or process them in reverse chronological order and then reverse the collected list.
 // The Python code uses `repo.iter_commits("HEAD", reverse=True)`, which means oldest to newest.
-func IterateCommits(repo *git.Repository, head *object.Commit) ([]*object.Commit, error) {
+// Iteration stops early with ctx's error when ctx is cancelled.
+func IterateCommits(ctx context.Context, repo *git.Repository, head *object.Commit) ([]*object.Commit, error) {
 	commits := []*object.Commit{}
 	commitIter, err := repo.Log(&git.LogOptions{From: head.Hash, Order: git.LogOrderCommitterTime})
 	if err != nil {
@@ -98,6 +132,9 @@ func IterateCommits(repo *git.Repository, head *object.Commit) ([]*object.Commit
 	}
 
 	err = commitIter.ForEach(func(c *object.Commit) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		commits = append(commits, c)
 		return nil
 	})
@@ -113,6 +150,42 @@ func IterateCommits(repo *git.Repository, head *object.Commit) ([]*object.Commit
 	return commits, nil
 }
 
+// CommitRef identifies a commit by hash and committer time. It takes a small, fixed amount
+// of memory, unlike an *object.Commit, which holds the message and signature.
+type CommitRef struct {
+	Hash plumbing.Hash
+	When time.Time
+}
+
+// ListCommits is a memory-lean IterateCommits for very long histories: it returns
+// references to the commits reachable from head, oldest first, and lets go of each
+// commit object as soon as it has been visited. Resolve the references with
+// repo.CommitObject when they are processed.
+func ListCommits(ctx context.Context, repo *git.Repository, head *object.Commit) ([]CommitRef, error) {
+	commitIter, err := repo.Log(&git.LogOptions{From: head.Hash, Order: git.LogOrderCommitterTime})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit log: %w", err)
+	}
+
+	var refs []CommitRef
+	err = commitIter.ForEach(func(c *object.Commit) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		refs = append(refs, CommitRef{Hash: c.Hash, When: c.Committer.When})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed while iterating commits: %w", err)
+	}
+
+	// The log is newest first; callers process history oldest first.
+	for i, j := 0, len(refs)-1; i < j; i, j = i+1, j-1 {
+		refs[i], refs[j] = refs[j], refs[i]
+	}
+	return refs, nil
+}
+
 // GetFilePaths lists all files tracked by git at the given commit.
 // Similar to `repo.git.ls_files()` in the Python code.
 func GetFilePaths(_ *git.Repository, commit *object.Commit) ([]string, error) {
@@ -149,13 +222,20 @@ func GetFilePaths(_ *git.Repository, commit *object.Commit) ([]string, error) {
 // This is a complex function to port directly from GitPython's `repo.blame_incremental`
 // or `repo.blame`. `go-git` provides `git.Blame(c *object.Commit, path string) (*object.BlameResult, error)`.
 // We need to process `object.BlameResult.Lines` to aggregate per contributor.
-func GetBlameForFile(_ *git.Repository, commit *object.Commit, filePath string) (*models.FileBlameStats, error) {
+// When ctx is done first, ctx's error is returned (see blameContext).
+func GetBlameForFile(ctx context.Context, repo *git.Repository, commit *object.Commit, filePath string) (*models.FileBlameStats, error) {
+	return GetBlameForFileWithOptions(ctx, repo, commit, filePath, BlameOptions{})
+}
+
+// GetBlameForFileWithOptions is GetBlameForFile with blame options, such as commits to
+// ignore or whitespace-insensitive attribution (see blameWithOptions).
+func GetBlameForFileWithOptions(ctx context.Context, repo *git.Repository, commit *object.Commit, filePath string, opts BlameOptions) (*models.FileBlameStats, error) {
 	// Placeholder for the return structure
 	blameStats := &models.FileBlameStats{
 		LinesByContributor: make(map[string]int),
 	}
 
-	blameResult, err := git.Blame(commit, filePath)
+	lines, err := blameWithOptions(ctx, repo, commit, filePath, opts)
 	if err != nil {
 		// It's possible a file listed in the tree doesn't exist at this exact commit hash if it was e.g. just deleted.
 		// Or if it's a submodule, or other non-blamable type.
@@ -163,22 +243,49 @@ func GetBlameForFile(_ *git.Repository, commit *object.Commit, filePath string)
 		return blameStats, fmt.Errorf("failed to get blame for file %s at commit %s: %w", filePath, commit.Hash.String(), err)
 	}
 
-	if blameResult == nil || len(blameResult.Lines) == 0 {
+	if len(lines) == 0 {
 		return blameStats, nil // No lines or empty blame result
 	}
+	return blameStatsFromLines(lines, commit.Committer.When), nil
+}
+
+// blameLine is the attribution of a single line, as reported by either backend.
+type blameLine struct {
+	Hash       plumbing.Hash
+	Author     string // Email
+	AuthorName string
+	Date       time.Time
+}
+
+// blameStatsFromLines aggregates the attribution of a file's lines into FileBlameStats.
+// Line ages are measured up to asOf, the date of the blamed commit.
+func blameStatsFromLines(lines []blameLine, asOf time.Time) *models.FileBlameStats {
+	blameStats := &models.FileBlameStats{
+		LinesByContributor:    make(map[string]int),
+		LineAgesByContributor: make(map[string]models.AgeHistogram),
+	}
 
 	var lastCommitDate time.Time
 	var originalAuthor string
 
-	for _, line := range blameResult.Lines {
-		if line == nil || line.Author == "" { // line.Author can be empty for some commits (e.g. initial empty commit)
+	for _, line := range lines {
+		if line.Author == "" { // line.Author can be empty for some commits (e.g. initial empty commit)
 			continue
 		}
-		contributorName := strings.Split(line.Author, "<")[0]
-		contributorName = strings.TrimSpace(contributorName) // Extract name part, remove email
+		// line.Author holds the email; prefer the name so blame ownership lines up with contributor names.
+		contributorName := line.AuthorName
+		if contributorName == "" {
+			contributorName = line.Author
+		}
+		contributorName = strings.TrimSpace(strings.Split(contributorName, "<")[0])
 
 		blameStats.LinesByContributor[contributorName]++
 		blameStats.TotalLines++
+		age := asOf.Sub(line.Date)
+		blameStats.LineAges.Add(age, 1)
+		contributorAges := blameStats.LineAgesByContributor[contributorName]
+		contributorAges.Add(age, 1)
+		blameStats.LineAgesByContributor[contributorName] = contributorAges
 
 		// Track the author of the first line as potential original author
 		// and the date of the first line's commit as potential introduction date.
@@ -199,8 +306,8 @@ func GetBlameForFile(_ *git.Repository, commit *object.Commit, filePath string)
 
 	// The number of distinct commits in the blame result can be found by looking at line.Hash
 	distinctCommits := make(map[string]struct{})
-	for _, line := range blameResult.Lines {
-		if line != nil && line.Hash != plumbing.ZeroHash {
+	for _, line := range lines {
+		if line.Hash != plumbing.ZeroHash {
 			distinctCommits[line.Hash.String()] = struct{}{}
 		}
 	}
@@ -220,7 +327,31 @@ func GetBlameForFile(_ *git.Repository, commit *object.Commit, filePath string)
 		blameStats.TopContributor = fmt.Sprintf("%s (%.2f%%)", topC, percentage)
 	}
 
-	return blameStats, nil
+	return blameStats
+}
+
+// blameContext runs git.Blame, returning early with ctx's error when ctx is done first.
+// go-git's blame cannot be interrupted: an abandoned blame keeps running in the
+// background until it completes, and its result is discarded.
+func blameContext(ctx context.Context, commit *object.Commit, filePath string) (*git.BlameResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	type blameOutcome struct {
+		result *git.BlameResult
+		err    error
+	}
+	done := make(chan blameOutcome, 1) // Buffered so an abandoned blame can always finish
+	go func() {
+		result, err := git.Blame(commit, filePath)
+		done <- blameOutcome{result, err}
+	}()
+	select {
+	case outcome := <-done:
+		return outcome.result, outcome.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
 }
 
 // FileBlameStats has been moved to models.FileBlameStats
@@ -230,8 +361,12 @@ func GetBlameForFile(_ *git.Repository, commit *object.Commit, filePath string)
 // It requires comparing a commit to its parent(s).
 // For merge commits, it might be more complex if we want diff against each parent.
 // The Python code uses `commit.stats.total` and `commit.stats.files`.
-func GetCommitStats(commit *object.Commit) (insertions, deletions int, filesChanged map[string]models.FileCommitStats, err error) {
+// Diff computation is abandoned with ctx's error when ctx is cancelled.
+func GetCommitStats(ctx context.Context, commit *object.Commit) (insertions, deletions int, filesChanged map[string]models.FileCommitStats, err error) {
 	filesChanged = make(map[string]models.FileCommitStats)
+	if err := ctx.Err(); err != nil {
+		return 0, 0, nil, err
+	}
 
 	if commit.NumParents() == 0 {
 		// Initial commit: stats are based on the content of the commit itself
@@ -262,17 +397,11 @@ func GetCommitStats(commit *object.Commit) (insertions, deletions int, filesChan
 		return 0, 0, nil, fmt.Errorf("could not get parent for commit %s: %w", commit.Hash, err)
 	}
 
-	patch, err := parentCommit.Patch(commit)
+	patch, err := parentCommit.PatchContext(ctx, commit)
 	if err != nil {
 		return 0, 0, nil, fmt.Errorf("could not generate patch between %s and %s: %w", parentCommit.Hash, commit.Hash, err)
 	}
 
-	overallStats := patch.Stats()
-	if len(overallStats) > 0 { // Patch.Stats() returns a slice, usually with one element for overall.
-		insertions = overallStats[0].Addition
-		deletions = overallStats[0].Deletion
-	}
-
 	for _, filePatch := range patch.FilePatches() {
 		from, to := filePatch.Files()
 		var fileName string
@@ -289,14 +418,16 @@ func GetCommitStats(commit *object.Commit) (insertions, deletions int, filesChan
 		var addition, deletion int
 		for _, chunk := range filePatch.Chunks() {
 			switch chunk.Type() {
-			case 0: // Equal
+			case diff.Equal:
 				continue
-			case 1: // Add
-				addition += len(chunk.Content())
-			case 2: // Delete
-				deletion += len(chunk.Content())
+			case diff.Add:
+				addition += countLines([]byte(chunk.Content()))
+			case diff.Delete:
+				deletion += countLines([]byte(chunk.Content()))
 			}
 		}
+		insertions += addition
+		deletions += deletion
 		// 'Lines' in FileCommitStats is total lines in file after commit.
 		// This is hard to get from patch alone. Need to inspect the file in 'commit.Tree()'.
 		// For now, we'll leave it 0 or approximate. Python's GitPython might be doing more.
@@ -324,10 +455,9 @@ func GetCommitStats(commit *object.Commit) (insertions, deletions int, filesChan
 	return insertions, deletions, filesChanged, nil
 }
 
-// GetGitVersion returns the version of the git command line tool.
-// go-git is a pure Go implementation and doesn't rely on the git CLI,
-// so this function might need to execute `git --version` if that specific info is required.
-// For now, we can return a string indicating go-git is used.
+// GetGitVersion describes the git implementation used by the go-git backend.
+// go-git is a pure Go implementation and doesn't rely on the git CLI;
+// the CLI backend reports the output of `git --version` instead (see CLIBackend.Version).
 func GetGitVersion() (string, error) {
 	// This is different from Python's GitPython which can get underlying git version.
 	// For go-git, we are the "git implementation".
@@ -0,0 +1,106 @@
+package gitutil
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// DefaultIgnoreRevsFile is the conventional ignore-revs file at the repository root,
+// also honoured by GitHub's blame view.
+const DefaultIgnoreRevsFile = ".git-blame-ignore-revs"
+
+// LoadIgnoreRevs collects the commits to ignore in blame, as git does: those listed in
+// the file named by the blame.ignoreRevsFile setting and in file (such as
+// DefaultIgnoreRevsFile; empty for none), and the revisions in extra (hashes, abbreviated
+// hashes or any other revision). Files are read from the tree of commit, so bare mirrors
+// work too; absolute paths are read from disk. Missing files are not an error.
+// Entries of the files that do not resolve, such as commits missing from a shallow
+// clone, are left out and returned in skipped; revisions in extra must resolve.
+func LoadIgnoreRevs(repo *git.Repository, commit *object.Commit, file string, extra []string) (hashes []plumbing.Hash, skipped []string, err error) {
+	var files []string
+	if cfg, errConfig := repo.Config(); errConfig == nil {
+		if configured := cfg.Raw.Section("blame").Option("ignoreRevsFile"); configured != "" {
+			files = append(files, configured)
+		}
+	}
+	if file != "" && (len(files) == 0 || files[0] != file) {
+		files = append(files, file)
+	}
+
+	seen := make(map[plumbing.Hash]bool)
+	add := func(hash plumbing.Hash) {
+		if !seen[hash] {
+			seen[hash] = true
+			hashes = append(hashes, hash)
+		}
+	}
+	for _, name := range files {
+		content, err := readIgnoreRevsFile(commit, name)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, rev := range parseIgnoreRevs(content) {
+			hash, errResolve := repo.ResolveRevision(plumbing.Revision(rev))
+			if errResolve != nil {
+				skipped = append(skipped, fmt.Sprintf("%s (listed in %s): %v", rev, name, errResolve))
+				continue
+			}
+			add(*hash)
+		}
+	}
+	for _, rev := range extra {
+		hash, err := repo.ResolveRevision(plumbing.Revision(rev))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to resolve ignored revision %q: %w", rev, err)
+		}
+		add(*hash)
+	}
+	return hashes, skipped, nil
+}
+
+// readIgnoreRevsFile reads an ignore-revs file from the tree of commit, or from disk for
+// absolute paths. It returns "" if the file does not exist.
+func readIgnoreRevsFile(commit *object.Commit, name string) (string, error) {
+	if filepath.IsAbs(name) {
+		content, err := os.ReadFile(name) // #nosec G304 -- path configured by the repository owner
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read ignore-revs file %s: %w", name, err)
+		}
+		return string(content), nil
+	}
+	file, err := commit.File(filepath.ToSlash(filepath.Clean(name)))
+	if err == object.ErrFileNotFound {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read ignore-revs file %s: %w", name, err)
+	}
+	return file.Contents()
+}
+
+// parseIgnoreRevs returns the revisions listed in an ignore-revs file, skipping blank
+// lines and comments.
+func parseIgnoreRevs(content string) []string {
+	var revs []string
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			revs = append(revs, line)
+		}
+	}
+	return revs
+}
@@ -0,0 +1,103 @@
+package gitutil
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseIgnoreRevs(t *testing.T) {
+	content := "# Reformatting\nabc123\n\n  def456  # with a comment\n#ghi789\n"
+	want := []string{"abc123", "def456"}
+	if got := parseIgnoreRevs(content); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseIgnoreRevs() = %v, want %v", got, want)
+	}
+}
+
+func TestBlame_IgnoreRevs(t *testing.T) {
+	repoPath, cleanup := createTestRepo(t)
+	defer cleanup()
+
+	commit := func(author, name, content string) string {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(repoPath, name), []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		if out, err := exec.Command("git", "-C", repoPath, "add", "-A").CombinedOutput(); err != nil {
+			t.Fatalf("Failed to git add: %v: %s", err, out)
+		}
+		cmd := exec.Command("git", "-C", repoPath, "commit", "-m", "change", "--author", author+" <"+strings.ToLower(author)+"@example.com>")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("Failed to git commit: %v: %s", err, out)
+		}
+		out, err := exec.Command("git", "-C", repoPath, "rev-parse", "HEAD").Output()
+		if err != nil {
+			t.Fatalf("Failed to git rev-parse: %v", err)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	commit("Alice", "code.go", "func a() {\n\treturn 1\n}\n")
+	reformat := commit("Bob", "code.go", "func a() {\n    return 1\n}\n")
+	commit("Carol", "code.go", "func a() {\n    return 1\n}\n\nfunc b() {}\n")
+	commit("Carol", DefaultIgnoreRevsFile, "# Indentation\n"+reformat+"\n0000000000000000000000000000000000000001\n")
+
+	repo, err := OpenRepository(repoPath)
+	if err != nil {
+		t.Fatalf("OpenRepository() error = %v", err)
+	}
+	head, _ := GetHeadCommit(repo)
+	ctx := context.Background()
+
+	// Like git, the file is only read when named.
+	if ignoreRevs, _, err := LoadIgnoreRevs(repo, head, "", nil); err != nil || len(ignoreRevs) != 0 {
+		t.Errorf("LoadIgnoreRevs() without a file = %v, %v, want none", ignoreRevs, err)
+	}
+	ignoreRevs, skipped, err := LoadIgnoreRevs(repo, head, DefaultIgnoreRevsFile, nil)
+	if err != nil {
+		t.Fatalf("LoadIgnoreRevs() error = %v", err)
+	}
+	if len(ignoreRevs) != 1 || ignoreRevs[0].String() != reformat {
+		t.Fatalf("LoadIgnoreRevs() = %v, want [%s] from %s", ignoreRevs, reformat, DefaultIgnoreRevsFile)
+	}
+	if len(skipped) != 1 || !strings.HasPrefix(skipped[0], "0000000000000000000000000000000000000001 ") {
+		t.Errorf("LoadIgnoreRevs() skipped = %v, want the unknown commit", skipped)
+	}
+	if _, _, err := LoadIgnoreRevs(repo, head, "", []string{"no-such-revision"}); err == nil {
+		t.Error("LoadIgnoreRevs(no-such-revision) error = nil, want an error")
+	}
+
+	if out, err := exec.Command("git", "-C", repoPath, "config", "blame.ignoreRevsFile", DefaultIgnoreRevsFile).CombinedOutput(); err != nil {
+		t.Fatalf("Failed to git config: %v: %s", err, out)
+	}
+	if configured, _, err := LoadIgnoreRevs(repo, head, "", nil); err != nil || !reflect.DeepEqual(configured, ignoreRevs) {
+		t.Errorf("LoadIgnoreRevs() with blame.ignoreRevsFile = %v, %v, want %v", configured, err, ignoreRevs)
+	}
+
+	plain, err := GetBlameForFile(ctx, repo, head, "code.go")
+	if err != nil {
+		t.Fatalf("GetBlameForFile() error = %v", err)
+	}
+	if plain.LinesByContributor["Bob"] != 1 {
+		t.Errorf("GetBlameForFile() = %v, want 1 line for Bob", plain.LinesByContributor)
+	}
+
+	want := map[string]int{"Alice": 3, "Carol": 2}
+	opts := BackendOptions{Blame: BlameOptions{IgnoreRevs: ignoreRevs}}
+	for _, name := range []string{BackendGoGit, BackendCLI} {
+		backend, err := NewBackend(name, repo, repoPath, head.Hash, opts)
+		if err != nil {
+			t.Fatalf("NewBackend(%s) error = %v", name, err)
+		}
+		stats, err := backend.Blame(ctx, head, "code.go")
+		if err != nil {
+			t.Fatalf("%s Blame() error = %v", name, err)
+		}
+		if !reflect.DeepEqual(stats.LinesByContributor, want) {
+			t.Errorf("%s Blame() ignoring %s = %v, want %v", name, reformat[:7], stats.LinesByContributor, want)
+		}
+	}
+}
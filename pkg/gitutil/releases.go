@@ -0,0 +1,43 @@
+package gitutil
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// FirstReleases attributes commits to the first release that contains them. releases
+// are the tagged commits of the releases, oldest release first; the result maps every
+// commit reachable from one of them to the index of the first release it is reachable
+// from. A release whose commit an earlier release already contains gets no commits.
+// Commits reachable from no release are left out.
+func FirstReleases(ctx context.Context, repo *git.Repository, releases []plumbing.Hash) (map[plumbing.Hash]int, error) {
+	releaseOf := make(map[plumbing.Hash]int)
+	for i, tagged := range releases {
+		if _, ok := releaseOf[tagged]; ok {
+			continue // Its whole ancestry is attributed already
+		}
+		releaseOf[tagged] = i
+		pending := []plumbing.Hash{tagged}
+		for len(pending) > 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			hash := pending[len(pending)-1]
+			pending = pending[:len(pending)-1]
+			commit, err := repo.CommitObject(hash)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read commit %s: %w", hash, err)
+			}
+			for _, parent := range commit.ParentHashes {
+				if _, ok := releaseOf[parent]; !ok {
+					releaseOf[parent] = i
+					pending = append(pending, parent)
+				}
+			}
+		}
+	}
+	return releaseOf, nil
+}
@@ -0,0 +1,144 @@
+package gitutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// scpLikeURL matches scp-style remotes such as git@github.com:org/repo.git.
+var scpLikeURL = regexp.MustCompile(`^[\w.-]+@[\w.-]+:[^/\\]`)
+
+// CloneOptions controls how a remote repository is mirrored for analysis.
+type CloneOptions struct {
+	Depth        int  // Limit history to the given number of commits; 0 fetches everything
+	SingleBranch bool // Only fetch the remote's default branch
+}
+
+// IsRemoteURL reports whether target refers to a remote repository (including file:// URLs)
+// rather than a local path.
+func IsRemoteURL(target string) bool {
+	if strings.Contains(target, "://") {
+		return true
+	}
+	return scpLikeURL.MatchString(target)
+}
+
+// MirrorRepository clones url as a bare repository into dir, or updates the existing
+// bare clone in dir. The local branches of the clone track the remote's branches
+// so HEAD always resolves to the remote's current default branch tip.
+// A new clone is written to a temporary sibling of dir and renamed into place once
+// complete, so an interrupted clone never leaves a partial mirror at dir; an existing
+// mirror that cannot be opened is cloned again. Callers running concurrently against
+// the same dir must serialize their calls.
+// Cloning and fetching are aborted when ctx is cancelled.
+func MirrorRepository(ctx context.Context, url, dir string, opts CloneOptions) (*git.Repository, error) {
+	if _, err := os.Stat(dir); err == nil {
+		repo, errOpen := git.PlainOpen(dir)
+		if errOpen == nil {
+			if err := updateMirror(ctx, repo, opts); err != nil {
+				return nil, fmt.Errorf("failed to update mirror of %s at %s: %w", url, dir, err)
+			}
+			return withShallowGrafts(repo)
+		}
+		// Most likely left behind half-written by an older, interrupted run.
+		if err := os.RemoveAll(dir); err != nil {
+			return nil, fmt.Errorf("failed to remove unreadable mirror at %s (%v): %w", dir, errOpen, err)
+		}
+	}
+
+	tempDir, err := os.MkdirTemp(filepath.Dir(dir), filepath.Base(dir)+".clone-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary clone directory for %s: %w", dir, err)
+	}
+	cloneOpts := &git.CloneOptions{
+		URL:          url,
+		Depth:        opts.Depth,
+		SingleBranch: opts.SingleBranch,
+		Tags:         git.AllTags,
+	}
+	if opts.SingleBranch {
+		cloneOpts.Tags = git.NoTags
+	}
+	if _, err := git.PlainCloneContext(ctx, tempDir, true, cloneOpts); err != nil {
+		_ = os.RemoveAll(tempDir)
+		return nil, fmt.Errorf("failed to clone %s into %s: %w", url, dir, err)
+	}
+	if err := os.Rename(tempDir, dir); err != nil {
+		_ = os.RemoveAll(tempDir)
+		return nil, fmt.Errorf("failed to move clone of %s into %s: %w", url, dir, err)
+	}
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open mirror at %s: %w", dir, err)
+	}
+	return withShallowGrafts(repo)
+}
+
+// updateMirror fetches the remote's branches (or only its default branch) into the bare
+// clone, pruning the branches deleted on the remote, and points HEAD at the remote's
+// current default branch, which may have changed since the clone.
+func updateMirror(ctx context.Context, repo *git.Repository, opts CloneOptions) error {
+	headRef, err := repo.Reference(plumbing.HEAD, false)
+	if err != nil {
+		return fmt.Errorf("failed to read HEAD of mirror: %w", err)
+	}
+	branch := headRef.Target()
+	if remoteBranch, err := remoteHead(ctx, repo); err != nil {
+		return err
+	} else if remoteBranch != "" {
+		branch = remoteBranch
+	}
+
+	refSpecs := []config.RefSpec{"+refs/heads/*:refs/heads/*"}
+	tags := git.AllTags
+	if opts.SingleBranch {
+		refSpecs = []config.RefSpec{config.RefSpec(fmt.Sprintf("+%s:%s", branch, branch))}
+		tags = git.NoTags
+	}
+
+	err = repo.FetchContext(ctx, &git.FetchOptions{
+		RemoteName: git.DefaultRemoteName,
+		RefSpecs:   refSpecs,
+		Depth:      opts.Depth,
+		Tags:       tags,
+		Force:      true,
+		Prune:      true,
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return err
+	}
+	if branch != headRef.Target() {
+		if err := repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, branch)); err != nil {
+			return fmt.Errorf("failed to point HEAD of mirror at %s: %w", branch, err)
+		}
+	}
+	return nil
+}
+
+// remoteHead returns the branch the remote's HEAD points at, or "" if the remote does
+// not advertise it.
+func remoteHead(ctx context.Context, repo *git.Repository) (plumbing.ReferenceName, error) {
+	remote, err := repo.Remote(git.DefaultRemoteName)
+	if err != nil {
+		return "", fmt.Errorf("failed to read remote of mirror: %w", err)
+	}
+	refs, err := remote.ListContext(ctx, &git.ListOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to list remote references: %w", err)
+	}
+	for _, ref := range refs {
+		if ref.Name() == plumbing.HEAD && ref.Type() == plumbing.SymbolicReference {
+			return ref.Target(), nil
+		}
+	}
+	return "", nil
+}
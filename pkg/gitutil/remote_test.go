@@ -0,0 +1,157 @@
+package gitutil
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+func TestIsRemoteURL(t *testing.T) {
+	testCases := []struct {
+		target string
+		want   bool
+	}{
+		{"https://github.com/org/repo.git", true},
+		{"ssh://git@github.com/org/repo.git", true},
+		{"file:///tmp/repo", true},
+		{"git@github.com:org/repo.git", true},
+		{"/home/user/repo", false},
+		{"./repo", false},
+		{`C:\src\repo`, false},
+	}
+	for _, tc := range testCases {
+		if got := IsRemoteURL(tc.target); got != tc.want {
+			t.Errorf("IsRemoteURL(%q) = %v, want %v", tc.target, got, tc.want)
+		}
+	}
+}
+
+func TestMirrorRepository_FileRemote(t *testing.T) {
+	repoPath, cleanup := createTestRepo(t)
+	defer cleanup()
+
+	commit := func(content, message string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(repoPath, "f.txt"), []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		if err := exec.Command("git", "-C", repoPath, "add", ".").Run(); err != nil {
+			t.Fatalf("Failed to git add: %v", err)
+		}
+		if err := exec.Command("git", "-C", repoPath, "commit", "-m", message).Run(); err != nil {
+			t.Fatalf("Failed to git commit: %v", err)
+		}
+	}
+	commit("one\n", "first")
+	commit("one\ntwo\n", "second")
+
+	mirrorDir := filepath.Join(t.TempDir(), "mirror.git")
+	url := "file://" + repoPath
+
+	repo, err := MirrorRepository(context.Background(), url, mirrorDir, CloneOptions{Depth: 1})
+	if err != nil {
+		t.Fatalf("MirrorRepository() clone error = %v", err)
+	}
+	head, err := GetHeadCommit(repo)
+	if err != nil {
+		t.Fatalf("GetHeadCommit() on mirror error = %v", err)
+	}
+	if head.Message != "second\n" {
+		t.Errorf("Mirror HEAD message = %q, want %q", head.Message, "second\n")
+	}
+	commits, err := IterateCommits(context.Background(), repo, head)
+	if err != nil {
+		t.Fatalf("IterateCommits() on shallow mirror error = %v", err)
+	}
+	if len(commits) != 1 {
+		t.Errorf("Shallow mirror has %d commits, want 1", len(commits))
+	}
+	// The shallow boundary commit has a missing parent and must be treated as a root commit.
+	if _, _, _, err := GetCommitStats(context.Background(), head); err != nil {
+		t.Errorf("GetCommitStats() on shallow boundary error = %v", err)
+	}
+
+	// A new upstream commit must show up when the mirror is refreshed.
+	commit("one\ntwo\nthree\n", "third")
+	repo, err = MirrorRepository(context.Background(), url, mirrorDir, CloneOptions{})
+	if err != nil {
+		t.Fatalf("MirrorRepository() update error = %v", err)
+	}
+	head, err = GetHeadCommit(repo)
+	if err != nil {
+		t.Fatalf("GetHeadCommit() on updated mirror error = %v", err)
+	}
+	if head.Message != "third\n" {
+		t.Errorf("Updated mirror HEAD message = %q, want %q", head.Message, "third\n")
+	}
+
+	// A new default branch must be followed, and the deleted one pruned.
+	oldBranch, err := exec.Command("git", "-C", repoPath, "symbolic-ref", "HEAD").Output()
+	if err != nil {
+		t.Fatalf("Failed to git symbolic-ref: %v", err)
+	}
+	if out, err := exec.Command("git", "-C", repoPath, "checkout", "-b", "trunk").CombinedOutput(); err != nil {
+		t.Fatalf("Failed to git checkout: %v: %s", err, out)
+	}
+	commit("one\ntwo\nthree\nfour\n", "fourth")
+	if out, err := exec.Command("git", "-C", repoPath, "update-ref", "-d", strings.TrimSpace(string(oldBranch))).CombinedOutput(); err != nil {
+		t.Fatalf("Failed to delete the old branch: %v: %s", err, out)
+	}
+	repo, err = MirrorRepository(context.Background(), url, mirrorDir, CloneOptions{})
+	if err != nil {
+		t.Fatalf("MirrorRepository() update error = %v", err)
+	}
+	if ref, err := repo.Reference(plumbing.HEAD, false); err != nil || ref.Target() != "refs/heads/trunk" {
+		t.Errorf("Mirror HEAD = %v, %v, want refs/heads/trunk", ref, err)
+	}
+	if head, err = GetHeadCommit(repo); err != nil || head.Message != "fourth\n" {
+		t.Errorf("Mirror HEAD commit = %v, %v, want the fourth commit", head, err)
+	}
+	if _, err := repo.Reference(plumbing.ReferenceName(strings.TrimSpace(string(oldBranch))), false); err == nil {
+		t.Errorf("Mirror still has the deleted branch %s", strings.TrimSpace(string(oldBranch)))
+	}
+}
+
+func TestMirrorRepository_RecoversUnreadableMirror(t *testing.T) {
+	repoPath, cleanup := createTestRepo(t)
+	defer cleanup()
+	if err := os.WriteFile(filepath.Join(repoPath, "f.txt"), []byte("one\n"), 0600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := exec.Command("git", "-C", repoPath, "add", ".").Run(); err != nil {
+		t.Fatalf("Failed to git add: %v", err)
+	}
+	if err := exec.Command("git", "-C", repoPath, "commit", "-m", "first").Run(); err != nil {
+		t.Fatalf("Failed to git commit: %v", err)
+	}
+
+	// A directory left behind by an interrupted clone is not a repository.
+	mirrorDir := filepath.Join(t.TempDir(), "mirror.git")
+	if err := os.MkdirAll(filepath.Join(mirrorDir, "objects"), 0755); err != nil {
+		t.Fatalf("Failed to create partial mirror: %v", err)
+	}
+
+	repo, err := MirrorRepository(context.Background(), "file://"+repoPath, mirrorDir, CloneOptions{})
+	if err != nil {
+		t.Fatalf("MirrorRepository() error = %v", err)
+	}
+	head, err := GetHeadCommit(repo)
+	if err != nil {
+		t.Fatalf("GetHeadCommit() on re-cloned mirror error = %v", err)
+	}
+	if head.Message != "first\n" {
+		t.Errorf("Re-cloned mirror HEAD message = %q, want %q", head.Message, "first\n")
+	}
+	entries, err := os.ReadDir(filepath.Dir(mirrorDir))
+	if err != nil {
+		t.Fatalf("Failed to read mirrors dir: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Mirrors dir has %d entries, want only the mirror (temporary clone left behind?)", len(entries))
+	}
+}
@@ -0,0 +1,94 @@
+package gitutil
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/storage"
+)
+
+// withShallowGrafts reopens repo so that the boundary commits of a shallow clone
+// appear as root commits, the same way git itself grafts them. Without this,
+// go-git's log and blame fail with "object not found" when they reach a parent
+// that was never fetched. Complete (non-shallow) repositories are returned as is.
+func withShallowGrafts(repo *git.Repository) (*git.Repository, error) {
+	shallow, err := repo.Storer.Shallow()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read shallow commits: %w", err)
+	}
+	if len(shallow) == 0 {
+		return repo, nil
+	}
+
+	grafts := make(map[plumbing.Hash]bool, len(shallow))
+	for _, hash := range shallow {
+		grafts[hash] = true
+	}
+
+	var worktree billy.Filesystem
+	if wt, errWt := repo.Worktree(); errWt == nil {
+		worktree = wt.Filesystem
+	}
+	grafted, err := git.Open(&graftedStorer{Storer: repo.Storer, grafts: grafts}, worktree)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reopen shallow repository: %w", err)
+	}
+	return grafted, nil
+}
+
+// graftedStorer strips the parents of shallow boundary commits.
+type graftedStorer struct {
+	storage.Storer
+	grafts map[plumbing.Hash]bool
+}
+
+// EncodedObject returns the stored object, rewriting grafted commits without their parent lines.
+func (gs *graftedStorer) EncodedObject(t plumbing.ObjectType, h plumbing.Hash) (plumbing.EncodedObject, error) {
+	obj, err := gs.Storer.EncodedObject(t, h)
+	if err != nil || !gs.grafts[h] || obj.Type() != plumbing.CommitObject {
+		return obj, err
+	}
+
+	reader, err := obj.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	return &graftedObject{EncodedObject: obj, content: stripParents(content)}, nil
+}
+
+// stripParents removes the "parent" header lines from a raw commit object.
+func stripParents(content []byte) []byte {
+	headerEnd := bytes.Index(content, []byte("\n\n"))
+	if headerEnd == -1 {
+		headerEnd = len(content)
+	}
+	var out bytes.Buffer
+	for _, line := range bytes.SplitAfter(content[:headerEnd], []byte("\n")) {
+		if !bytes.HasPrefix(line, []byte("parent ")) {
+			out.Write(line)
+		}
+	}
+	out.Write(content[headerEnd:])
+	return out.Bytes()
+}
+
+// graftedObject keeps the original object hash while serving rewritten content.
+type graftedObject struct {
+	plumbing.EncodedObject
+	content []byte
+}
+
+func (o *graftedObject) Size() int64 { return int64(len(o.content)) }
+
+func (o *graftedObject) Reader() (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(o.content)), nil
+}
@@ -0,0 +1,281 @@
+package gitutil
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"sync"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/user/git-inquisitor-go/internal/models"
+)
+
+// maxLineCountEntries bounds the number of blobs remembered by a LineCountCache.
+const maxLineCountEntries = 1 << 20
+
+// maxDiffCost bounds the work of lineDiffCounts, measured as the edit distance explored
+// times the number of lines left after trimming the common prefix and suffix.
+const maxDiffCost = 1 << 26
+
+// binarySniffLen is how much of a blob is inspected for NUL bytes, as git does.
+const binarySniffLen = 8000
+
+// LineCountCache remembers the line count of blobs by hash, so files that reappear
+// unchanged across commits (or in several commits' trees) are read only once.
+// It is safe for concurrent use. Binary blobs are remembered with a count of -1.
+type LineCountCache struct {
+	mu     sync.Mutex
+	counts map[plumbing.Hash]int
+}
+
+// NewLineCountCache returns an empty LineCountCache.
+func NewLineCountCache() *LineCountCache {
+	return &LineCountCache{counts: make(map[plumbing.Hash]int)}
+}
+
+func (c *LineCountCache) get(hash plumbing.Hash) (int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	count, ok := c.counts[hash]
+	return count, ok
+}
+
+func (c *LineCountCache) put(hash plumbing.Hash, count int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.counts) >= maxLineCountEntries {
+		c.counts = make(map[plumbing.Hash]int) // Start over rather than grow without bound
+	}
+	c.counts[hash] = count
+}
+
+// GetCommitStatsFast is a faster equivalent of GetCommitStats for long histories.
+// It diffs the trees of commit and its first parent by hash, so unchanged files and
+// directories are never read, takes line counts of added and deleted files from
+// lineCounts, and counts the insertions and deletions of modified files with a Myers
+// diff over line hashes instead of building a textual patch. Files too different to diff
+// cheaply count as entirely removed and re-added. Binary files are reported with zero
+// counts. lineCounts may be nil.
+func GetCommitStatsFast(ctx context.Context, commit *object.Commit, lineCounts *LineCountCache) (insertions, deletions int, filesChanged map[string]models.FileCommitStats, err error) {
+	if err := ctx.Err(); err != nil {
+		return 0, 0, nil, err
+	}
+	if lineCounts == nil {
+		lineCounts = NewLineCountCache()
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("could not get tree for commit %s: %w", commit.Hash, err)
+	}
+	var parentTree *object.Tree // Nil for the initial commit: everything is added
+	if commit.NumParents() > 0 {
+		parentCommit, errParent := commit.Parent(0)
+		if errParent != nil {
+			return 0, 0, nil, fmt.Errorf("could not get parent for commit %s: %w", commit.Hash, errParent)
+		}
+		if parentTree, err = parentCommit.Tree(); err != nil {
+			return 0, 0, nil, fmt.Errorf("could not get tree for commit %s: %w", parentCommit.Hash, err)
+		}
+	}
+
+	changes, err := object.DiffTreeWithOptions(ctx, parentTree, tree, object.DefaultDiffTreeOptions)
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("could not diff trees of commit %s: %w", commit.Hash, err)
+	}
+
+	filesChanged = make(map[string]models.FileCommitStats, len(changes))
+	for _, change := range changes {
+		if err := ctx.Err(); err != nil {
+			return 0, 0, nil, err
+		}
+		fileName := change.To.Name
+		if fileName == "" {
+			fileName = change.From.Name // File was deleted
+		}
+		stats, errChange := changeStats(ctx, change, lineCounts)
+		if errChange != nil {
+			return 0, 0, nil, fmt.Errorf("could not compute stats for %s in commit %s: %w", fileName, commit.Hash, errChange)
+		}
+		if parentTree == nil && stats == nil {
+			continue // Binary files of the initial commit are not listed, as in GetCommitStats
+		}
+		if stats == nil {
+			stats = &models.FileCommitStats{}
+		}
+		insertions += stats.Insertions
+		deletions += stats.Deletions
+		filesChanged[fileName] = *stats
+	}
+	return insertions, deletions, filesChanged, nil
+}
+
+// changeStats computes the line stats of a single tree change.
+// It returns nil stats when either side is binary or not a file (such as a submodule).
+func changeStats(ctx context.Context, change *object.Change, lineCounts *LineCountCache) (*models.FileCommitStats, error) {
+	from, to := change.From, change.To
+	switch {
+	case from.Name == "": // Added
+		count, err := blobLineCount(to, lineCounts)
+		if err != nil || count < 0 {
+			return nil, err
+		}
+		return &models.FileCommitStats{Insertions: count, Lines: count}, nil
+	case to.Name == "": // Deleted
+		count, err := blobLineCount(from, lineCounts)
+		if err != nil || count < 0 {
+			return nil, err
+		}
+		return &models.FileCommitStats{Deletions: count}, nil
+	}
+
+	// Modified (or renamed): only now are both blobs read in full.
+	oldLines, err := blobLines(from)
+	if err != nil || oldLines == nil {
+		return nil, err
+	}
+	newLines, err := blobLines(to)
+	if err != nil || newLines == nil {
+		return nil, err
+	}
+	lineCounts.put(to.TreeEntry.Hash, len(newLines))
+	added, removed, err := lineDiffCounts(ctx, oldLines, newLines)
+	if err != nil {
+		return nil, err
+	}
+	return &models.FileCommitStats{Insertions: added, Deletions: removed, Lines: len(newLines)}, nil
+}
+
+// blobLineCount returns the line count of the blob of entry, or -1 if it is binary.
+func blobLineCount(entry object.ChangeEntry, lineCounts *LineCountCache) (int, error) {
+	if !isFileMode(entry.TreeEntry.Mode) {
+		return -1, nil
+	}
+	if count, ok := lineCounts.get(entry.TreeEntry.Hash); ok {
+		return count, nil
+	}
+	content, err := blobContent(entry)
+	if err != nil {
+		return 0, err
+	}
+	count := -1
+	if !isBinaryContent(content) {
+		count = countLines(content)
+	}
+	lineCounts.put(entry.TreeEntry.Hash, count)
+	return count, nil
+}
+
+// blobLines returns the hashes of the lines of the blob of entry, or nil if it is binary.
+func blobLines(entry object.ChangeEntry) ([]uint64, error) {
+	if !isFileMode(entry.TreeEntry.Mode) {
+		return nil, nil
+	}
+	content, err := blobContent(entry)
+	if err != nil || isBinaryContent(content) {
+		return nil, err
+	}
+	lines := make([]uint64, 0, countLines(content))
+	for len(content) > 0 {
+		end := bytes.IndexByte(content, '\n') + 1
+		if end == 0 {
+			end = len(content) // Last line without a newline differs from the same line with one, as in git
+		}
+		lineHash := fnv.New64a()
+		lineHash.Write(content[:end])
+		lines = append(lines, lineHash.Sum64())
+		content = content[end:]
+	}
+	return lines, nil
+}
+
+// blobContent reads the blob of a change entry.
+func blobContent(entry object.ChangeEntry) ([]byte, error) {
+	file, err := entry.Tree.TreeEntryFile(&entry.TreeEntry)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := file.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// isFileMode reports whether mode denotes blob content (a file or a symlink).
+func isFileMode(mode filemode.FileMode) bool {
+	return mode != filemode.Submodule && mode != filemode.Dir
+}
+
+// isBinaryContent reports whether content looks binary, using git's heuristic of a NUL byte
+// in the first few kilobytes.
+func isBinaryContent(content []byte) bool {
+	if len(content) > binarySniffLen {
+		content = content[:binarySniffLen]
+	}
+	return bytes.IndexByte(content, 0) >= 0
+}
+
+// countLines counts lines the way object.File.Lines does: a final line without a newline counts.
+func countLines(content []byte) int {
+	count := bytes.Count(content, []byte{'\n'})
+	if len(content) > 0 && content[len(content)-1] != '\n' {
+		count++
+	}
+	return count
+}
+
+// lineDiffCounts returns the number of lines added and removed by a shortest edit script
+// turning a into b, computed with Myers' O(ND) algorithm after trimming the common prefix and suffix.
+// Once the search exceeds maxDiffCost it gives up and counts every remaining line of a as
+// removed and of b as added. It returns ctx's error if ctx is cancelled during the search.
+func lineDiffCounts(ctx context.Context, a, b []uint64) (added, removed int, err error) {
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		a, b = a[1:], b[1:]
+	}
+	for len(a) > 0 && len(b) > 0 && a[len(a)-1] == b[len(b)-1] {
+		a, b = a[:len(a)-1], b[:len(b)-1]
+	}
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 {
+		return m, n, nil
+	}
+
+	// v[offset+k] is the furthest x reached on diagonal k = x - y.
+	maxD := n + m
+	offset := maxD + 1
+	v := make([]int, 2*maxD+3)
+	for d := 0; d <= maxD; d++ {
+		if err := ctx.Err(); err != nil {
+			return 0, 0, err
+		}
+		if d*(n+m) > maxDiffCost {
+			return m, n, nil // Too expensive: count the differing region as rewritten
+		}
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1] // Step down: insertion
+			} else {
+				x = v[offset+k-1] + 1 // Step right: deletion
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				// d edits; the longest common subsequence has (n+m-d)/2 lines.
+				common := (n + m - d) / 2
+				return m - common, n - common, nil
+			}
+		}
+	}
+	return m, n, nil // Unreachable: d = n+m always reaches the end
+}
@@ -0,0 +1,130 @@
+package gitutil
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestLineDiffCounts(t *testing.T) {
+	tests := []struct {
+		name           string
+		a, b           []uint64
+		added, removed int
+	}{
+		{"identical", []uint64{1, 2, 3}, []uint64{1, 2, 3}, 0, 0},
+		{"append", []uint64{1, 2}, []uint64{1, 2, 3, 4}, 2, 0},
+		{"delete middle", []uint64{1, 2, 3}, []uint64{1, 3}, 0, 1},
+		{"replace", []uint64{1, 2, 3}, []uint64{1, 9, 3}, 1, 1},
+		{"from empty", nil, []uint64{1, 2}, 2, 0},
+		{"to empty", []uint64{1, 2}, nil, 0, 2},
+		{"move", []uint64{1, 2, 3, 4}, []uint64{2, 3, 4, 1}, 1, 1},
+		{"interleaved", []uint64{1, 2, 3, 4, 5}, []uint64{6, 2, 7, 4, 8}, 3, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			added, removed, err := lineDiffCounts(context.Background(), tt.a, tt.b)
+			if err != nil {
+				t.Fatalf("lineDiffCounts() error = %v", err)
+			}
+			if added != tt.added || removed != tt.removed {
+				t.Errorf("lineDiffCounts() = +%d -%d, want +%d -%d", added, removed, tt.added, tt.removed)
+			}
+		})
+	}
+}
+
+func TestLineDiffCounts_Limits(t *testing.T) {
+	// Two long files without a line in common would take n+m rounds of the search.
+	n := 20000
+	a, b := make([]uint64, n), make([]uint64, n)
+	for i := range a {
+		a[i], b[i] = uint64(2*i), uint64(2*i+1)
+	}
+	added, removed, err := lineDiffCounts(context.Background(), a, b)
+	if err != nil {
+		t.Fatalf("lineDiffCounts() error = %v", err)
+	}
+	if added != n || removed != n {
+		t.Errorf("lineDiffCounts() = +%d -%d, want the whole file rewritten (+%d -%d)", added, removed, n, n)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := lineDiffCounts(ctx, []uint64{1, 2}, []uint64{3, 4}); !errors.Is(err, context.Canceled) {
+		t.Errorf("lineDiffCounts() with a cancelled context error = %v, want context.Canceled", err)
+	}
+}
+
+func TestGetCommitStatsFast_MatchesPatchTotals(t *testing.T) {
+	repoPath, cleanup := createTestRepo(t)
+	defer cleanup()
+
+	commit := func(files map[string]string, remove ...string) {
+		t.Helper()
+		for name, content := range files {
+			if err := os.WriteFile(filepath.Join(repoPath, name), []byte(content), 0600); err != nil {
+				t.Fatalf("Failed to write file: %v", err)
+			}
+		}
+		for _, name := range remove {
+			if err := os.Remove(filepath.Join(repoPath, name)); err != nil {
+				t.Fatalf("Failed to remove file: %v", err)
+			}
+		}
+		if out, err := exec.Command("git", "-C", repoPath, "add", "-A").CombinedOutput(); err != nil {
+			t.Fatalf("Failed to git add: %v: %s", err, out)
+		}
+		if out, err := exec.Command("git", "-C", repoPath, "commit", "-m", "change").CombinedOutput(); err != nil {
+			t.Fatalf("Failed to git commit: %v: %s", err, out)
+		}
+	}
+	commit(map[string]string{"a.txt": "1\n2\n3\n", "b.txt": "x\ny", "bin.dat": "\x00\x01\x02"})
+	commit(map[string]string{"a.txt": "1\nchanged\n3\n4\n", "b.txt": "x\ny\n", "c.txt": "new\n"})
+	commit(map[string]string{"bin.dat": "\x00\x03"}, "c.txt")
+
+	repo, err := OpenRepository(repoPath)
+	if err != nil {
+		t.Fatalf("OpenRepository() error = %v", err)
+	}
+	head, _ := GetHeadCommit(repo)
+	commits, err := IterateCommits(context.Background(), repo, head)
+	if err != nil {
+		t.Fatalf("IterateCommits() error = %v", err)
+	}
+
+	lineCounts := NewLineCountCache()
+	for _, c := range commits {
+		wantIns, wantDel, wantFiles, err := GetCommitStats(context.Background(), c)
+		if err != nil {
+			t.Fatalf("GetCommitStats(%s) error = %v", c.Hash, err)
+		}
+		ins, del, files, err := GetCommitStatsFast(context.Background(), c, lineCounts)
+		if err != nil {
+			t.Fatalf("GetCommitStatsFast(%s) error = %v", c.Hash, err)
+		}
+		if ins != wantIns || del != wantDel {
+			t.Errorf("Commit %s: fast stats +%d -%d, patch stats +%d -%d", c.Hash, ins, del, wantIns, wantDel)
+		}
+		if len(files) != len(wantFiles) {
+			t.Errorf("Commit %s: fast stats files %v, patch stats files %v", c.Hash, files, wantFiles)
+		}
+		for name, want := range wantFiles {
+			if files[name].Lines != want.Lines {
+				t.Errorf("Commit %s: %s lines = %d, want %d", c.Hash, name, files[name].Lines, want.Lines)
+			}
+		}
+	}
+
+	// The second commit: a.txt +2 -1 (4 lines), b.txt +1 -1 (2 lines), c.txt +1.
+	_, _, files, _ := GetCommitStatsFast(context.Background(), commits[1], lineCounts)
+	if a := files["a.txt"]; a.Insertions != 2 || a.Deletions != 1 || a.Lines != 4 {
+		t.Errorf("a.txt stats = %+v, want +2 -1 with 4 lines", a)
+	}
+	if b := files["b.txt"]; b.Insertions != 1 || b.Deletions != 1 || b.Lines != 2 {
+		t.Errorf("b.txt stats = %+v, want +1 -1 with 2 lines", b)
+	}
+}